@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/summary"
+	"github.com/spf13/cobra"
+)
+
+var clustersSort string
+
+var clustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Summarize services and task counts per cluster",
+	Long: `Clusters prints the number of services and the total running and desired
+task counts of every cluster, followed by the totals across all clusters.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !summary.ValidSortKey(clustersSort) {
+			return fmt.Errorf("unknown sort column %q: must be name, services, running, or desired", clustersSort)
+		}
+		return runClusters()
+	},
+}
+
+func init() {
+	clustersCmd.Flags().StringVar(&clustersSort, "sort", summary.SortByName, "Sort by name, services, running, or desired")
+	rootCmd.AddCommand(clustersCmd)
+}
+
+func runClusters() error {
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	services, err := fetchLiveServices(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	summaries := summary.Summarize(services)
+	summary.Sort(summaries, clustersSort)
+	return summary.WriteTable(os.Stdout, summaries)
+}
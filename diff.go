@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/snapshot"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffBaseline string
+	diffOutput   string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [snapshotA snapshotB]",
+	Short: "Compare two service snapshots, or a snapshot against the live fleet",
+	Long: `Diff reports services whose desired count, running count, or status changed
+between two snapshots written by "bw-cli export --format json". With --baseline,
+the snapshot is compared against the current state of all services instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffBaseline == "" && len(args) != 2 {
+			return errors.New("diff requires two snapshot files, or --baseline with none")
+		}
+		if diffBaseline != "" && len(args) != 0 {
+			return errors.New("--baseline compares against live services and takes no snapshot arguments")
+		}
+		return runDiff(args)
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffBaseline, "baseline", "", "Snapshot file to compare against the live services")
+	diffCmd.Flags().StringVar(&diffOutput, "output", "text", "Output format: text or json")
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(args []string) error {
+	var before, after []pkg.ServiceDetails
+	var err error
+
+	if diffBaseline != "" {
+		if before, err = snapshot.Load(diffBaseline); err != nil {
+			return err
+		}
+		ctx := context.TODO()
+		clients, err := newAWSClients(ctx)
+		if err != nil {
+			return err
+		}
+		if after, err = fetchLiveServices(ctx, clients); err != nil {
+			return err
+		}
+	} else {
+		if before, err = snapshot.Load(args[0]); err != nil {
+			return err
+		}
+		if after, err = snapshot.Load(args[1]); err != nil {
+			return err
+		}
+	}
+
+	changes := snapshot.Diff(before, after)
+	switch diffOutput {
+	case "json":
+		return snapshot.WriteJSON(os.Stdout, changes)
+	case "text":
+		return snapshot.WriteText(os.Stdout, changes)
+	default:
+		return fmt.Errorf("unsupported output format %q (expected text or json)", diffOutput)
+	}
+}
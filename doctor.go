@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that AWS credentials, region, and permissions are set up",
+	Long: `Doctor checks that credentials resolve, a region is set, and that ECS
+clusters and services can be listed and described and CloudWatch metrics
+read, printing a checklist with a hint for each failed check.
+
+It exits non-zero if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor() error {
+	ctx := context.TODO()
+
+	var results []doctor.Result
+	cfg, err := loadAWSConfig(ctx)
+	if err != nil {
+		results = []doctor.Result{doctor.ConfigLoadFailure(err)}
+	} else {
+		clients := newAWSClientsFromConfig(cfg)
+		checker := &doctor.Checker{Config: cfg, ECS: clients.ecs, CloudWatch: clients.cloudwatch}
+		results = checker.Run(ctx)
+	}
+
+	if failures := doctor.Write(os.Stdout, results); failures > 0 {
+		return fmt.Errorf("%d check(s) failed", failures)
+	}
+	return nil
+}
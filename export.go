@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/export"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportFile   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export details of all ECS services as CSV, JSON or a text table",
+	Long: `Export fetches every service across all clusters and writes its cluster,
+name, running and desired counts, status, and CPU and memory utilization to
+stdout or a file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport(exportFormat, exportFile)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", export.FormatCSV, "Output format: csv, json or table")
+	exportCmd.Flags().StringVar(&exportFile, "file", "", "Write to this file instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
+
+// exportMetricsConcurrency bounds the number of services whose metrics are
+// fetched from CloudWatch at the same time.
+const exportMetricsConcurrency = 10
+
+func runExport(format, file string) error {
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	services, err := fetchLiveServices(ctx, clients)
+	if err != nil {
+		return err
+	}
+	aws.LoadServiceMetrics(ctx, clients.cloudwatch, services, exportMetricsConcurrency)
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %v", file, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return export.Write(w, format, services)
+}
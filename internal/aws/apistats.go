@@ -0,0 +1,163 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// API Call Statistics
+// -------------------
+
+// Names under which calls are counted
+const (
+	StatsECS        = "ECS"
+	StatsCloudWatch = "CloudWatch"
+)
+
+// APICallStats is the number of calls made to one AWS service and their
+// combined latency
+type APICallStats struct {
+	Service string
+	Calls   int
+	Latency time.Duration
+}
+
+// APIStats counts the calls made through clients wrapped by CountECSCalls and
+// CountCloudWatchCalls. It is safe for concurrent use.
+type APIStats struct {
+	mu    sync.Mutex
+	stats map[string]*APICallStats
+}
+
+// NewAPIStats creates an empty set of counters
+func NewAPIStats() *APIStats {
+	return &APIStats{stats: make(map[string]*APICallStats)}
+}
+
+func (s *APIStats) record(service string, start time.Time) {
+	elapsed := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.stats[service]
+	if !ok {
+		stats = &APICallStats{Service: service}
+		s.stats[service] = stats
+	}
+	stats.Calls++
+	stats.Latency += elapsed
+}
+
+// Take returns the counters accumulated since the last call, sorted by
+// service name, and resets them
+func (s *APIStats) Take() []APICallStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	taken := make([]APICallStats, 0, len(s.stats))
+	for _, stats := range s.stats {
+		taken = append(taken, *stats)
+	}
+	s.stats = make(map[string]*APICallStats)
+	sort.Slice(taken, func(i, j int) bool { return taken[i].Service < taken[j].Service })
+	return taken
+}
+
+// FormatAPIStats summarizes counters on one line, e.g.
+// "CloudWatch: 8 calls, 1.2s | ECS: 3 calls, 420ms"
+func FormatAPIStats(stats []APICallStats) string {
+	if len(stats) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(stats))
+	for i, s := range stats {
+		noun := "calls"
+		if s.Calls == 1 {
+			noun = "call"
+		}
+		parts[i] = fmt.Sprintf("%s: %d %s, %s", s.Service, s.Calls, noun, s.Latency.Round(time.Millisecond))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// countingECSClient records every call made through an ECS client
+type countingECSClient struct {
+	client ECSClientAPI
+	stats  *APIStats
+}
+
+// CountECSCalls wraps client so every call is counted in stats
+func CountECSCalls(client ECSClientAPI, stats *APIStats) ECSClientAPI {
+	return &countingECSClient{client: client, stats: stats}
+}
+
+func (c *countingECSClient) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.ListClusters(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.DescribeClusters(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.ListServices(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.DescribeServices(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.UpdateService(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.DescribeTasks(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.ListTasks(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.ListTaskDefinitions(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.DescribeTaskDefinition(ctx, params, optFns...)
+}
+
+func (c *countingECSClient) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	defer c.stats.record(StatsECS, time.Now())
+	return c.client.DescribeContainerInstances(ctx, params, optFns...)
+}
+
+// countingCloudWatchClient records every call made through a CloudWatch client
+type countingCloudWatchClient struct {
+	client CloudWatchClientAPI
+	stats  *APIStats
+}
+
+// CountCloudWatchCalls wraps client so every call is counted in stats
+func CountCloudWatchCalls(client CloudWatchClientAPI, stats *APIStats) CloudWatchClientAPI {
+	return &countingCloudWatchClient{client: client, stats: stats}
+}
+
+func (c *countingCloudWatchClient) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	defer c.stats.record(StatsCloudWatch, time.Now())
+	return c.client.GetMetricStatistics(ctx, params, optFns...)
+}
@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCountingClients(t *testing.T) {
+	ctx := context.Background()
+	stats := NewAPIStats()
+
+	mockECS := new(MockECSClient)
+	mockECS.On("ListClusters", ctx, mock.Anything, mock.Anything).Return(&ecs.ListClustersOutput{}, nil)
+	mockCW := new(MockCloudWatchClient)
+	mockCW.On("GetMetricStatistics", ctx, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil)
+
+	ecsClient := CountECSCalls(mockECS, stats)
+	cwClient := CountCloudWatchCalls(mockCW, stats)
+
+	_, err := ListClusters(ctx, ecsClient)
+	assert.NoError(t, err)
+	_, err = cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{})
+	assert.NoError(t, err)
+	_, err = cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{})
+	assert.NoError(t, err)
+
+	taken := stats.Take()
+	assert.Len(t, taken, 2)
+	assert.Equal(t, StatsCloudWatch, taken[0].Service)
+	assert.Equal(t, 2, taken[0].Calls)
+	assert.Equal(t, StatsECS, taken[1].Service)
+	assert.Equal(t, 1, taken[1].Calls)
+
+	assert.Empty(t, stats.Take(), "Take resets the counters")
+}
+
+func TestFormatAPIStats(t *testing.T) {
+	assert.Equal(t, "none", FormatAPIStats(nil))
+	assert.Equal(t, "CloudWatch: 8 calls, 1.2s | ECS: 1 call, 420ms", FormatAPIStats([]APICallStats{
+		{Service: StatsCloudWatch, Calls: 8, Latency: 1200 * time.Millisecond},
+		{Service: StatsECS, Calls: 1, Latency: 420 * time.Millisecond},
+	}))
+}
@@ -0,0 +1,40 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	astypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+)
+
+// AutoScalingClientAPI defines the interface for Application Auto Scaling client operations
+type AutoScalingClientAPI interface {
+	DescribeScalableTargets(ctx context.Context, params *applicationautoscaling.DescribeScalableTargetsInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DescribeScalableTargetsOutput, error)
+}
+
+// GetScalingBounds returns the desired count bounds of an auto-scaled
+// service, or nil if the service isn't registered with auto scaling
+func GetScalingBounds(ctx context.Context, client AutoScalingClientAPI, cluster, serviceName string) (*pkg.ScalingBounds, error) {
+	input := &applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace:  astypes.ServiceNamespaceEcs,
+		ScalableDimension: astypes.ScalableDimensionECSServiceDesiredCount,
+		ResourceIds:       []string{fmt.Sprintf("service/%s/%s", ClusterName(cluster), serviceName)},
+	}
+
+	output, err := client.DescribeScalableTargets(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error describing auto scaling target for service %s: %v", serviceName, err)
+	}
+	if len(output.ScalableTargets) == 0 {
+		return nil, nil
+	}
+
+	target := output.ScalableTargets[0]
+	return &pkg.ScalingBounds{
+		MinCapacity: int64(aws.ToInt32(target.MinCapacity)),
+		MaxCapacity: int64(aws.ToInt32(target.MaxCapacity)),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	astypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAutoScalingClient is a mock of the Application Auto Scaling client
+type MockAutoScalingClient struct {
+	mock.Mock
+}
+
+func (m *MockAutoScalingClient) DescribeScalableTargets(ctx context.Context, params *applicationautoscaling.DescribeScalableTargetsInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DescribeScalableTargetsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*applicationautoscaling.DescribeScalableTargetsOutput), args.Error(1)
+}
+
+func TestGetScalingBounds(t *testing.T) {
+	ctx := context.Background()
+	cluster := "arn:aws:ecs:eu-west-1:123456789012:cluster/prod"
+
+	t.Run("auto-scaled service", func(t *testing.T) {
+		mockClient := new(MockAutoScalingClient)
+		mockClient.On("DescribeScalableTargets", ctx, mock.MatchedBy(func(input *applicationautoscaling.DescribeScalableTargetsInput) bool {
+			return input.ServiceNamespace == astypes.ServiceNamespaceEcs &&
+				input.ScalableDimension == astypes.ScalableDimensionECSServiceDesiredCount &&
+				len(input.ResourceIds) == 1 && input.ResourceIds[0] == "service/prod/api"
+		}), mock.Anything).Return(&applicationautoscaling.DescribeScalableTargetsOutput{
+			ScalableTargets: []astypes.ScalableTarget{{MinCapacity: aws.Int32(2), MaxCapacity: aws.Int32(10)}},
+		}, nil)
+
+		bounds, err := GetScalingBounds(ctx, mockClient, cluster, "api")
+		assert.NoError(t, err)
+		assert.Equal(t, &pkg.ScalingBounds{MinCapacity: 2, MaxCapacity: 10}, bounds)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("not auto-scaled", func(t *testing.T) {
+		mockClient := new(MockAutoScalingClient)
+		mockClient.On("DescribeScalableTargets", ctx, mock.Anything, mock.Anything).
+			Return(&applicationautoscaling.DescribeScalableTargetsOutput{}, nil)
+
+		bounds, err := GetScalingBounds(ctx, mockClient, cluster, "worker")
+		assert.NoError(t, err)
+		assert.Nil(t, bounds)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockClient := new(MockAutoScalingClient)
+		mockClient.On("DescribeScalableTargets", ctx, mock.Anything, mock.Anything).
+			Return(&applicationautoscaling.DescribeScalableTargetsOutput{}, errors.New("access denied"))
+
+		bounds, err := GetScalingBounds(ctx, mockClient, cluster, "api")
+		assert.Error(t, err)
+		assert.Nil(t, bounds)
+	})
+}
+
+func TestScalingBoundsContains(t *testing.T) {
+	bounds := pkg.ScalingBounds{MinCapacity: 2, MaxCapacity: 10}
+	assert.False(t, bounds.Contains(1))
+	assert.True(t, bounds.Contains(2))
+	assert.True(t, bounds.Contains(10))
+	assert.False(t, bounds.Contains(11))
+}
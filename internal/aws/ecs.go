@@ -2,15 +2,24 @@ package aws
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/alexalbu001/bw-cli/pkg"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
 const maxDescribeServicesBatchSize = 10
@@ -18,48 +27,110 @@ const maxDescribeServicesBatchSize = 10
 // ECSClientAPI defines the interface for ECS client operations
 type ECSClientAPI interface {
 	ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error)
+	DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error)
 	ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error)
 	DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error)
 	UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error)
 	DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error)
 	ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error)
+	ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error)
+	DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error)
+	DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error)
 }
 
 // Service Listing and Description
 // -------------------------------
 
+// ClusterErrors maps cluster ARNs to the error that prevented their services
+// from loading. It is returned alongside the services that did load, so the
+// caller can decide whether a partial view is acceptable.
+type ClusterErrors map[string]error
+
+func (e ClusterErrors) Error() string {
+	clusters := make([]string, 0, len(e))
+	for cluster := range e {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	details := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		details = append(details, fmt.Sprintf("%s: %v", ClusterName(cluster), e[cluster]))
+	}
+	return fmt.Sprintf("%d cluster(s) failed to load: %s", len(e), strings.Join(details, "; "))
+}
+
+type clusterResult struct {
+	cluster  string
+	services []pkg.ServiceDetails
+	err      error
+}
+
+// GetAllServiceDetails describes the services of every cluster concurrently.
+// If some clusters fail, the services that did load are returned together
+// with a ClusterErrors describing the failures.
 func GetAllServiceDetails(ctx context.Context, ecsClient ECSClientAPI) ([]pkg.ServiceDetails, error) {
 	clusters, err := listClusters(ctx, ecsClient)
 	if err != nil {
 		return nil, err
 	}
+	return GetServiceDetailsForClusters(ctx, ecsClient, clusters)
+}
+
+// ListClusters returns the ARNs of every cluster in the account and region
+func ListClusters(ctx context.Context, ecsClient ECSClientAPI) ([]string, error) {
+	return listClusters(ctx, ecsClient)
+}
 
+// GetServiceDetailsForClusters is like GetAllServiceDetails, but only
+// describes the services of the given clusters.
+func GetServiceDetailsForClusters(ctx context.Context, ecsClient ECSClientAPI, clusters []string) ([]pkg.ServiceDetails, error) {
 	var wg sync.WaitGroup
-	serviceCh := make(chan []pkg.ServiceDetails, len(clusters))
+	resultCh := make(chan clusterResult, len(clusters))
 
 	for _, cluster := range clusters {
 		wg.Add(1)
 		go func(cluster string) {
 			defer wg.Done()
 			services, err := describeServicesInBatches(cluster, ctx, ecsClient)
-			if err != nil {
-				return
-			}
-			serviceCh <- services
+			resultCh <- clusterResult{cluster: cluster, services: services, err: err}
 		}(cluster)
 	}
 
 	wg.Wait()
-	close(serviceCh)
+	close(resultCh)
 
 	var allServices []pkg.ServiceDetails
-	for services := range serviceCh {
-		allServices = append(allServices, services...)
+	clusterErrs := ClusterErrors{}
+	for result := range resultCh {
+		allServices = append(allServices, result.services...)
+		if result.err != nil {
+			clusterErrs[result.cluster] = result.err
+		}
 	}
 
+	if len(clusterErrs) > 0 {
+		return allServices, clusterErrs
+	}
 	return allServices, nil
 }
 
+// GetClusterServiceDetails describes every service in a single cluster
+func GetClusterServiceDetails(ctx context.Context, ecsClient ECSClientAPI, cluster string) ([]pkg.ServiceDetails, error) {
+	return describeServicesInBatches(cluster, ctx, ecsClient)
+}
+
+// SortServices orders services naturally by cluster, then by service name, so
+// listings are stable regardless of the order clusters were fetched in.
+func SortServices(services []pkg.ServiceDetails) {
+	sort.SliceStable(services, func(i, j int) bool {
+		if services[i].Cluster != services[j].Cluster {
+			return NaturalLess(services[i].Cluster, services[j].Cluster)
+		}
+		return NaturalLess(services[i].ServiceName, services[j].ServiceName)
+	})
+}
+
 func GetServiceDetails(ctx context.Context, ecsClient ECSClientAPI, serviceName, cluster string) (pkg.ServiceDetails, error) {
 	input := &ecs.DescribeServicesInput{
 		Cluster:  &cluster,
@@ -68,21 +139,73 @@ func GetServiceDetails(ctx context.Context, ecsClient ECSClientAPI, serviceName,
 
 	output, err := ecsClient.DescribeServices(ctx, input)
 	if err != nil {
-		return pkg.ServiceDetails{}, fmt.Errorf("error describing service %s: %v", serviceName, err)
+		return pkg.ServiceDetails{}, fmt.Errorf("error describing service %s: %w", serviceName, err)
 	}
 
 	if len(output.Services) == 0 {
 		return pkg.ServiceDetails{}, fmt.Errorf("no service details found for service %s", serviceName)
 	}
 
-	service := output.Services[0]
-	return pkg.ServiceDetails{
-		ServiceName:  *service.ServiceName,
-		RunningCount: int64(service.RunningCount),
-		DesiredCount: int64(service.DesiredCount),
-		Status:       *service.Status,
-		Cluster:      cluster,
-	}, nil
+	return newServiceDetails(output.Services[0], cluster), nil
+}
+
+// ClusterName returns the short cluster name from a cluster ARN. Values that
+// are not ARNs are returned unchanged.
+func ClusterName(cluster string) string {
+	return cluster[strings.LastIndex(cluster, "/")+1:]
+}
+
+// ParseARN parses an ECS resource ARN such as a cluster or service ARN,
+// requiring it to name a region and account so resources from different
+// regions and accounts can be told apart.
+func ParseARN(resourceArn string) (arn.ARN, error) {
+	parsed, err := arn.Parse(resourceArn)
+	if err != nil {
+		return arn.ARN{}, fmt.Errorf("invalid ARN %s: %v", resourceArn, err)
+	}
+	if parsed.Region == "" || parsed.AccountID == "" {
+		return arn.ARN{}, fmt.Errorf("ARN %s has no region or account", resourceArn)
+	}
+	return parsed, nil
+}
+
+// AccountIDs returns the distinct account IDs of the services' clusters, read
+// from their ARNs. Clusters known only by name are skipped.
+func AccountIDs(services []pkg.ServiceDetails) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, service := range services {
+		parsed, err := ParseARN(service.Cluster)
+		if err != nil || seen[parsed.AccountID] {
+			continue
+		}
+		seen[parsed.AccountID] = true
+		ids = append(ids, parsed.AccountID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ServiceConsoleURL returns the AWS console URL for a service. The region is
+// taken from the cluster ARN.
+func ServiceConsoleURL(cluster, serviceName string) (string, error) {
+	parsed, err := ParseARN(cluster)
+	if err != nil {
+		return "", fmt.Errorf("unable to determine region from cluster %s", cluster)
+	}
+	region := parsed.Region
+	return fmt.Sprintf("https://%s.console.aws.amazon.com/ecs/v2/clusters/%s/services/%s/health?region=%s",
+		region, url.PathEscape(ClusterName(cluster)), url.PathEscape(serviceName), region), nil
+}
+
+// ClusterGroup returns the cluster name prefix before the first "-", which
+// groups clusters named like team-env-cluster by team.
+func ClusterGroup(cluster string) string {
+	name := ClusterName(cluster)
+	if i := strings.Index(name, "-"); i > 0 {
+		return name[:i]
+	}
+	return name
 }
 
 // Helper functions for listing and describing
@@ -126,8 +249,14 @@ func describeServicesInBatches(cluster string, ctx context.Context, ecsClient EC
 	if err != nil || len(serviceArns) == 0 {
 		return nil, err
 	}
+	return describeServiceArns(ctx, ecsClient, cluster, serviceArns)
+}
 
+// describeServiceArns describes services of a cluster in batches of
+// maxDescribeServicesBatchSize
+func describeServiceArns(ctx context.Context, ecsClient ECSClientAPI, cluster string, serviceArns []string) ([]pkg.ServiceDetails, error) {
 	var services []pkg.ServiceDetails
+	var batchErr error
 	for i := 0; i < len(serviceArns); i += maxDescribeServicesBatchSize {
 		end := i + maxDescribeServicesBatchSize
 		if end > len(serviceArns) {
@@ -142,22 +271,233 @@ func describeServicesInBatches(cluster string, ctx context.Context, ecsClient EC
 
 		output, err := ecsClient.DescribeServices(ctx, input)
 		if err != nil {
-			fmt.Printf("Error describing services in cluster %s: %v\n", cluster, err)
+			// Keep describing the remaining batches and report the failure with the partial results
+			batchErr = fmt.Errorf("error describing services in cluster %s: %w", cluster, err)
+			continue
+		}
+
+		// A batch where every service failed points at the cluster rather than
+		// at services deleted mid-call, e.g. missing permissions
+		if len(output.Services) == 0 && len(output.Failures) > 0 {
+			batchErr = fmt.Errorf("error describing services in cluster %s: all %d services failed: %s", cluster, len(output.Failures), failureReasons(output.Failures))
 			continue
 		}
 
+		// Services deleted mid-call come back as failures; the rest of the batch is still usable
+		for _, failure := range output.Failures {
+			slog.Debug("service could not be described",
+				"cluster", cluster,
+				"service", aws.ToString(failure.Arn),
+				"reason", aws.ToString(failure.Reason),
+				"detail", aws.ToString(failure.Detail))
+		}
+
 		for _, service := range output.Services {
-			services = append(services, pkg.ServiceDetails{
-				ServiceName:  *service.ServiceName,
-				RunningCount: int64(service.RunningCount),
-				DesiredCount: int64(service.DesiredCount),
-				Status:       *service.Status,
-				Cluster:      cluster,
-			})
+			services = append(services, newServiceDetails(service, cluster))
+		}
+	}
+
+	return services, batchErr
+}
+
+// failureReasons summarizes the distinct reasons of failures with their
+// counts, e.g. "ACCESS_DENIED (3), MISSING"
+func failureReasons(failures []types.Failure) string {
+	counts := make(map[string]int)
+	var reasons []string
+	for _, failure := range failures {
+		reason := aws.ToString(failure.Reason)
+		if reason == "" {
+			reason = "unknown reason"
+		}
+		if counts[reason] == 0 {
+			reasons = append(reasons, reason)
+		}
+		counts[reason]++
+	}
+
+	summaries := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		if counts[reason] > 1 {
+			reason = fmt.Sprintf("%s (%d)", reason, counts[reason])
+		}
+		summaries = append(summaries, reason)
+	}
+	return strings.Join(summaries, ", ")
+}
+
+func newServiceDetails(service types.Service, cluster string) pkg.ServiceDetails {
+	details := pkg.ServiceDetails{
+		ServiceName:        *service.ServiceName,
+		RunningCount:       int64(service.RunningCount),
+		DesiredCount:       int64(service.DesiredCount),
+		Status:             *service.Status,
+		Cluster:            cluster,
+		Group:              ClusterGroup(cluster),
+		TaskDefinition:     aws.ToString(service.TaskDefinition),
+		SchedulingStrategy: string(service.SchedulingStrategy),
+		PlacementBlocked:   placementBlocked(service.Events),
+		Deploying:          isDeploying(service.Deployments),
+		CreatedAt:          service.CreatedAt,
+
+		ExecuteCommandEnabled: service.EnableExecuteCommand,
+		PropagateTags:         string(service.PropagateTags),
+		ManagedTags:           service.EnableECSManagedTags,
+	}
+
+	if primary := primaryDeployment(service.Deployments); primary != nil {
+		details.DeploymentID = aws.ToString(primary.Id)
+		details.DeploymentRunningCount = int64(primary.RunningCount)
+		details.LastDeployedAt = primary.CreatedAt
+	}
+
+	details.FailedDeployment, details.Rollback = rolloutFailure(service.Deployments)
+	details.Deployments = serviceDeployments(service)
+
+	details.LaunchType = string(service.LaunchType)
+	for _, item := range service.CapacityProviderStrategy {
+		details.CapacityProviders = append(details.CapacityProviders, aws.ToString(item.CapacityProvider))
+	}
+	if details.IsFargate() {
+		details.PlatformVersion = aws.ToString(service.PlatformVersion)
+	}
+
+	for _, lb := range service.LoadBalancers {
+		if lb.TargetGroupArn != nil {
+			details.TargetGroupArns = append(details.TargetGroupArns, *lb.TargetGroupArn)
+		}
+	}
+
+	if network := service.NetworkConfiguration; network != nil && network.AwsvpcConfiguration != nil {
+		details.Network = &pkg.NetworkConfiguration{
+			Subnets:        network.AwsvpcConfiguration.Subnets,
+			SecurityGroups: network.AwsvpcConfiguration.SecurityGroups,
+			AssignPublicIP: string(network.AwsvpcConfiguration.AssignPublicIp),
+		}
+	}
+
+	if config := service.DeploymentConfiguration; config != nil {
+		details.MinimumHealthyPercent = int64Ptr(config.MinimumHealthyPercent)
+		details.MaximumPercent = int64Ptr(config.MaximumPercent)
+		if breaker := config.DeploymentCircuitBreaker; breaker != nil {
+			details.CircuitBreaker = &pkg.CircuitBreaker{Enabled: breaker.Enable, Rollback: breaker.Rollback}
+		}
+	}
+	return details
+}
+
+// isDeploying reports whether a rollout is in progress: either a deployment
+// is still rolling out, or an old deployment hasn't been drained yet
+func isDeploying(deployments []types.Deployment) bool {
+	if len(deployments) > 1 {
+		return true
+	}
+	for _, deployment := range deployments {
+		if deployment.RolloutState == types.DeploymentRolloutStateInProgress {
+			return true
+		}
+	}
+	return false
+}
+
+// primaryDeployment returns the deployment ECS is rolling out, or nil if the
+// service reports no deployments
+func primaryDeployment(deployments []types.Deployment) *types.Deployment {
+	for i := range deployments {
+		if aws.ToString(deployments[i].Status) == "PRIMARY" {
+			return &deployments[i]
+		}
+	}
+	if len(deployments) > 0 {
+		return &deployments[0]
+	}
+	return nil
+}
+
+// serviceDeployments lists the deployments, or the task sets of services
+// deployed by CodeDeploy or an external controller, a service's tasks are
+// split between
+func serviceDeployments(service types.Service) []pkg.Deployment {
+	var deployments []pkg.Deployment
+	if len(service.TaskSets) > 0 {
+		for _, taskSet := range service.TaskSets {
+			deployment := pkg.Deployment{
+				ID:             aws.ToString(taskSet.Id),
+				Status:         aws.ToString(taskSet.Status),
+				TaskDefinition: aws.ToString(taskSet.TaskDefinition),
+				DesiredCount:   int64(taskSet.ComputedDesiredCount),
+				RunningCount:   int64(taskSet.RunningCount),
+				PendingCount:   int64(taskSet.PendingCount),
+			}
+			if taskSet.Scale != nil && taskSet.Scale.Unit == types.ScaleUnitPercent {
+				deployment.ScalePercent = aws.Float64(taskSet.Scale.Value)
+			}
+			deployments = append(deployments, deployment)
+		}
+		return deployments
+	}
+	for _, d := range service.Deployments {
+		deployments = append(deployments, pkg.Deployment{
+			ID:             aws.ToString(d.Id),
+			Status:         aws.ToString(d.Status),
+			TaskDefinition: aws.ToString(d.TaskDefinition),
+			DesiredCount:   int64(d.DesiredCount),
+			RunningCount:   int64(d.RunningCount),
+			PendingCount:   int64(d.PendingCount),
+			RolloutState:   string(d.RolloutState),
+		})
+	}
+	return deployments
+}
+
+// rolloutFailure returns the reasons ECS gives for a failed deployment and for
+// rolling back from it. The circuit breaker marks the failed deployment FAILED
+// and, with rollback enabled, starts a deployment whose reason says which
+// deployment it is rolling back to.
+func rolloutFailure(deployments []types.Deployment) (failed, rollback string) {
+	for _, deployment := range deployments {
+		reason := aws.ToString(deployment.RolloutStateReason)
+		switch {
+		case deployment.RolloutState == types.DeploymentRolloutStateFailed && failed == "":
+			failed = reason
+			if failed == "" {
+				failed = "deployment " + aws.ToString(deployment.Id) + " failed"
+			}
+		case strings.Contains(reason, "rolling back") && rollback == "":
+			rollback = reason
+		}
+	}
+	return failed, rollback
+}
+
+// placementEventsScanned limits how far back placementBlocked looks
+const placementEventsScanned = 10
+
+// placementBlocked reports whether the most recent service events show ECS
+// failing to place tasks. Events are ordered newest first, so a steady state
+// event ends the scan: any placement failure before it has been resolved.
+func placementBlocked(events []types.ServiceEvent) bool {
+	for i, event := range events {
+		if i == placementEventsScanned {
+			break
+		}
+		message := aws.ToString(event.Message)
+		if strings.Contains(message, "has reached a steady state") {
+			return false
+		}
+		if strings.Contains(message, "was unable to place a task") {
+			return true
 		}
 	}
+	return false
+}
 
-	return services, nil
+func int64Ptr(value *int32) *int64 {
+	if value == nil {
+		return nil
+	}
+	v := int64(*value)
+	return &v
 }
 
 // Service Management Operations
@@ -177,6 +517,67 @@ func UpdateServiceDesiredCount(ctx context.Context, ecsClient ECSClientAPI, serv
 	return nil
 }
 
+// ServiceResult pairs a service with the outcome of an operation applied to it
+type ServiceResult struct {
+	Service pkg.ServiceDetails
+	Err     error
+}
+
+// DesiredCountUpdate is a desired count to apply to a service
+type DesiredCountUpdate struct {
+	Service      pkg.ServiceDetails
+	DesiredCount int64
+}
+
+// updateDesiredCountsConcurrency bounds the number of services updated at the
+// same time, to stay clear of UpdateService throttling on large clusters
+const updateDesiredCountsConcurrency = 10
+
+// UpdateDesiredCounts applies every update concurrently. The results are
+// returned in the same order as updates.
+func UpdateDesiredCounts(ctx context.Context, ecsClient ECSClientAPI, updates []DesiredCountUpdate) []ServiceResult {
+	results := make([]ServiceResult, len(updates))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, updateDesiredCountsConcurrency)
+
+	for i, update := range updates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, update DesiredCountUpdate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := UpdateServiceDesiredCount(ctx, ecsClient, update.Service.ServiceName, update.Service.Cluster, update.DesiredCount)
+			results[i] = ServiceResult{Service: update.Service, Err: err}
+		}(i, update)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// ScalableServices splits services into those whose desired count can be set
+// and DAEMON services, which ECS refuses a desired count for
+func ScalableServices(services []pkg.ServiceDetails) (scalable, daemons []pkg.ServiceDetails) {
+	for _, service := range services {
+		if service.IsDaemon() {
+			daemons = append(daemons, service)
+		} else {
+			scalable = append(scalable, service)
+		}
+	}
+	return scalable, daemons
+}
+
+// ScaleServices sets the same desired count on every service concurrently.
+// The results are returned in the same order as services.
+func ScaleServices(ctx context.Context, ecsClient ECSClientAPI, services []pkg.ServiceDetails, desiredCount int64) []ServiceResult {
+	updates := make([]DesiredCountUpdate, len(services))
+	for i, service := range services {
+		updates[i] = DesiredCountUpdate{Service: service, DesiredCount: desiredCount}
+	}
+	return UpdateDesiredCounts(ctx, ecsClient, updates)
+}
+
 func RestartService(ctx context.Context, ecsClient ECSClientAPI, serviceName, cluster string) error {
 	input := &ecs.UpdateServiceInput{
 		Cluster:            &cluster,
@@ -192,6 +593,173 @@ func RestartService(ctx context.Context, ecsClient ECSClientAPI, serviceName, cl
 	return nil
 }
 
+func UpdateServiceTaskDefinition(ctx context.Context, ecsClient ECSClientAPI, serviceName, cluster, taskDefinitionArn string) error {
+	input := &ecs.UpdateServiceInput{
+		Cluster:        &cluster,
+		Service:        &serviceName,
+		TaskDefinition: &taskDefinitionArn,
+	}
+
+	_, err := ecsClient.UpdateService(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to update task definition for service %s: %v", serviceName, err)
+	}
+	return nil
+}
+
+// Task Definitions
+// ----------------
+
+// TaskDefinitionName returns the family:revision part of a task definition
+// ARN, e.g. "web:42" for ".../task-definition/web:42".
+func TaskDefinitionName(taskDefinition string) string {
+	return taskDefinition[strings.LastIndex(taskDefinition, "/")+1:]
+}
+
+// TaskDefinitionFamily returns the family name from a task definition ARN or
+// family:revision string, e.g. "web" for ".../task-definition/web:42".
+func TaskDefinitionFamily(taskDefinition string) string {
+	family := TaskDefinitionName(taskDefinition)
+	if i := strings.LastIndex(family, ":"); i >= 0 {
+		family = family[:i]
+	}
+	return family
+}
+
+// TaskDefinitionRevision returns the revision number from a task definition
+// ARN or family:revision string, or 0 if it has none.
+func TaskDefinitionRevision(taskDefinition string) int {
+	name := TaskDefinitionName(taskDefinition)
+	revision, err := strconv.Atoi(name[strings.LastIndex(name, ":")+1:])
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// GetTaskReservation returns the CPU and memory reserved by each task of a
+// task definition. Task-level sizes are used when set, as on Fargate;
+// otherwise the container reservations are summed.
+func GetTaskReservation(ctx context.Context, ecsClient ECSClientAPI, taskDefinition string) (pkg.TaskReservation, error) {
+	output, err := ecsClient.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{TaskDefinition: &taskDefinition})
+	if err != nil {
+		return pkg.TaskReservation{}, fmt.Errorf("error describing task definition %s: %v", TaskDefinitionName(taskDefinition), err)
+	}
+	return taskReservation(output.TaskDefinition), nil
+}
+
+func taskReservation(taskDefinition *types.TaskDefinition) pkg.TaskReservation {
+	var reservation pkg.TaskReservation
+	if taskDefinition == nil {
+		return reservation
+	}
+
+	var containerCPU, containerMemory int64
+	for _, container := range taskDefinition.ContainerDefinitions {
+		containerCPU += int64(container.Cpu)
+		if container.Memory != nil {
+			containerMemory += int64(*container.Memory)
+		} else if container.MemoryReservation != nil {
+			containerMemory += int64(*container.MemoryReservation)
+		}
+	}
+
+	reservation.CPU = containerCPU
+	if cpu, err := strconv.ParseInt(aws.ToString(taskDefinition.Cpu), 10, 64); err == nil {
+		reservation.CPU = cpu
+	}
+	reservation.MemoryMiB = containerMemory
+	if memory, err := strconv.ParseInt(aws.ToString(taskDefinition.Memory), 10, 64); err == nil {
+		reservation.MemoryMiB = memory
+	}
+	return reservation
+}
+
+// GetTaskDefinitionSummary describes a task definition revision for comparison
+// with other revisions of its family.
+func GetTaskDefinitionSummary(ctx context.Context, ecsClient ECSClientAPI, taskDefinition string) (pkg.TaskDefinitionSummary, error) {
+	output, err := ecsClient.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{TaskDefinition: &taskDefinition})
+	if err != nil {
+		return pkg.TaskDefinitionSummary{}, fmt.Errorf("error describing task definition %s: %v", TaskDefinitionName(taskDefinition), err)
+	}
+	summary := pkg.TaskDefinitionSummary{TaskDefinition: taskDefinition}
+	if output.TaskDefinition == nil {
+		return summary, nil
+	}
+
+	summary.CPU = aws.ToString(output.TaskDefinition.Cpu)
+	summary.Memory = aws.ToString(output.TaskDefinition.Memory)
+	for _, container := range output.TaskDefinition.ContainerDefinitions {
+		details := pkg.ContainerDefinitionSummary{
+			Name:              aws.ToString(container.Name),
+			Image:             aws.ToString(container.Image),
+			CPU:               container.Cpu,
+			Memory:            container.Memory,
+			MemoryReservation: container.MemoryReservation,
+		}
+		for _, variable := range container.Environment {
+			if details.Environment == nil {
+				details.Environment = make(map[string]string)
+			}
+			details.Environment[aws.ToString(variable.Name)] = aws.ToString(variable.Value)
+		}
+		summary.Containers = append(summary.Containers, details)
+	}
+	return summary, nil
+}
+
+// GetPreviousTaskDefinition returns the newest ACTIVE revision in the task
+// definition's family that is older than the given one.
+func GetPreviousTaskDefinition(ctx context.Context, ecsClient ECSClientAPI, taskDefinition string) (string, error) {
+	family := TaskDefinitionFamily(taskDefinition)
+	current := TaskDefinitionRevision(taskDefinition)
+	input := &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: &family,
+		Status:       types.TaskDefinitionStatusActive,
+		Sort:         types.SortOrderDesc,
+	}
+
+	paginator := ecs.NewListTaskDefinitionsPaginator(ecsClient, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("error listing task definitions for family %s: %v", family, err)
+		}
+		for _, arn := range output.TaskDefinitionArns {
+			if TaskDefinitionFamily(arn) == family && TaskDefinitionRevision(arn) < current {
+				return arn, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no previous active revision found for %s", TaskDefinitionName(taskDefinition))
+}
+
+// ListTaskDefinitionRevisions returns up to limit ACTIVE task definition ARNs
+// in the given family, newest revision first.
+func ListTaskDefinitionRevisions(ctx context.Context, ecsClient ECSClientAPI, family string, limit int) ([]string, error) {
+	input := &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: &family,
+		Status:       types.TaskDefinitionStatusActive,
+		Sort:         types.SortOrderDesc,
+	}
+	var revisions []string
+
+	paginator := ecs.NewListTaskDefinitionsPaginator(ecsClient, input)
+	for paginator.HasMorePages() && len(revisions) < limit {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing task definitions for family %s: %v", family, err)
+		}
+		for _, arn := range output.TaskDefinitionArns {
+			// FamilyPrefix also matches longer family names, so keep exact matches only
+			if TaskDefinitionFamily(arn) == family && len(revisions) < limit {
+				revisions = append(revisions, arn)
+			}
+		}
+	}
+	return revisions, nil
+}
+
 // Deployment Status
 // -----------------
 
@@ -206,9 +774,12 @@ func GetServiceDeploymentStatus(ctx context.Context, ecsClient ECSClientAPI, ser
 		return "", fmt.Errorf("error describing service %s: %v", serviceName, err)
 	}
 
-	if len(output.Services) == 0 || len(output.Services[0].Deployments) == 0 {
+	if len(output.Services) == 0 {
 		return "Unknown", nil
 	}
+	if len(output.Services[0].Deployments) == 0 {
+		return taskSetStatus(output.Services[0].TaskSets), nil
+	}
 
 	deployment := output.Services[0].Deployments[0]
 	switch deployment.RolloutState {
@@ -224,6 +795,78 @@ func GetServiceDeploymentStatus(ctx context.Context, ecsClient ECSClientAPI, ser
 	return *deployment.Status, nil
 }
 
+// taskSetStatus summarizes the task sets of a service using the CODE_DEPLOY
+// or EXTERNAL deployment controller, which has task sets instead of
+// deployments. A rollout is in progress while any task set other than the
+// primary one remains, or the primary one hasn't reached a steady state.
+func taskSetStatus(taskSets []types.TaskSet) string {
+	var primary *types.TaskSet
+	for i := range taskSets {
+		if aws.ToString(taskSets[i].Status) == "PRIMARY" {
+			primary = &taskSets[i]
+		}
+	}
+	if primary == nil {
+		return "Unknown"
+	}
+	if len(taskSets) == 1 && primary.StabilityStatus == types.StabilityStatusSteadyState {
+		return "Stable"
+	}
+	return fmt.Sprintf("Deploying (%d/%d)", primary.RunningCount, primary.ComputedDesiredCount)
+}
+
+// Deployment states that GetServiceDeploymentStatus results are grouped into
+const (
+	DeploymentStateDeploying = "deploying"
+	DeploymentStateStable    = "stable"
+	DeploymentStateFailed    = "failed"
+	DeploymentStateOther     = "other"
+)
+
+// DeploymentState groups a status returned by GetServiceDeploymentStatus
+// into one of the DeploymentState constants
+func DeploymentState(status string) string {
+	switch {
+	case strings.HasPrefix(status, "Deploying"):
+		return DeploymentStateDeploying
+	case status == "Stable":
+		return DeploymentStateStable
+	case status == "Deployment Failed":
+		return DeploymentStateFailed
+	}
+	return DeploymentStateOther
+}
+
+// DeploymentStatusResult is the deployment status of a service, or the error
+// that prevented it from being fetched
+type DeploymentStatusResult struct {
+	Service pkg.ServiceDetails
+	Status  string
+	Err     error
+}
+
+// GetDeploymentStatuses fetches the deployment status of every service, at
+// most concurrency at a time. Results are in the same order as services.
+func GetDeploymentStatuses(ctx context.Context, ecsClient ECSClientAPI, services []pkg.ServiceDetails, concurrency int) []DeploymentStatusResult {
+	results := make([]DeploymentStatusResult, len(services))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, service := range services {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, service pkg.ServiceDetails) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			status, err := GetServiceDeploymentStatus(ctx, ecsClient, service.ServiceName, service.Cluster)
+			results[i] = DeploymentStatusResult{Service: service, Status: status, Err: err}
+		}(i, service)
+	}
+
+	wg.Wait()
+	return results
+}
+
 // Container Operations
 // --------------------
 
@@ -290,35 +933,391 @@ func GetTaskArnForService(ctx context.Context, ecsClient ECSClientAPI, cluster,
 	return output.TaskArns[0], nil
 }
 
+// GetServiceJSON returns a service as DescribeServices reports it, tags
+// included, as indented JSON. Fields keep the SDK's names, e.g. ServiceName.
+func GetServiceJSON(ctx context.Context, ecsClient ECSClientAPI, cluster, serviceName string) (string, error) {
+	input := &ecs.DescribeServicesInput{
+		Cluster:  &cluster,
+		Services: []string{serviceName},
+		Include:  []types.ServiceField{types.ServiceFieldTags},
+	}
+
+	output, err := ecsClient.DescribeServices(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("error describing service %s: %v", serviceName, err)
+	}
+	if len(output.Services) == 0 {
+		return "", fmt.Errorf("no service details found for service %s", serviceName)
+	}
+
+	data, err := json.MarshalIndent(output.Services[0], "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding service %s: %v", serviceName, err)
+	}
+	return string(data), nil
+}
+
+// GetServiceEvents returns the recent events of a service, oldest first
+func GetServiceEvents(ctx context.Context, ecsClient ECSClientAPI, cluster, serviceName string) ([]pkg.ServiceEvent, error) {
+	input := &ecs.DescribeServicesInput{
+		Cluster:  &cluster,
+		Services: []string{serviceName},
+	}
+
+	output, err := ecsClient.DescribeServices(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error describing service %s: %v", serviceName, err)
+	}
+	if len(output.Services) == 0 {
+		return nil, fmt.Errorf("no service details found for service %s", serviceName)
+	}
+
+	// ECS lists events newest first
+	serviceEvents := output.Services[0].Events
+	events := make([]pkg.ServiceEvent, 0, len(serviceEvents))
+	for i := len(serviceEvents) - 1; i >= 0; i-- {
+		events = append(events, pkg.ServiceEvent{
+			ID:        aws.ToString(serviceEvents[i].Id),
+			CreatedAt: serviceEvents[i].CreatedAt,
+			Message:   aws.ToString(serviceEvents[i].Message),
+		})
+	}
+	return events, nil
+}
+
+// maxDescribeTasksBatchSize is the most tasks DescribeTasks accepts at once
+const maxDescribeTasksBatchSize = 100
+
+// ListServiceTasks returns every task of a service that ECS still knows
+// about, in the order ListTasks returns them
+func ListServiceTasks(ctx context.Context, ecsClient ECSClientAPI, cluster, serviceName string) ([]pkg.TaskDetails, error) {
+	input := &ecs.ListTasksInput{
+		Cluster:     &cluster,
+		ServiceName: &serviceName,
+	}
+	var taskArns []string
+
+	paginator := ecs.NewListTasksPaginator(ecsClient, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing tasks for service %s: %v", serviceName, err)
+		}
+		taskArns = append(taskArns, output.TaskArns...)
+	}
+
+	tasks, err := describeTasksInBatches(ctx, ecsClient, cluster, taskArns)
+	if err != nil {
+		return nil, fmt.Errorf("error describing tasks for service %s: %v", serviceName, err)
+	}
+	result := make([]pkg.TaskDetails, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, newTaskDetails(task))
+	}
+	return result, nil
+}
+
+// ListStandaloneTasks returns the running and recently stopped tasks of a
+// cluster that don't belong to a service, such as scheduled tasks started by
+// EventBridge, most recently started first. When startedBy is set, only tasks
+// started by it are returned.
+func ListStandaloneTasks(ctx context.Context, ecsClient ECSClientAPI, cluster, startedBy string) ([]pkg.TaskDetails, error) {
+	var taskArns []string
+	for _, status := range []types.DesiredStatus{types.DesiredStatusRunning, types.DesiredStatusStopped} {
+		input := &ecs.ListTasksInput{
+			Cluster:       &cluster,
+			DesiredStatus: status,
+		}
+		if startedBy != "" {
+			input.StartedBy = &startedBy
+		}
+
+		paginator := ecs.NewListTasksPaginator(ecsClient, input)
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error listing tasks for cluster %s: %v", ClusterName(cluster), err)
+			}
+			taskArns = append(taskArns, output.TaskArns...)
+		}
+	}
+
+	tasks, err := describeTasksInBatches(ctx, ecsClient, cluster, taskArns)
+	if err != nil {
+		return nil, fmt.Errorf("error describing tasks for cluster %s: %v", ClusterName(cluster), err)
+	}
+	var standalone []pkg.TaskDetails
+	for _, task := range tasks {
+		// Service tasks are grouped as "service:<name>"
+		if strings.HasPrefix(aws.ToString(task.Group), "service:") {
+			continue
+		}
+		standalone = append(standalone, newTaskDetails(task))
+	}
+	sort.SliceStable(standalone, func(i, j int) bool {
+		return aws.ToTime(standalone[i].StartedAt).After(aws.ToTime(standalone[j].StartedAt))
+	})
+	return standalone, nil
+}
+
+// describeTasksInBatches describes tasks in batches of up to
+// maxDescribeTasksBatchSize, the most DescribeTasks accepts
+func describeTasksInBatches(ctx context.Context, ecsClient ECSClientAPI, cluster string, taskArns []string) ([]types.Task, error) {
+	var tasks []types.Task
+	for i := 0; i < len(taskArns); i += maxDescribeTasksBatchSize {
+		end := i + maxDescribeTasksBatchSize
+		if end > len(taskArns) {
+			end = len(taskArns)
+		}
+
+		output, err := ecsClient.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: &cluster,
+			Tasks:   taskArns[i:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, output.Tasks...)
+	}
+	return tasks, nil
+}
+
+// GetStoppedTasks returns the service's recently stopped tasks, most recently
+// stopped first. ECS only retains stopped tasks for a short while.
+func GetStoppedTasks(ctx context.Context, ecsClient ECSClientAPI, cluster, serviceName string) ([]pkg.TaskDetails, error) {
+	listInput := &ecs.ListTasksInput{
+		Cluster:       &cluster,
+		ServiceName:   &serviceName,
+		DesiredStatus: types.DesiredStatusStopped,
+	}
+
+	listOutput, err := ecsClient.ListTasks(ctx, listInput)
+	if err != nil {
+		return nil, fmt.Errorf("error listing stopped tasks for service %s: %v", serviceName, err)
+	}
+	if len(listOutput.TaskArns) == 0 {
+		return nil, nil
+	}
+
+	stopped, err := describeTasksInBatches(ctx, ecsClient, cluster, listOutput.TaskArns)
+	if err != nil {
+		return nil, fmt.Errorf("error describing stopped tasks for service %s: %v", serviceName, err)
+	}
+
+	tasks := make([]pkg.TaskDetails, 0, len(stopped))
+	for _, task := range stopped {
+		tasks = append(tasks, newTaskDetails(task))
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return aws.ToTime(tasks[i].StoppedAt).After(aws.ToTime(tasks[j].StoppedAt))
+	})
+	return tasks, nil
+}
+
+func newTaskDetails(task types.Task) pkg.TaskDetails {
+	details := pkg.TaskDetails{
+		TaskArn:          aws.ToString(task.TaskArn),
+		TaskDefinition:   aws.ToString(task.TaskDefinitionArn),
+		StartedBy:        aws.ToString(task.StartedBy),
+		AvailabilityZone: aws.ToString(task.AvailabilityZone),
+		LastStatus:       aws.ToString(task.LastStatus),
+		DesiredStatus:    aws.ToString(task.DesiredStatus),
+		HealthStatus:     string(task.HealthStatus),
+		StartedAt:        task.StartedAt,
+		StoppedAt:        task.StoppedAt,
+		StoppedReason:    aws.ToString(task.StoppedReason),
+		PrivateIP:        taskPrivateIP(task),
+
+		ContainerInstanceArn: aws.ToString(task.ContainerInstanceArn),
+	}
+	for _, container := range task.Containers {
+		details.Containers = append(details.Containers, pkg.ContainerDetails{
+			Name:       aws.ToString(container.Name),
+			LastStatus: aws.ToString(container.LastStatus),
+			Reason:     aws.ToString(container.Reason),
+			ExitCode:   container.ExitCode,
+		})
+	}
+	return details
+}
+
+// taskPrivateIP returns the private IP of an awsvpc task's network interface
+func taskPrivateIP(task types.Task) string {
+	for _, attachment := range task.Attachments {
+		if aws.ToString(attachment.Type) != "ElasticNetworkInterface" {
+			continue
+		}
+		for _, detail := range attachment.Details {
+			if aws.ToString(detail.Name) == "privateIPv4Address" {
+				return aws.ToString(detail.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// Container Instances
+// -------------------
+
+// maxDescribeContainerInstancesBatchSize is the most container instances
+// DescribeContainerInstances accepts at once
+const maxDescribeContainerInstancesBatchSize = 100
+
+// GetContainerInstances describes the EC2 container instances of a cluster
+// that tasks run on, keyed by container instance ARN. Duplicate and empty
+// ARNs are ignored, so the ContainerInstanceArn of every task can be passed.
+func GetContainerInstances(ctx context.Context, ecsClient ECSClientAPI, cluster string, containerInstanceArns []string) (map[string]pkg.ContainerInstance, error) {
+	seen := make(map[string]bool)
+	var arns []string
+	for _, arn := range containerInstanceArns {
+		if arn != "" && !seen[arn] {
+			seen[arn] = true
+			arns = append(arns, arn)
+		}
+	}
+
+	instances := make(map[string]pkg.ContainerInstance, len(arns))
+	for i := 0; i < len(arns); i += maxDescribeContainerInstancesBatchSize {
+		end := i + maxDescribeContainerInstancesBatchSize
+		if end > len(arns) {
+			end = len(arns)
+		}
+
+		output, err := ecsClient.DescribeContainerInstances(ctx, &ecs.DescribeContainerInstancesInput{
+			Cluster:            &cluster,
+			ContainerInstances: arns[i:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing container instances in cluster %s: %v", ClusterName(cluster), err)
+		}
+		for _, instance := range output.ContainerInstances {
+			details := newContainerInstance(instance)
+			instances[details.ContainerInstanceArn] = details
+		}
+	}
+	return instances, nil
+}
+
+func newContainerInstance(instance types.ContainerInstance) pkg.ContainerInstance {
+	details := pkg.ContainerInstance{
+		ContainerInstanceArn: aws.ToString(instance.ContainerInstanceArn),
+		EC2InstanceID:        aws.ToString(instance.Ec2InstanceId),
+		Status:               aws.ToString(instance.Status),
+		AgentConnected:       instance.AgentConnected,
+		RunningTasksCount:    int64(instance.RunningTasksCount),
+	}
+	for _, attribute := range instance.Attributes {
+		if aws.ToString(attribute.Name) == "ecs.availability-zone" {
+			details.AvailabilityZone = aws.ToString(attribute.Value)
+		}
+	}
+	details.RegisteredCPU, details.RegisteredMemoryMiB = resourceAmounts(instance.RegisteredResources)
+	details.RemainingCPU, details.RemainingMemoryMiB = resourceAmounts(instance.RemainingResources)
+	return details
+}
+
+// resourceAmounts returns the CPU units and memory in MiB of a container
+// instance's registered or remaining resources
+func resourceAmounts(resources []types.Resource) (cpu, memoryMiB int64) {
+	for _, resource := range resources {
+		switch aws.ToString(resource.Name) {
+		case "CPU":
+			cpu = int64(resource.IntegerValue)
+		case "MEMORY":
+			memoryMiB = int64(resource.IntegerValue)
+		}
+	}
+	return cpu, memoryMiB
+}
+
 // Service Updates Polling
 // -----------------------
 
-func PollServiceUpdates(ctx context.Context, ecsClient ECSClientAPI, services []pkg.ServiceDetails, updateInterval time.Duration) chan []pkg.ServiceDetails {
-	updates := make(chan []pkg.ServiceDetails)
+// PollUpdate is sent by PollServiceUpdates on each tick: either freshly
+// fetched services, or a signal that metrics are due to be refreshed
+type PollUpdate struct {
+	Services        []pkg.ServiceDetails // Nil on metrics ticks
+	DeletedClusters []string             // Clusters found deleted, whose services were left out
+	MetricsDue      bool
+}
+
+// PollServiceUpdates fetches the services every serviceInterval and signals
+// every metricsInterval that metrics should be refreshed, so fast-changing
+// ECS state can be polled often without calling CloudWatch as often. A zero
+// metricsInterval disables metrics ticks. Polling stops when ctx is done,
+// and the channel is closed by the polling goroutine itself, so no send can
+// race with the close. Updates fetched after ctx is done are dropped.
+func PollServiceUpdates(ctx context.Context, ecsClient ECSClientAPI, services []pkg.ServiceDetails, serviceInterval, metricsInterval time.Duration) <-chan PollUpdate {
+	updates := make(chan PollUpdate)
 
 	go func() {
-		ticker := time.NewTicker(updateInterval)
-		defer ticker.Stop()
+		serviceTicker := time.NewTicker(serviceInterval)
+		defer serviceTicker.Stop()
+		var metricsTicks <-chan time.Time
+		if metricsInterval > 0 {
+			metricsTicker := time.NewTicker(metricsInterval)
+			defer metricsTicker.Stop()
+			metricsTicks = metricsTicker.C
+		}
 		defer close(updates)
 
 		for {
+			var update PollUpdate
+			select {
+			case <-ctx.Done():
+				return
+			case <-serviceTicker.C:
+				update.Services, update.DeletedClusters = FetchServiceUpdates(ctx, ecsClient, services)
+			case <-metricsTicks:
+				update.MetricsDue = true
+			}
+
+			// A fetch interrupted by cancellation yields incomplete services,
+			// and select picks randomly when both cases below are ready
+			if ctx.Err() != nil {
+				return
+			}
 			select {
 			case <-ctx.Done():
 				return
-			case <-ticker.C:
-				updatedServices := make([]pkg.ServiceDetails, len(services))
-				for i, service := range services {
-					details, err := GetServiceDetails(ctx, ecsClient, service.ServiceName, service.Cluster)
-					if err != nil {
-						// Log the error, but continue with other services
-						continue
-					}
-					updatedServices[i] = details
-				}
-				updates <- updatedServices
+			case updates <- update:
 			}
 		}
 	}()
 
 	return updates
 }
+
+// FetchServiceUpdates fetches the current details of each service once.
+// Services whose cluster no longer exists are left out, and their clusters
+// returned, so a cluster deleted mid-session drops out of the list. Services
+// that fail to update for other reasons keep their previous details.
+func FetchServiceUpdates(ctx context.Context, ecsClient ECSClientAPI, services []pkg.ServiceDetails) ([]pkg.ServiceDetails, []string) {
+	updatedServices := make([]pkg.ServiceDetails, 0, len(services))
+	var deletedClusters []string
+	deleted := make(map[string]bool)
+	for _, service := range services {
+		if deleted[service.Cluster] {
+			continue
+		}
+		details, err := GetServiceDetails(ctx, ecsClient, service.ServiceName, service.Cluster)
+		if IsClusterNotFound(err) {
+			deleted[service.Cluster] = true
+			deletedClusters = append(deletedClusters, service.Cluster)
+			continue
+		}
+		if err != nil {
+			// Keep the last known details, and continue with other services
+			details = service
+		}
+		updatedServices = append(updatedServices, details)
+	}
+	return updatedServices, deletedClusters
+}
+
+// IsClusterNotFound reports whether err means the cluster doesn't exist,
+// e.g. because it was deleted
+func IsClusterNotFound(err error) bool {
+	var notFound *types.ClusterNotFoundException
+	return errors.As(err, &notFound)
+}
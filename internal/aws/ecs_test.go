@@ -2,7 +2,11 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/alexalbu001/bw-cli/pkg"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -22,6 +26,11 @@ func (m *MockECSClient) ListClusters(ctx context.Context, params *ecs.ListCluste
 	return args.Get(0).(*ecs.ListClustersOutput), args.Error(1)
 }
 
+func (m *MockECSClient) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ecs.DescribeClustersOutput), args.Error(1)
+}
+
 func (m *MockECSClient) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
 	args := m.Called(ctx, params, optFns)
 	return args.Get(0).(*ecs.ListServicesOutput), args.Error(1)
@@ -47,6 +56,21 @@ func (m *MockECSClient) ListTasks(ctx context.Context, params *ecs.ListTasksInpu
 	return args.Get(0).(*ecs.ListTasksOutput), args.Error(1)
 }
 
+func (m *MockECSClient) ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ecs.ListTaskDefinitionsOutput), args.Error(1)
+}
+
+func (m *MockECSClient) DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ecs.DescribeTaskDefinitionOutput), args.Error(1)
+}
+
+func (m *MockECSClient) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*ecs.DescribeContainerInstancesOutput), args.Error(1)
+}
+
 func TestGetAllServiceDetails(t *testing.T) {
 	mockClient := new(MockECSClient)
 	ctx := context.Background()
@@ -112,16 +136,143 @@ func TestGetAllServiceDetails(t *testing.T) {
 	assert.Len(t, services, 4) // 2 clusters * 2 services each
 
 	expectedServices := []pkg.ServiceDetails{
-		{ServiceName: "service1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE", Cluster: "cluster1"},
-		{ServiceName: "service2", RunningCount: 1, DesiredCount: 3, Status: "DRAINING", Cluster: "cluster1"},
-		{ServiceName: "service3", RunningCount: 3, DesiredCount: 3, Status: "ACTIVE", Cluster: "cluster2"},
-		{ServiceName: "service4", RunningCount: 0, DesiredCount: 2, Status: "INACTIVE", Cluster: "cluster2"},
+		{ServiceName: "service1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE", Cluster: "cluster1", Group: "cluster1"},
+		{ServiceName: "service2", RunningCount: 1, DesiredCount: 3, Status: "DRAINING", Cluster: "cluster1", Group: "cluster1"},
+		{ServiceName: "service3", RunningCount: 3, DesiredCount: 3, Status: "ACTIVE", Cluster: "cluster2", Group: "cluster2"},
+		{ServiceName: "service4", RunningCount: 0, DesiredCount: 2, Status: "INACTIVE", Cluster: "cluster2", Group: "cluster2"},
 	}
 
 	assert.ElementsMatch(t, expectedServices, services)
 	mockClient.AssertExpectations(t)
 }
 
+func TestGetAllServiceDetailsReportsClusterFailures(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListClusters", ctx, mock.AnythingOfType("*ecs.ListClustersInput"), mock.Anything).Return(&ecs.ListClustersOutput{
+		ClusterArns: []string{"cluster1", "cluster2"},
+	}, nil)
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1")}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service1"},
+	}, nil)
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster2")}, mock.Anything).Return(&ecs.ListServicesOutput{}, errors.New("access denied"))
+	mockClient.On("DescribeServices", ctx, mock.AnythingOfType("*ecs.DescribeServicesInput"), mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{ServiceName: aws.String("service1"), RunningCount: 1, DesiredCount: 1, Status: aws.String("ACTIVE")},
+		},
+	}, nil)
+
+	services, err := GetAllServiceDetails(ctx, mockClient)
+
+	assert.Len(t, services, 1)
+	assert.Equal(t, "service1", services[0].ServiceName)
+
+	var clusterErrs ClusterErrors
+	assert.True(t, errors.As(err, &clusterErrs))
+	assert.Len(t, clusterErrs, 1)
+	assert.Contains(t, clusterErrs, "cluster2")
+	assert.Contains(t, err.Error(), "1 cluster(s) failed to load")
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceDetailsForClusters(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	// Only the selected cluster is described; ListClusters is never called
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster2")}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service3"},
+	}, nil)
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("cluster2"),
+		Services: []string{"service3"},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{ServiceName: aws.String("service3"), RunningCount: 1, DesiredCount: 1, Status: aws.String("ACTIVE")},
+		},
+	}, nil)
+
+	services, err := GetServiceDetailsForClusters(ctx, mockClient, []string{"cluster2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.ServiceDetails{
+		{ServiceName: "service3", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE", Cluster: "cluster2", Group: "cluster2"},
+	}, services)
+	mockClient.AssertNotCalled(t, "ListClusters", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetClusterServiceDetailsWithDescribeFailures(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1")}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service1", "service2"},
+	}, nil)
+	// service2 was deleted between ListServices and DescribeServices
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("cluster1"),
+		Services: []string{"service1", "service2"},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{ServiceName: aws.String("service1"), RunningCount: 1, DesiredCount: 1, Status: aws.String("ACTIVE")},
+		},
+		Failures: []types.Failure{
+			{Arn: aws.String("service2"), Reason: aws.String("MISSING")},
+		},
+	}, nil)
+
+	services, err := GetClusterServiceDetails(ctx, mockClient, "cluster1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.ServiceDetails{
+		{ServiceName: "service1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE", Cluster: "cluster1", Group: "cluster1"},
+	}, services)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetClusterServiceDetailsWhenAllDescribesFail(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1")}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service1", "service2", "service3"},
+	}, nil)
+	mockClient.On("DescribeServices", ctx, mock.AnythingOfType("*ecs.DescribeServicesInput"), mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Failures: []types.Failure{
+			{Arn: aws.String("service1"), Reason: aws.String("ACCESS_DENIED")},
+			{Arn: aws.String("service2"), Reason: aws.String("ACCESS_DENIED")},
+			{Arn: aws.String("service3"), Reason: aws.String("MISSING")},
+		},
+	}, nil)
+
+	services, err := GetClusterServiceDetails(ctx, mockClient, "cluster1")
+
+	assert.Empty(t, services)
+	assert.EqualError(t, err, "error describing services in cluster cluster1: all 3 services failed: ACCESS_DENIED (2), MISSING")
+	mockClient.AssertExpectations(t)
+}
+
+func TestSortServices(t *testing.T) {
+	services := []pkg.ServiceDetails{
+		{ServiceName: "beta", Cluster: "cluster2"},
+		{ServiceName: "beta", Cluster: "cluster1"},
+		{ServiceName: "alpha", Cluster: "cluster2"},
+		{ServiceName: "alpha", Cluster: "cluster1"},
+	}
+
+	SortServices(services)
+
+	expected := []pkg.ServiceDetails{
+		{ServiceName: "alpha", Cluster: "cluster1"},
+		{ServiceName: "beta", Cluster: "cluster1"},
+		{ServiceName: "alpha", Cluster: "cluster2"},
+		{ServiceName: "beta", Cluster: "cluster2"},
+	}
+	assert.Equal(t, expected, services)
+}
+
 func TestUpdateServiceDesiredCount(t *testing.T) {
 	mockClient := new(MockECSClient)
 	ctx := context.Background()
@@ -172,6 +323,78 @@ func TestUpdateServiceDesiredCount(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestScaleServices(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("UpdateService", ctx, mock.MatchedBy(func(input *ecs.UpdateServiceInput) bool {
+		return *input.Service == "broken"
+	}), mock.Anything).Return(&ecs.UpdateServiceOutput{}, errors.New("access denied"))
+	mockClient.On("UpdateService", ctx, mock.MatchedBy(func(input *ecs.UpdateServiceInput) bool {
+		return *input.Service != "broken" && *input.DesiredCount == 0
+	}), mock.Anything).Return(&ecs.UpdateServiceOutput{}, nil)
+
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "dev"},
+		{ServiceName: "broken", Cluster: "dev"},
+		{ServiceName: "worker", Cluster: "dev"},
+	}
+	results := ScaleServices(ctx, mockClient, services, 0)
+
+	assert.Len(t, results, 3)
+	assert.Equal(t, "api", results[0].Service.ServiceName)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "broken", results[1].Service.ServiceName)
+	assert.Error(t, results[1].Err)
+	assert.NoError(t, results[2].Err)
+	mockClient.AssertExpectations(t)
+}
+
+// inFlightECSClient records the most UpdateService calls in flight at once
+type inFlightECSClient struct {
+	ECSClientAPI
+	mu       sync.Mutex
+	inFlight int
+	most     int
+}
+
+func (c *inFlightECSClient) UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error) {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.most {
+		c.most = c.inFlight
+	}
+	c.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return &ecs.UpdateServiceOutput{}, nil
+}
+
+func TestUpdateDesiredCountsBoundsConcurrency(t *testing.T) {
+	client := &inFlightECSClient{}
+	updates := make([]DesiredCountUpdate, 50)
+	for i := range updates {
+		updates[i] = DesiredCountUpdate{Service: pkg.ServiceDetails{ServiceName: fmt.Sprintf("service%d", i), Cluster: "dev"}}
+	}
+
+	results := UpdateDesiredCounts(context.Background(), client, updates)
+
+	assert.Len(t, results, 50)
+	assert.LessOrEqual(t, client.most, updateDesiredCountsConcurrency)
+}
+
+func TestScalableServices(t *testing.T) {
+	api := pkg.ServiceDetails{ServiceName: "api", SchedulingStrategy: "REPLICA"}
+	agent := pkg.ServiceDetails{ServiceName: "agent", SchedulingStrategy: "DAEMON"}
+
+	scalable, daemons := ScalableServices([]pkg.ServiceDetails{api, agent})
+
+	assert.Equal(t, []pkg.ServiceDetails{api}, scalable)
+	assert.Equal(t, []pkg.ServiceDetails{agent}, daemons)
+}
+
 func TestGetServiceDetails(t *testing.T) {
 	mockClient := new(MockECSClient)
 	ctx := context.Background()
@@ -182,10 +405,15 @@ func TestGetServiceDetails(t *testing.T) {
 	mockClient.On("DescribeServices", ctx, mock.AnythingOfType("*ecs.DescribeServicesInput"), mock.Anything).Return(&ecs.DescribeServicesOutput{
 		Services: []types.Service{
 			{
-				ServiceName:  aws.String(serviceName),
-				RunningCount: 2,
-				DesiredCount: 2,
-				Status:       aws.String("ACTIVE"),
+				ServiceName:        aws.String(serviceName),
+				RunningCount:       2,
+				DesiredCount:       2,
+				Status:             aws.String("ACTIVE"),
+				SchedulingStrategy: types.SchedulingStrategyDaemon,
+				DeploymentConfiguration: &types.DeploymentConfiguration{
+					MinimumHealthyPercent: aws.Int32(0),
+					MaximumPercent:        aws.Int32(100),
+				},
 			},
 		},
 	}, nil)
@@ -196,5 +424,727 @@ func TestGetServiceDetails(t *testing.T) {
 	assert.Equal(t, serviceName, service.ServiceName)
 	assert.Equal(t, int64(2), service.RunningCount)
 	assert.Equal(t, int64(2), service.DesiredCount)
+	assert.Equal(t, "DAEMON", service.SchedulingStrategy)
+	assert.Equal(t, int64(0), *service.MinimumHealthyPercent)
+	assert.Equal(t, int64(100), *service.MaximumPercent)
+	assert.Empty(t, service.PlatformVersion)
+	mockClient.AssertExpectations(t)
+}
+
+func TestPlatformVersionOnlyForFargate(t *testing.T) {
+	fargate := newServiceDetails(types.Service{
+		ServiceName:     aws.String("api"),
+		Status:          aws.String("ACTIVE"),
+		LaunchType:      types.LaunchTypeFargate,
+		PlatformVersion: aws.String("1.4.0"),
+	}, "prod")
+	assert.Equal(t, "1.4.0", fargate.PlatformVersion)
+	assert.True(t, fargate.IsFargate())
+
+	spot := newServiceDetails(types.Service{
+		ServiceName:              aws.String("batch"),
+		Status:                   aws.String("ACTIVE"),
+		CapacityProviderStrategy: []types.CapacityProviderStrategyItem{{CapacityProvider: aws.String("FARGATE_SPOT"), Weight: 1}},
+		PlatformVersion:          aws.String("LATEST"),
+	}, "prod")
+	assert.Equal(t, "LATEST", spot.PlatformVersion)
+	assert.Equal(t, []string{"FARGATE_SPOT"}, spot.CapacityProviders)
+	assert.True(t, spot.IsFargate())
+
+	ec2 := newServiceDetails(types.Service{
+		ServiceName:     aws.String("worker"),
+		Status:          aws.String("ACTIVE"),
+		LaunchType:      types.LaunchTypeEc2,
+		PlatformVersion: aws.String("LATEST"),
+	}, "prod")
+	assert.Empty(t, ec2.PlatformVersion)
+	assert.False(t, ec2.IsFargate())
+}
+
+func TestNetworkConfigurationOnlyForAwsvpc(t *testing.T) {
+	awsvpc := newServiceDetails(types.Service{
+		ServiceName: aws.String("api"),
+		Status:      aws.String("ACTIVE"),
+		NetworkConfiguration: &types.NetworkConfiguration{
+			AwsvpcConfiguration: &types.AwsVpcConfiguration{
+				Subnets:        []string{"subnet-1", "subnet-2"},
+				SecurityGroups: []string{"sg-1"},
+				AssignPublicIp: types.AssignPublicIpDisabled,
+			},
+		},
+	}, "prod")
+	assert.Equal(t, &pkg.NetworkConfiguration{
+		Subnets:        []string{"subnet-1", "subnet-2"},
+		SecurityGroups: []string{"sg-1"},
+		AssignPublicIP: "DISABLED",
+	}, awsvpc.Network)
+
+	bridge := newServiceDetails(types.Service{
+		ServiceName: aws.String("worker"),
+		Status:      aws.String("ACTIVE"),
+	}, "prod")
+	assert.Nil(t, bridge.Network)
+}
+
+func TestPlacementBlocked(t *testing.T) {
+	event := func(message string) types.ServiceEvent {
+		return types.ServiceEvent{Message: aws.String(message)}
+	}
+	unplaceable := event("(service api) was unable to place a task because no container instance met all of its requirements.")
+	steady := event("(service api) has reached a steady state.")
+
+	assert.True(t, placementBlocked([]types.ServiceEvent{unplaceable, steady}))
+	assert.False(t, placementBlocked([]types.ServiceEvent{steady, unplaceable}))
+	assert.False(t, placementBlocked(nil))
+}
+
+func TestIsDeploying(t *testing.T) {
+	assert.False(t, isDeploying(nil))
+	assert.False(t, isDeploying([]types.Deployment{{RolloutState: types.DeploymentRolloutStateCompleted}}))
+	assert.True(t, isDeploying([]types.Deployment{{RolloutState: types.DeploymentRolloutStateInProgress}}))
+	// Deployments without a rollout state (e.g. the ECS deployment controller) are still draining
+	assert.True(t, isDeploying([]types.Deployment{{Status: aws.String("PRIMARY")}, {Status: aws.String("ACTIVE")}}))
+}
+
+func TestPrimaryDeployment(t *testing.T) {
+	assert.Nil(t, primaryDeployment(nil))
+	deployments := []types.Deployment{
+		{Id: aws.String("ecs-svc/old"), Status: aws.String("ACTIVE"), RunningCount: 3},
+		{Id: aws.String("ecs-svc/new"), Status: aws.String("PRIMARY"), RunningCount: 1, CreatedAt: aws.Time(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))},
+	}
+	assert.Equal(t, "ecs-svc/new", aws.ToString(primaryDeployment(deployments).Id))
+
+	details := newServiceDetails(types.Service{ServiceName: aws.String("api"), Status: aws.String("ACTIVE"), Deployments: deployments}, "prod")
+	assert.Equal(t, "ecs-svc/new", details.DeploymentID)
+	assert.Equal(t, int64(1), details.DeploymentRunningCount)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), *details.LastDeployedAt)
+}
+
+func TestCircuitBreakerAndRollback(t *testing.T) {
+	details := newServiceDetails(types.Service{
+		ServiceName: aws.String("api"),
+		Status:      aws.String("ACTIVE"),
+		DeploymentConfiguration: &types.DeploymentConfiguration{
+			DeploymentCircuitBreaker: &types.DeploymentCircuitBreaker{Enable: true, Rollback: true},
+		},
+		Deployments: []types.Deployment{
+			{
+				Id:                 aws.String("ecs-svc/2"),
+				Status:             aws.String("PRIMARY"),
+				RolloutState:       types.DeploymentRolloutStateInProgress,
+				RolloutStateReason: aws.String("ECS deployment circuit breaker: rolling back to deploymentId ecs-svc/1."),
+			},
+			{
+				Id:                 aws.String("ecs-svc/3"),
+				Status:             aws.String("ACTIVE"),
+				RolloutState:       types.DeploymentRolloutStateFailed,
+				RolloutStateReason: aws.String("ECS deployment circuit breaker: tasks failed to start."),
+			},
+		},
+	}, "prod")
+	assert.Equal(t, &pkg.CircuitBreaker{Enabled: true, Rollback: true}, details.CircuitBreaker)
+	assert.Equal(t, "ECS deployment circuit breaker: tasks failed to start.", details.FailedDeployment)
+	assert.Equal(t, "ECS deployment circuit breaker: rolling back to deploymentId ecs-svc/1.", details.Rollback)
+
+	healthy := newServiceDetails(types.Service{
+		ServiceName: aws.String("api"),
+		Status:      aws.String("ACTIVE"),
+		Deployments: []types.Deployment{{Id: aws.String("ecs-svc/1"), RolloutState: types.DeploymentRolloutStateCompleted}},
+	}, "prod")
+	assert.Nil(t, healthy.CircuitBreaker)
+	assert.Empty(t, healthy.FailedDeployment)
+	assert.Empty(t, healthy.Rollback)
+}
+
+func TestServiceDeployments(t *testing.T) {
+	rolling := serviceDeployments(types.Service{Deployments: []types.Deployment{
+		{Id: aws.String("ecs-svc/2"), Status: aws.String("PRIMARY"), TaskDefinition: aws.String("api:8"), DesiredCount: 4, RunningCount: 1, PendingCount: 2, RolloutState: types.DeploymentRolloutStateInProgress},
+	}})
+	assert.Equal(t, []pkg.Deployment{
+		{ID: "ecs-svc/2", Status: "PRIMARY", TaskDefinition: "api:8", DesiredCount: 4, RunningCount: 1, PendingCount: 2, RolloutState: "IN_PROGRESS"},
+	}, rolling)
+
+	// Task sets take precedence over deployments
+	canary := serviceDeployments(types.Service{
+		Deployments: []types.Deployment{{Id: aws.String("ecs-svc/1")}},
+		TaskSets: []types.TaskSet{{
+			Id:                   aws.String("ecs-svc/3"),
+			Status:               aws.String("ACTIVE"),
+			TaskDefinition:       aws.String("api:9"),
+			ComputedDesiredCount: 1,
+			RunningCount:         1,
+			Scale:                &types.Scale{Unit: types.ScaleUnitPercent, Value: 10},
+		}},
+	})
+	assert.Len(t, canary, 1)
+	assert.Equal(t, "ecs-svc/3", canary[0].ID)
+	assert.Equal(t, int64(1), canary[0].DesiredCount)
+	assert.Equal(t, 10.0, *canary[0].ScalePercent)
+}
+
+func TestExecuteCommandAndTagSettings(t *testing.T) {
+	details := newServiceDetails(types.Service{
+		ServiceName:          aws.String("api"),
+		Status:               aws.String("ACTIVE"),
+		EnableExecuteCommand: true,
+		PropagateTags:        types.PropagateTagsService,
+		EnableECSManagedTags: true,
+	}, "prod")
+	assert.True(t, details.ExecuteCommandEnabled)
+	assert.Equal(t, "SERVICE", details.PropagateTags)
+	assert.True(t, details.ManagedTags)
+}
+
+func TestGetContainerInstances(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockECSClient)
+	mockClient.On("DescribeContainerInstances", ctx, mock.MatchedBy(func(input *ecs.DescribeContainerInstancesInput) bool {
+		return *input.Cluster == "prod" && assert.ObjectsAreEqual([]string{"ci/1", "ci/2"}, input.ContainerInstances)
+	}), mock.Anything).Return(&ecs.DescribeContainerInstancesOutput{
+		ContainerInstances: []types.ContainerInstance{{
+			ContainerInstanceArn: aws.String("ci/1"),
+			Ec2InstanceId:        aws.String("i-0abc"),
+			Status:               aws.String("ACTIVE"),
+			AgentConnected:       true,
+			RunningTasksCount:    4,
+			Attributes:           []types.Attribute{{Name: aws.String("ecs.availability-zone"), Value: aws.String("eu-west-1a")}},
+			RegisteredResources:  []types.Resource{{Name: aws.String("CPU"), IntegerValue: 2048}, {Name: aws.String("MEMORY"), IntegerValue: 3904}},
+			RemainingResources:   []types.Resource{{Name: aws.String("CPU"), IntegerValue: 512}, {Name: aws.String("MEMORY"), IntegerValue: 1024}},
+		}},
+	}, nil).Once()
+
+	// Empty and duplicate ARNs aren't described
+	instances, err := GetContainerInstances(ctx, mockClient, "prod", []string{"ci/1", "", "ci/2", "ci/1"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]pkg.ContainerInstance{"ci/1": {
+		ContainerInstanceArn: "ci/1",
+		EC2InstanceID:        "i-0abc",
+		AvailabilityZone:     "eu-west-1a",
+		Status:               "ACTIVE",
+		AgentConnected:       true,
+		RunningTasksCount:    4,
+		RegisteredCPU:        2048,
+		RegisteredMemoryMiB:  3904,
+		RemainingCPU:         512,
+		RemainingMemoryMiB:   1024,
+	}}, instances)
+	mockClient.AssertExpectations(t)
+
+	instances, err = GetContainerInstances(ctx, mockClient, "prod", []string{""})
+	assert.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func TestClusterGroup(t *testing.T) {
+	assert.Equal(t, "payments-prod-cluster", ClusterName("arn:aws:ecs:us-east-1:123456789012:cluster/payments-prod-cluster"))
+	assert.Equal(t, "payments", ClusterGroup("arn:aws:ecs:us-east-1:123456789012:cluster/payments-prod-cluster"))
+	assert.Equal(t, "payments", ClusterGroup("payments-prod"))
+	assert.Equal(t, "standalone", ClusterGroup("standalone"))
+}
+
+func TestListTaskDefinitionRevisions(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListTaskDefinitions", ctx, mock.MatchedBy(func(input *ecs.ListTaskDefinitionsInput) bool {
+		return *input.FamilyPrefix == "web" &&
+			input.Status == types.TaskDefinitionStatusActive &&
+			input.Sort == types.SortOrderDesc
+	}), mock.Anything).Return(&ecs.ListTaskDefinitionsOutput{
+		TaskDefinitionArns: []string{
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web-worker:7",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:3",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:2",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:1",
+		},
+	}, nil)
+
+	revisions, err := ListTaskDefinitionRevisions(ctx, mockClient, "web", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:3",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/web:2",
+	}, revisions)
 	mockClient.AssertExpectations(t)
 }
+
+func TestUpdateServiceTaskDefinition(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+	taskDefinition := "arn:aws:ecs:us-east-1:123456789012:task-definition/web:2"
+
+	mockClient.On("UpdateService", ctx, mock.MatchedBy(func(input *ecs.UpdateServiceInput) bool {
+		return *input.Cluster == "test-cluster" &&
+			*input.Service == "test-service" &&
+			*input.TaskDefinition == taskDefinition
+	}), mock.Anything).Return(&ecs.UpdateServiceOutput{}, nil)
+
+	err := UpdateServiceTaskDefinition(ctx, mockClient, "test-service", "test-cluster", taskDefinition)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "web", TaskDefinitionFamily(taskDefinition))
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetPreviousTaskDefinition(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListTaskDefinitions", ctx, mock.AnythingOfType("*ecs.ListTaskDefinitionsInput"), mock.Anything).Return(&ecs.ListTaskDefinitionsOutput{
+		TaskDefinitionArns: []string{
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:6",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:5",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web-worker:4",
+			"arn:aws:ecs:us-east-1:123456789012:task-definition/web:3",
+		},
+	}, nil)
+
+	previous, err := GetPreviousTaskDefinition(ctx, mockClient, "arn:aws:ecs:us-east-1:123456789012:task-definition/web:5")
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3", previous)
+
+	_, err = GetPreviousTaskDefinition(ctx, mockClient, "arn:aws:ecs:us-east-1:123456789012:task-definition/web:3")
+	assert.Error(t, err)
+}
+
+func TestServiceConsoleURL(t *testing.T) {
+	consoleURL, err := ServiceConsoleURL("arn:aws:ecs:eu-west-1:123456789012:cluster/prod", "api")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://eu-west-1.console.aws.amazon.com/ecs/v2/clusters/prod/services/api/health?region=eu-west-1", consoleURL)
+
+	_, err = ServiceConsoleURL("prod", "api")
+	assert.Error(t, err)
+}
+
+func TestParseARN(t *testing.T) {
+	cluster, err := ParseARN("arn:aws:ecs:eu-west-1:123456789012:cluster/prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cluster.Region)
+	assert.Equal(t, "123456789012", cluster.AccountID)
+	assert.Equal(t, "cluster/prod", cluster.Resource)
+
+	service, err := ParseARN("arn:aws-cn:ecs:cn-north-1:210987654321:service/prod/api")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws-cn", service.Partition)
+	assert.Equal(t, "cn-north-1", service.Region)
+	assert.Equal(t, "210987654321", service.AccountID)
+	assert.Equal(t, "service/prod/api", service.Resource)
+
+	_, err = ParseARN("prod")
+	assert.Error(t, err)
+	_, err = ParseARN("arn:aws:iam::123456789012:role/admin")
+	assert.Error(t, err)
+}
+
+func TestGetStoppedTasks(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	mockClient.On("ListTasks", ctx, mock.MatchedBy(func(input *ecs.ListTasksInput) bool {
+		return *input.ServiceName == "api" && input.DesiredStatus == types.DesiredStatusStopped
+	}), mock.Anything).Return(&ecs.ListTasksOutput{
+		TaskArns: []string{"task1", "task2"},
+	}, nil)
+	mockClient.On("DescribeTasks", ctx, mock.AnythingOfType("*ecs.DescribeTasksInput"), mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{
+			{
+				TaskArn:       aws.String("task1"),
+				LastStatus:    aws.String("STOPPED"),
+				StoppedAt:     aws.Time(now.Add(-10 * time.Minute)),
+				StoppedReason: aws.String("Scaling activity initiated by deployment"),
+				Containers: []types.Container{
+					{Name: aws.String("app"), ExitCode: aws.Int32(0)},
+				},
+			},
+			{
+				TaskArn:       aws.String("task2"),
+				LastStatus:    aws.String("STOPPED"),
+				StoppedAt:     aws.Time(now),
+				StoppedReason: aws.String("Essential container in task exited"),
+				Containers: []types.Container{
+					{Name: aws.String("app"), ExitCode: aws.Int32(137), Reason: aws.String("OutOfMemoryError")},
+				},
+			},
+		},
+	}, nil)
+
+	tasks, err := GetStoppedTasks(ctx, mockClient, "prod", "api")
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, "task2", tasks[0].TaskArn)
+	assert.Equal(t, "Essential container in task exited", tasks[0].StoppedReason)
+	assert.Equal(t, int32(137), *tasks[0].Containers[0].ExitCode)
+	assert.Equal(t, "OutOfMemoryError", tasks[0].Containers[0].Reason)
+	assert.Equal(t, "task1", tasks[1].TaskArn)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceEvents(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("prod"),
+		Services: []string{"api"},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{
+				ServiceName: aws.String("api"),
+				Events: []types.ServiceEvent{
+					{Id: aws.String("2"), Message: aws.String("(service api) has reached a steady state.")},
+					{Id: aws.String("1"), Message: aws.String("(service api) has started 1 tasks.")},
+				},
+			},
+		},
+	}, nil)
+
+	events, err := GetServiceEvents(ctx, mockClient, "prod", "api")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.ServiceEvent{
+		{ID: "1", Message: "(service api) has started 1 tasks."},
+		{ID: "2", Message: "(service api) has reached a steady state."},
+	}, events)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceJSON(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("prod"),
+		Services: []string{"api"},
+		Include:  []types.ServiceField{types.ServiceFieldTags},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{{
+			ServiceName:  aws.String("api"),
+			DesiredCount: 3,
+			Tags:         []types.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		}},
+	}, nil)
+
+	text, err := GetServiceJSON(ctx, mockClient, "prod", "api")
+
+	assert.NoError(t, err)
+	assert.Contains(t, text, `"ServiceName": "api"`)
+	assert.Contains(t, text, `"DesiredCount": 3`)
+	assert.Contains(t, text, `"Value": "payments"`)
+	mockClient.AssertExpectations(t)
+}
+
+func TestTaskSetStatus(t *testing.T) {
+	primary := types.TaskSet{Status: aws.String("PRIMARY"), StabilityStatus: types.StabilityStatusSteadyState, RunningCount: 3, ComputedDesiredCount: 3}
+	assert.Equal(t, "Stable", taskSetStatus([]types.TaskSet{primary}))
+
+	// A blue/green deployment still shifting traffic from the old task set
+	draining := types.TaskSet{Status: aws.String("ACTIVE"), StabilityStatus: types.StabilityStatusSteadyState, RunningCount: 3, ComputedDesiredCount: 3}
+	primary.RunningCount = 1
+	assert.Equal(t, "Deploying (1/3)", taskSetStatus([]types.TaskSet{draining, primary}))
+
+	primary.StabilityStatus = types.StabilityStatusStabilizing
+	assert.Equal(t, "Deploying (1/3)", taskSetStatus([]types.TaskSet{primary}))
+	assert.Equal(t, "Unknown", taskSetStatus(nil))
+}
+
+func TestDeploymentState(t *testing.T) {
+	assert.Equal(t, DeploymentStateDeploying, DeploymentState("Deploying (1/3)"))
+	assert.Equal(t, DeploymentStateStable, DeploymentState("Stable"))
+	assert.Equal(t, DeploymentStateFailed, DeploymentState("Deployment Failed"))
+	assert.Equal(t, DeploymentStateOther, DeploymentState("PRIMARY"))
+	assert.Equal(t, DeploymentStateOther, DeploymentState("Unknown"))
+}
+
+func TestGetDeploymentStatuses(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("prod"),
+		Services: []string{"api"},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{Deployments: []types.Deployment{{RolloutState: types.DeploymentRolloutStateInProgress, RunningCount: 1, DesiredCount: 2}}},
+		},
+	}, nil)
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String("prod"),
+		Services: []string{"web"},
+	}, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{
+			{Deployments: []types.Deployment{{RolloutState: types.DeploymentRolloutStateCompleted, RunningCount: 2, DesiredCount: 2}}},
+		},
+	}, nil)
+
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod"},
+		{ServiceName: "web", Cluster: "prod"},
+	}
+	results := GetDeploymentStatuses(ctx, mockClient, services, 2)
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "api", results[0].Service.ServiceName)
+	assert.Equal(t, "Deploying (1/2)", results[0].Status)
+	assert.Equal(t, "web", results[1].Service.ServiceName)
+	assert.Equal(t, "Stable", results[1].Status)
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListServiceTasks(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListTasks", ctx, &ecs.ListTasksInput{Cluster: aws.String("prod"), ServiceName: aws.String("api")}, mock.Anything).Return(&ecs.ListTasksOutput{
+		TaskArns:  []string{"task1"},
+		NextToken: aws.String("page2"),
+	}, nil)
+	mockClient.On("ListTasks", ctx, &ecs.ListTasksInput{Cluster: aws.String("prod"), ServiceName: aws.String("api"), NextToken: aws.String("page2")}, mock.Anything).Return(&ecs.ListTasksOutput{
+		TaskArns: []string{"task2"},
+	}, nil)
+	mockClient.On("DescribeTasks", ctx, &ecs.DescribeTasksInput{Cluster: aws.String("prod"), Tasks: []string{"task1", "task2"}}, mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{
+			{TaskArn: aws.String("task1"), LastStatus: aws.String("RUNNING"), HealthStatus: types.HealthStatusHealthy, AvailabilityZone: aws.String("eu-west-1a")},
+			{TaskArn: aws.String("task2"), LastStatus: aws.String("PENDING"), HealthStatus: types.HealthStatusUnknown},
+		},
+	}, nil)
+
+	tasks, err := ListServiceTasks(ctx, mockClient, "prod", "api")
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	assert.Equal(t, "task1", tasks[0].TaskArn)
+	assert.Equal(t, "RUNNING", tasks[0].LastStatus)
+	assert.Equal(t, "HEALTHY", tasks[0].HealthStatus)
+	assert.Equal(t, "eu-west-1a", tasks[0].AvailabilityZone)
+	assert.Equal(t, "PENDING", tasks[1].LastStatus)
+	mockClient.AssertExpectations(t)
+}
+
+func TestListServiceTasksInBatches(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	taskArns := make([]string, 150)
+	for i := range taskArns {
+		taskArns[i] = fmt.Sprintf("task%d", i)
+	}
+	describedTasks := func(arns []string) *ecs.DescribeTasksOutput {
+		tasks := make([]types.Task, len(arns))
+		for i, arn := range arns {
+			tasks[i] = types.Task{TaskArn: aws.String(arn), LastStatus: aws.String("RUNNING")}
+		}
+		return &ecs.DescribeTasksOutput{Tasks: tasks}
+	}
+
+	mockClient.On("ListTasks", ctx, mock.Anything, mock.Anything).Return(&ecs.ListTasksOutput{TaskArns: taskArns}, nil)
+	mockClient.On("DescribeTasks", ctx, &ecs.DescribeTasksInput{Cluster: aws.String("prod"), Tasks: taskArns[:100]}, mock.Anything).Return(describedTasks(taskArns[:100]), nil)
+	mockClient.On("DescribeTasks", ctx, &ecs.DescribeTasksInput{Cluster: aws.String("prod"), Tasks: taskArns[100:]}, mock.Anything).Return(describedTasks(taskArns[100:]), nil)
+
+	tasks, err := ListServiceTasks(ctx, mockClient, "prod", "api")
+
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 150)
+	assert.Equal(t, "task149", tasks[149].TaskArn)
+	mockClient.AssertNumberOfCalls(t, "DescribeTasks", 2)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetTaskReservation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("task-level sizes", func(t *testing.T) {
+		mockClient := new(MockECSClient)
+		mockClient.On("DescribeTaskDefinition", ctx, mock.MatchedBy(func(input *ecs.DescribeTaskDefinitionInput) bool {
+			return *input.TaskDefinition == "web:42"
+		}), mock.Anything).Return(&ecs.DescribeTaskDefinitionOutput{
+			TaskDefinition: &types.TaskDefinition{
+				Cpu:                  aws.String("512"),
+				Memory:               aws.String("1024"),
+				ContainerDefinitions: []types.ContainerDefinition{{Cpu: 256, Memory: aws.Int32(512)}},
+			},
+		}, nil)
+
+		reservation, err := GetTaskReservation(ctx, mockClient, "web:42")
+		assert.NoError(t, err)
+		assert.Equal(t, pkg.TaskReservation{CPU: 512, MemoryMiB: 1024}, reservation)
+	})
+
+	t.Run("container sizes", func(t *testing.T) {
+		mockClient := new(MockECSClient)
+		mockClient.On("DescribeTaskDefinition", ctx, mock.Anything, mock.Anything).Return(&ecs.DescribeTaskDefinitionOutput{
+			TaskDefinition: &types.TaskDefinition{
+				ContainerDefinitions: []types.ContainerDefinition{
+					{Cpu: 256, Memory: aws.Int32(512)},
+					{Cpu: 128, MemoryReservation: aws.Int32(128)},
+				},
+			},
+		}, nil)
+
+		reservation, err := GetTaskReservation(ctx, mockClient, "worker:3")
+		assert.NoError(t, err)
+		assert.Equal(t, pkg.TaskReservation{CPU: 384, MemoryMiB: 640}, reservation)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockClient := new(MockECSClient)
+		mockClient.On("DescribeTaskDefinition", ctx, mock.Anything, mock.Anything).
+			Return(&ecs.DescribeTaskDefinitionOutput{}, errors.New("access denied"))
+
+		_, err := GetTaskReservation(ctx, mockClient, "web:42")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetTaskDefinitionSummary(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockECSClient)
+	mockClient.On("DescribeTaskDefinition", ctx, mock.MatchedBy(func(input *ecs.DescribeTaskDefinitionInput) bool {
+		return *input.TaskDefinition == "web:3"
+	}), mock.Anything).Return(&ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: &types.TaskDefinition{
+			Cpu:    aws.String("512"),
+			Memory: aws.String("1024"),
+			ContainerDefinitions: []types.ContainerDefinition{
+				{
+					Name:        aws.String("api"),
+					Image:       aws.String("repo/api:3"),
+					Cpu:         256,
+					Memory:      aws.Int32(512),
+					Environment: []types.KeyValuePair{{Name: aws.String("PORT"), Value: aws.String("8080")}},
+				},
+				{Name: aws.String("sidecar"), Image: aws.String("repo/sidecar:1")},
+			},
+		},
+	}, nil)
+
+	summary, err := GetTaskDefinitionSummary(ctx, mockClient, "web:3")
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.TaskDefinitionSummary{
+		TaskDefinition: "web:3",
+		CPU:            "512",
+		Memory:         "1024",
+		Containers: []pkg.ContainerDefinitionSummary{
+			{Name: "api", Image: "repo/api:3", CPU: 256, Memory: aws.Int32(512), Environment: map[string]string{"PORT": "8080"}},
+			{Name: "sidecar", Image: "repo/sidecar:1"},
+		},
+	}, summary)
+
+	failing := new(MockECSClient)
+	failing.On("DescribeTaskDefinition", ctx, mock.Anything, mock.Anything).
+		Return(&ecs.DescribeTaskDefinitionOutput{}, errors.New("access denied"))
+	_, err = GetTaskDefinitionSummary(ctx, failing, "web:3")
+	assert.Error(t, err)
+}
+
+func TestListStandaloneTasks(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockECSClient)
+	earlier := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+
+	mockClient.On("ListTasks", ctx, mock.MatchedBy(func(input *ecs.ListTasksInput) bool {
+		return input.DesiredStatus == types.DesiredStatusRunning && input.ServiceName == nil && *input.StartedBy == "events-rule/nightly"
+	}), mock.Anything).Return(&ecs.ListTasksOutput{TaskArns: []string{"task-service", "task-running"}}, nil)
+	mockClient.On("ListTasks", ctx, mock.MatchedBy(func(input *ecs.ListTasksInput) bool {
+		return input.DesiredStatus == types.DesiredStatusStopped
+	}), mock.Anything).Return(&ecs.ListTasksOutput{TaskArns: []string{"task-stopped"}}, nil)
+	mockClient.On("DescribeTasks", ctx, mock.Anything, mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{
+			{TaskArn: aws.String("task-service"), Group: aws.String("service:api"), StartedAt: &later},
+			{TaskArn: aws.String("task-stopped"), Group: aws.String("family:report"), StartedAt: &earlier, StartedBy: aws.String("events-rule/nightly")},
+			{TaskArn: aws.String("task-running"), Group: aws.String("family:report"), StartedAt: &later, TaskDefinitionArn: aws.String("arn:aws:ecs:eu-west-1:123456789012:task-definition/report:3")},
+		},
+	}, nil)
+
+	tasks, err := ListStandaloneTasks(ctx, mockClient, "prod", "events-rule/nightly")
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, "task-running", tasks[0].TaskArn)
+		assert.Equal(t, "arn:aws:ecs:eu-west-1:123456789012:task-definition/report:3", tasks[0].TaskDefinition)
+		assert.Equal(t, "task-stopped", tasks[1].TaskArn)
+		assert.Equal(t, "events-rule/nightly", tasks[1].StartedBy)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestPollServiceUpdatesSeparateIntervals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient := new(MockECSClient)
+
+	updates := PollServiceUpdates(ctx, mockClient, nil, 10*time.Millisecond, 35*time.Millisecond)
+
+	serviceTicks, metricsTicks := 0, 0
+	for metricsTicks == 0 {
+		update := <-updates
+		if update.MetricsDue {
+			assert.Nil(t, update.Services)
+			metricsTicks++
+		} else {
+			assert.NotNil(t, update.Services)
+			serviceTicks++
+		}
+	}
+	// Services are polled several times for every metrics tick
+	assert.GreaterOrEqual(t, serviceTicks, 2)
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestFetchServiceUpdatesDropsDeletedClusters(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{Cluster: aws.String("gone"), Services: []string{"api"}}, mock.Anything).
+		Return(&ecs.DescribeServicesOutput{}, &types.ClusterNotFoundException{Message: aws.String("Cluster not found.")}).Once()
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{Cluster: aws.String("prod"), Services: []string{"api"}}, mock.Anything).
+		Return(&ecs.DescribeServicesOutput{}, errors.New("throttled"))
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{Cluster: aws.String("prod"), Services: []string{"worker"}}, mock.Anything).
+		Return(&ecs.DescribeServicesOutput{Services: []types.Service{
+			{ServiceName: aws.String("worker"), RunningCount: 2, DesiredCount: 2, Status: aws.String("ACTIVE")},
+		}}, nil)
+
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "gone"},
+		{ServiceName: "worker", Cluster: "gone"},
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1},
+		{ServiceName: "worker", Cluster: "prod"},
+	}
+	updated, deleted := FetchServiceUpdates(ctx, mockClient, services)
+
+	assert.Equal(t, []string{"gone"}, deleted)
+	assert.Len(t, updated, 2)
+	// A service that failed for another reason keeps its last known details
+	assert.Equal(t, services[2], updated[0])
+	assert.Equal(t, int64(2), updated[1].RunningCount)
+	// The rest of a deleted cluster isn't described at all
+	mockClient.AssertExpectations(t)
+}
+
+func TestPollServiceUpdatesCancelDuringFetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient := new(MockECSClient)
+
+	// Cancel while the services are being fetched
+	mockClient.On("DescribeServices", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(&ecs.DescribeServicesOutput{}, context.Canceled)
+
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod"}}
+	updates := PollServiceUpdates(ctx, mockClient, services, time.Millisecond, 0)
+
+	select {
+	case update, ok := <-updates:
+		assert.False(t, ok, "no update is sent after cancellation, got %+v", update)
+	case <-time.After(time.Second):
+		t.Fatal("updates was not closed after cancellation")
+	}
+}
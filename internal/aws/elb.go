@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+)
+
+// TargetStateUnregistered is reported for running tasks missing from a target group
+const TargetStateUnregistered = "unregistered"
+
+// ELBClientAPI defines the interface for Elastic Load Balancing v2 client operations
+type ELBClientAPI interface {
+	DescribeTargetHealth(ctx context.Context, params *elbv2.DescribeTargetHealthInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTargetHealthOutput, error)
+}
+
+// GetTargetHealth cross-references the running tasks of a service with its
+// load balancer target groups, reporting each task's state in each group.
+// Tasks are matched by IP, so only awsvpc tasks are reported.
+func GetTargetHealth(ctx context.Context, ecsClient ECSClientAPI, elbClient ELBClientAPI, service pkg.ServiceDetails) ([]pkg.TargetHealth, error) {
+	if len(service.TargetGroupArns) == 0 {
+		return nil, nil
+	}
+
+	tasks, err := ListServiceTasks(ctx, ecsClient, service.Cluster, service.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []pkg.TargetHealth
+	for _, targetGroupArn := range service.TargetGroupArns {
+		output, err := elbClient.DescribeTargetHealth(ctx, &elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: aws.String(targetGroupArn),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing target health of %s: %v", targetGroupArn, err)
+		}
+
+		byIP := make(map[string]elbtypes.TargetHealthDescription)
+		for _, description := range output.TargetHealthDescriptions {
+			if description.Target != nil {
+				byIP[aws.ToString(description.Target.Id)] = description
+			}
+		}
+
+		for _, task := range tasks {
+			if task.LastStatus != "RUNNING" || task.PrivateIP == "" {
+				continue
+			}
+			result := pkg.TargetHealth{TaskArn: task.TaskArn, TargetGroupArn: targetGroupArn, State: TargetStateUnregistered}
+			if description, ok := byIP[task.PrivateIP]; ok && description.TargetHealth != nil {
+				result.State = string(description.TargetHealth.State)
+				result.Reason = aws.ToString(description.TargetHealth.Description)
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
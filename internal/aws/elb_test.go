@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockELBClient is a mock of the Elastic Load Balancing v2 client
+type MockELBClient struct {
+	mock.Mock
+}
+
+func (m *MockELBClient) DescribeTargetHealth(ctx context.Context, params *elbv2.DescribeTargetHealthInput, optFns ...func(*elbv2.Options)) (*elbv2.DescribeTargetHealthOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*elbv2.DescribeTargetHealthOutput), args.Error(1)
+}
+
+func awsvpcTask(arn, ip string) types.Task {
+	return types.Task{
+		TaskArn:    aws.String(arn),
+		LastStatus: aws.String("RUNNING"),
+		Attachments: []types.Attachment{
+			{
+				Type:    aws.String("ElasticNetworkInterface"),
+				Details: []types.KeyValuePair{{Name: aws.String("privateIPv4Address"), Value: aws.String(ip)}},
+			},
+		},
+	}
+}
+
+func TestGetTargetHealth(t *testing.T) {
+	ecsClient := new(MockECSClient)
+	elbClient := new(MockELBClient)
+	ctx := context.Background()
+	targetGroup := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/abc123"
+
+	ecsClient.On("ListTasks", ctx, mock.AnythingOfType("*ecs.ListTasksInput"), mock.Anything).Return(&ecs.ListTasksOutput{
+		TaskArns: []string{"task1", "task2", "task3"},
+	}, nil)
+	ecsClient.On("DescribeTasks", ctx, mock.AnythingOfType("*ecs.DescribeTasksInput"), mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{
+			awsvpcTask("task1", "10.0.0.1"),
+			awsvpcTask("task2", "10.0.0.2"),
+			awsvpcTask("task3", "10.0.0.3"),
+		},
+	}, nil)
+	elbClient.On("DescribeTargetHealth", ctx, &elbv2.DescribeTargetHealthInput{TargetGroupArn: aws.String(targetGroup)}, mock.Anything).Return(&elbv2.DescribeTargetHealthOutput{
+		TargetHealthDescriptions: []elbtypes.TargetHealthDescription{
+			{Target: &elbtypes.TargetDescription{Id: aws.String("10.0.0.1")}, TargetHealth: &elbtypes.TargetHealth{State: elbtypes.TargetHealthStateEnumHealthy}},
+			{Target: &elbtypes.TargetDescription{Id: aws.String("10.0.0.2")}, TargetHealth: &elbtypes.TargetHealth{
+				State:       elbtypes.TargetHealthStateEnumUnhealthy,
+				Description: aws.String("Health checks failed"),
+			}},
+		},
+	}, nil)
+
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", TargetGroupArns: []string{targetGroup}}
+	results, err := GetTargetHealth(ctx, ecsClient, elbClient, service)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.TargetHealth{
+		{TaskArn: "task1", TargetGroupArn: targetGroup, State: "healthy"},
+		{TaskArn: "task2", TargetGroupArn: targetGroup, State: "unhealthy", Reason: "Health checks failed"},
+		{TaskArn: "task3", TargetGroupArn: targetGroup, State: TargetStateUnregistered},
+	}, results)
+	ecsClient.AssertExpectations(t)
+	elbClient.AssertExpectations(t)
+}
+
+func TestGetTargetHealthWithoutLoadBalancer(t *testing.T) {
+	results, err := GetTargetHealth(context.Background(), new(MockECSClient), new(MockELBClient), pkg.ServiceDetails{ServiceName: "worker"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, results)
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Health is a single verdict on a service, combining its ECS status, running
+// and desired counts, deployment rollout state and, where available, the
+// health of its tasks in their load balancer target groups
+type Health string
+
+const (
+	HealthHealthy   Health = "Healthy"
+	HealthDegraded  Health = "Degraded"
+	HealthUnhealthy Health = "Unhealthy"
+	HealthUnknown   Health = "Unknown"
+)
+
+// ServiceHealth computes the health of a service. targets are the results of
+// GetTargetHealth, or nil when they haven't been fetched. A service is
+// unhealthy when it isn't ACTIVE, runs no tasks at all, has a failed rollout
+// that isn't being rolled back, or has no healthy targets. It is degraded
+// when it runs fewer or more tasks than desired, is rolling back, or has
+// some targets that aren't healthy. Daemon services are only expected to
+// have some tasks running, as their desired count follows the instance count.
+func ServiceHealth(service pkg.ServiceDetails, targets []pkg.TargetHealth) Health {
+	if service.Status == "" {
+		return HealthUnknown
+	}
+	if !strings.EqualFold(service.Status, "active") {
+		return HealthUnhealthy
+	}
+	if service.DesiredCount > 0 && service.RunningCount == 0 {
+		return HealthUnhealthy
+	}
+	if service.FailedDeployment != "" && service.Rollback == "" {
+		return HealthUnhealthy
+	}
+
+	healthyTargets := 0
+	for _, target := range targets {
+		if target.State == "healthy" {
+			healthyTargets++
+		}
+	}
+	if len(targets) > 0 && healthyTargets == 0 {
+		return HealthUnhealthy
+	}
+
+	daemon := strings.EqualFold(service.SchedulingStrategy, "daemon")
+	switch {
+	case !daemon && service.RunningCount != service.DesiredCount:
+		return HealthDegraded
+	case service.FailedDeployment != "":
+		return HealthDegraded
+	case healthyTargets < len(targets):
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceHealth(t *testing.T) {
+	steady := pkg.ServiceDetails{ServiceName: "api", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2}
+	healthy := []pkg.TargetHealth{{State: "healthy"}, {State: "healthy"}}
+
+	tests := []struct {
+		name    string
+		service func(pkg.ServiceDetails) pkg.ServiceDetails
+		targets []pkg.TargetHealth
+		want    Health
+	}{
+		{"steady", nil, nil, HealthHealthy},
+		{"steady with healthy targets", nil, healthy, HealthHealthy},
+		{"not loaded", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.Status = ""; return s }, nil, HealthUnknown},
+		{"draining", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.Status = "DRAINING"; return s }, nil, HealthUnhealthy},
+		{"no tasks running", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.RunningCount = 0; return s }, nil, HealthUnhealthy},
+		{"scaled to zero", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.RunningCount, s.DesiredCount = 0, 0; return s }, nil, HealthHealthy},
+		{"below desired", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.RunningCount = 1; return s }, nil, HealthDegraded},
+		{"daemon below desired", func(s pkg.ServiceDetails) pkg.ServiceDetails {
+			s.SchedulingStrategy, s.RunningCount = "DAEMON", 1
+			return s
+		}, nil, HealthHealthy},
+		{"failed rollout", func(s pkg.ServiceDetails) pkg.ServiceDetails { s.FailedDeployment = "tasks failed to start"; return s }, nil, HealthUnhealthy},
+		{"rolling back", func(s pkg.ServiceDetails) pkg.ServiceDetails {
+			s.FailedDeployment, s.Rollback = "tasks failed to start", "rolling back to deployment ecs-svc/1"
+			return s
+		}, nil, HealthDegraded},
+		{"some targets unhealthy", nil, []pkg.TargetHealth{{State: "healthy"}, {State: "unhealthy"}}, HealthDegraded},
+		{"no targets healthy", nil, []pkg.TargetHealth{{State: "unhealthy"}, {State: TargetStateUnregistered}}, HealthUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := steady
+			if tt.service != nil {
+				service = tt.service(service)
+			}
+			assert.Equal(t, tt.want, ServiceHealth(service, tt.targets))
+		})
+	}
+}
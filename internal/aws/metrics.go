@@ -0,0 +1,416 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// DefaultMetricsNamespace is where ECS publishes the basic CPUUtilization
+	// and MemoryUtilization metrics
+	DefaultMetricsNamespace = "AWS/ECS"
+	insightsNamespace       = "ECS/ContainerInsights"
+
+	// metricsDatapoints is how many datapoints a derived period yields over
+	// the window; enough to judge sustained utilization
+	metricsDatapoints = 10
+
+	// SustainedUtilizationThreshold is the utilization percentage a service
+	// must exceed for most of the metrics window to count as sustained
+	SustainedUtilizationThreshold = 80.0
+)
+
+// Values accepted by MetricsSource
+const (
+	MetricsSourceAuto              = "auto"
+	MetricsSourceBasic             = "basic"
+	MetricsSourceContainerInsights = "container-insights"
+)
+
+// MetricsSource chooses where utilization is read from. Auto uses Container
+// Insights in clusters that DetectContainerInsights found it enabled in, and
+// basic AWS/ECS metrics elsewhere.
+var MetricsSource = MetricsSourceBasic
+
+// MetricsNamespace is the CloudWatch namespace basic utilization metrics are
+// read from. Setups that republish CPUUtilization and MemoryUtilization with
+// ClusterName and ServiceName dimensions under their own namespace can point
+// it there.
+var MetricsNamespace = DefaultMetricsNamespace
+
+// insightsClusters remembers, by cluster name, whether Container Insights is
+// enabled in a cluster, so each cluster is only probed once
+var insightsClusters sync.Map
+
+// maxDescribeClustersBatchSize is the most clusters DescribeClusters accepts
+const maxDescribeClustersBatchSize = 100
+
+// DetectContainerInsights reads the containerInsights setting of the clusters
+// not probed yet, so that in auto mode their utilization is read from
+// Container Insights where it is enabled. It does nothing unless MetricsSource
+// is auto.
+func DetectContainerInsights(ctx context.Context, ecsClient ECSClientAPI, clusters []string) error {
+	if MetricsSource != MetricsSourceAuto {
+		return nil
+	}
+	// A routed client sends each call to one region, so clusters are
+	// described a region at a time
+	byRegion := make(map[string][]string)
+	for _, cluster := range clusters {
+		if _, known := insightsClusters.Load(ClusterName(cluster)); !known {
+			region := resourceRegion(cluster)
+			byRegion[region] = append(byRegion[region], cluster)
+		}
+	}
+
+	for _, pending := range byRegion {
+		for start := 0; start < len(pending); start += maxDescribeClustersBatchSize {
+			end := min(start+maxDescribeClustersBatchSize, len(pending))
+			output, err := ecsClient.DescribeClusters(ctx, &ecs.DescribeClustersInput{
+				Clusters: pending[start:end],
+				Include:  []ecstypes.ClusterField{ecstypes.ClusterFieldSettings},
+			})
+			if err != nil {
+				return fmt.Errorf("error describing clusters: %v", err)
+			}
+			for _, cluster := range output.Clusters {
+				insightsClusters.Store(ClusterName(aws.ToString(cluster.ClusterName)), containerInsightsEnabled(cluster.Settings))
+			}
+		}
+	}
+	return nil
+}
+
+// containerInsightsEnabled reports whether a cluster's settings turn on
+// Container Insights, either the standard or the enhanced observability
+func containerInsightsEnabled(settings []ecstypes.ClusterSetting) bool {
+	for _, setting := range settings {
+		if setting.Name == ecstypes.ClusterSettingNameContainerInsights {
+			value := aws.ToString(setting.Value)
+			return value != "" && value != "disabled"
+		}
+	}
+	return false
+}
+
+// ValidateMetricsSource checks that source is one of the MetricsSource values
+func ValidateMetricsSource(source string) error {
+	switch source {
+	case MetricsSourceAuto, MetricsSourceBasic, MetricsSourceContainerInsights:
+		return nil
+	}
+	return fmt.Errorf("unknown metrics source %q: must be %s, %s or %s", source, MetricsSourceAuto, MetricsSourceBasic, MetricsSourceContainerInsights)
+}
+
+// Statistic is how datapoints are aggregated over each period
+type Statistic = cwtypes.Statistic
+
+// Statistics utilization can be read as. The average shows typical load,
+// while the maximum shows spikes that the average smooths over.
+const (
+	StatisticAverage = cwtypes.StatisticAverage
+	StatisticMaximum = cwtypes.StatisticMaximum
+)
+
+// MetricsWindow is how far back metrics are fetched
+var MetricsWindow = 10 * time.Minute
+
+// MetricsPeriod is the CloudWatch aggregation period. Zero derives it from
+// MetricsWindow with MetricsPeriodFor.
+var MetricsPeriod time.Duration
+
+// MetricsCallTimeout bounds each CloudWatch call, so a degraded CloudWatch
+// can't hold up metrics loading indefinitely. Zero disables the timeout.
+var MetricsCallTimeout = 10 * time.Second
+
+// ErrMetricsUnavailable is returned when CloudWatch doesn't answer within
+// MetricsCallTimeout
+var ErrMetricsUnavailable = errors.New("metrics unavailable")
+
+// ErrMetricsAccessDenied is returned for services in clusters without
+// metrics: clusters CloudWatch denied access to, and clusters marked with
+// SetNoMetricsClusters
+var ErrMetricsAccessDenied = errors.New("no CloudWatch access")
+
+// noMetricsClusters holds, by cluster name, the clusters whose metrics aren't
+// fetched. Clusters are added the first time CloudWatch denies access to
+// their metrics, so a partly scoped role isn't asked again on every refresh.
+var noMetricsClusters sync.Map
+
+// SetNoMetricsClusters marks clusters, by name, as having no metrics, e.g.
+// because CloudWatch access is scoped to other clusters
+func SetNoMetricsClusters(clusters []string) {
+	for _, cluster := range clusters {
+		noMetricsClusters.Store(ClusterName(cluster), true)
+	}
+}
+
+// HasNoMetrics reports whether a cluster's metrics aren't fetched
+func HasNoMetrics(cluster string) bool {
+	_, ok := noMetricsClusters.Load(ClusterName(cluster))
+	return ok
+}
+
+// isAccessDenied reports whether an AWS API call failed for lack of
+// permission
+func isAccessDenied(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "AccessDenied", "AccessDeniedException":
+		return true
+	}
+	return false
+}
+
+// CloudWatchClientAPI defines the interface for CloudWatch client operations
+type CloudWatchClientAPI interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
+}
+
+// GetServiceMetrics fetches the latest CPU and memory utilization of a
+// service, and whether each stayed above SustainedUtilizationThreshold for
+// most of the metrics window, aggregated with statistic. The metrics come
+// from Container Insights or AWS/ECS according to MetricsSource. Services in
+// clusters without metrics fail with ErrMetricsAccessDenied.
+func GetServiceMetrics(ctx context.Context, cwClient CloudWatchClientAPI, cluster, serviceName string, statistic Statistic) (pkg.ServiceMetrics, error) {
+	if HasNoMetrics(cluster) {
+		return pkg.ServiceMetrics{}, fmt.Errorf("%w in cluster %s", ErrMetricsAccessDenied, ClusterName(cluster))
+	}
+	if useInsights(cluster) {
+		metrics, found, err := getInsightsMetrics(ctx, cwClient, cluster, serviceName, statistic)
+		if err != nil || found || MetricsSource == MetricsSourceContainerInsights {
+			return metrics, err
+		}
+	}
+	return getBasicMetrics(ctx, cwClient, cluster, serviceName, statistic)
+}
+
+// useInsights reports whether Container Insights should be tried for a
+// cluster: always when selected, and in auto mode when the cluster has it
+// enabled
+func useInsights(cluster string) bool {
+	switch MetricsSource {
+	case MetricsSourceBasic:
+		return false
+	case MetricsSourceContainerInsights:
+		return true
+	}
+	enabled, _ := insightsClusters.Load(ClusterName(cluster))
+	return enabled == true
+}
+
+func getBasicMetrics(ctx context.Context, cwClient CloudWatchClientAPI, cluster, serviceName string, statistic Statistic) (pkg.ServiceMetrics, error) {
+	cpu, err := getMetric(ctx, cwClient, MetricsNamespace, "CPUUtilization", cluster, serviceName, statistic)
+	if err != nil {
+		return pkg.ServiceMetrics{}, err
+	}
+
+	memory, err := getMetric(ctx, cwClient, MetricsNamespace, "MemoryUtilization", cluster, serviceName, statistic)
+	if err != nil {
+		return pkg.ServiceMetrics{}, err
+	}
+
+	return utilizationMetrics(cpu, memory, MetricsNamespace), nil
+}
+
+// getInsightsMetrics computes utilization from the Container Insights
+// utilized and reserved CPU and memory of a service. found is false when the
+// service has no Container Insights datapoints, e.g. because it has no
+// running tasks. With the maximum
+// statistic, peak usage is divided by the average reservation, which only
+// changes when tasks are added or removed.
+func getInsightsMetrics(ctx context.Context, cwClient CloudWatchClientAPI, cluster, serviceName string, statistic Statistic) (metrics pkg.ServiceMetrics, found bool, err error) {
+	cpuUsed, err := getMetric(ctx, cwClient, insightsNamespace, "CpuUtilized", cluster, serviceName, statistic)
+	if err != nil {
+		return pkg.ServiceMetrics{}, false, err
+	}
+	if len(cpuUsed) == 0 {
+		return pkg.ServiceMetrics{}, false, nil
+	}
+
+	var datapoints [3][]cwtypes.Datapoint
+	for i, metricName := range []string{"CpuReserved", "MemoryUtilized", "MemoryReserved"} {
+		metricStatistic := StatisticAverage
+		if metricName == "MemoryUtilized" {
+			metricStatistic = statistic
+		}
+		if datapoints[i], err = getMetric(ctx, cwClient, insightsNamespace, metricName, cluster, serviceName, metricStatistic); err != nil {
+			return pkg.ServiceMetrics{}, false, err
+		}
+	}
+
+	cpu := utilizationDatapoints(cpuUsed, datapoints[0])
+	memory := utilizationDatapoints(datapoints[1], datapoints[2])
+	return utilizationMetrics(cpu, memory, insightsNamespace), true, nil
+}
+
+// utilizationDatapoints turns utilized and reserved datapoints into
+// utilization percentages, pairing them by timestamp
+func utilizationDatapoints(utilized, reserved []cwtypes.Datapoint) []cwtypes.Datapoint {
+	reservedAt := make(map[time.Time]float64, len(reserved))
+	for _, datapoint := range reserved {
+		if datapoint.Timestamp != nil {
+			reservedAt[*datapoint.Timestamp] = aws.ToFloat64(datapoint.Average)
+		}
+	}
+
+	var utilization []cwtypes.Datapoint
+	for _, datapoint := range utilized {
+		if datapoint.Timestamp == nil || datapoint.Average == nil {
+			continue
+		}
+		if total := reservedAt[*datapoint.Timestamp]; total > 0 {
+			utilization = append(utilization, cwtypes.Datapoint{
+				Timestamp: datapoint.Timestamp,
+				Average:   aws.Float64(*datapoint.Average / total * 100),
+			})
+		}
+	}
+	return utilization
+}
+
+func utilizationMetrics(cpu, memory []cwtypes.Datapoint, namespace string) pkg.ServiceMetrics {
+	return pkg.ServiceMetrics{
+		CPUUtilization:      latestAverage(cpu),
+		MemoryUtilization:   latestAverage(memory),
+		SustainedHighCPU:    sustainedAbove(cpu, SustainedUtilizationThreshold),
+		SustainedHighMemory: sustainedAbove(memory, SustainedUtilizationThreshold),
+		Source:              namespace,
+	}
+}
+
+// LoadServiceMetrics fetches metrics for every service, at most concurrency
+// at a time, and stores them on the services in place. Services whose
+// metrics cannot be fetched are left without metrics.
+func LoadServiceMetrics(ctx context.Context, cwClient CloudWatchClientAPI, services []pkg.ServiceDetails, concurrency int) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i := range services {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(service *pkg.ServiceDetails) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			metrics, err := GetServiceMetrics(ctx, cwClient, service.Cluster, service.ServiceName, StatisticAverage)
+			if err != nil {
+				return
+			}
+			service.SetMetrics(metrics)
+		}(&services[i])
+	}
+
+	wg.Wait()
+}
+
+// ValidateMetricsPeriod checks that a period is a positive multiple of 60
+// seconds, as CloudWatch requires for ECS metrics
+func ValidateMetricsPeriod(period time.Duration) error {
+	if period <= 0 || period%time.Minute != 0 {
+		return fmt.Errorf("metrics period %v must be a positive multiple of 60 seconds", period)
+	}
+	return nil
+}
+
+// MetricsPeriodFor returns a period that splits window into about ten
+// datapoints, rounded up to whole minutes
+func MetricsPeriodFor(window time.Duration) time.Duration {
+	period := window / metricsDatapoints
+	if remainder := period % time.Minute; remainder != 0 {
+		period += time.Minute - remainder
+	}
+	if period < time.Minute {
+		period = time.Minute
+	}
+	return period
+}
+
+// getMetric returns a statistic of a service metric over MetricsWindow.
+// Whichever statistic is requested, it is returned in the datapoints'
+// Average, so the datapoints are read the same way either way.
+func getMetric(ctx context.Context, cwClient CloudWatchClientAPI, namespace, metricName, cluster, serviceName string, statistic Statistic) ([]cwtypes.Datapoint, error) {
+	period := MetricsPeriod
+	if period == 0 {
+		period = MetricsPeriodFor(MetricsWindow)
+	}
+
+	endTime := time.Now()
+	input := &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metricName),
+		Dimensions: []cwtypes.Dimension{
+			{Name: aws.String("ClusterName"), Value: aws.String(ClusterName(cluster))},
+			{Name: aws.String("ServiceName"), Value: aws.String(serviceName)},
+		},
+		StartTime:  aws.Time(endTime.Add(-MetricsWindow)),
+		EndTime:    aws.Time(endTime),
+		Period:     aws.Int32(int32(period.Seconds())),
+		Statistics: []cwtypes.Statistic{statistic},
+	}
+
+	callCtx := ctx
+	if MetricsCallTimeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, MetricsCallTimeout)
+		defer cancel()
+	}
+
+	output, err := cwClient.GetMetricStatistics(callCtx, input)
+	if err != nil {
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w: %s for service %s timed out after %v", ErrMetricsUnavailable, metricName, serviceName, MetricsCallTimeout)
+		}
+		if isAccessDenied(err) {
+			noMetricsClusters.Store(ClusterName(cluster), true)
+			return nil, fmt.Errorf("%w in cluster %s: %v", ErrMetricsAccessDenied, ClusterName(cluster), err)
+		}
+		return nil, fmt.Errorf("error getting %s for service %s: %v", metricName, serviceName, err)
+	}
+	if statistic == StatisticMaximum {
+		for i := range output.Datapoints {
+			output.Datapoints[i].Average = output.Datapoints[i].Maximum
+		}
+	}
+	return output.Datapoints, nil
+}
+
+// latestAverage returns the average of the most recent datapoint, or nil if
+// there are none
+func latestAverage(datapoints []cwtypes.Datapoint) *float64 {
+	var latest *cwtypes.Datapoint
+	for i, datapoint := range datapoints {
+		if latest == nil || datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = &datapoints[i]
+		}
+	}
+	if latest == nil || latest.Average == nil {
+		return nil
+	}
+	return aws.Float64(*latest.Average)
+}
+
+// sustainedAbove reports whether more than half of the datapoints average
+// above threshold, so a single spike doesn't count
+func sustainedAbove(datapoints []cwtypes.Datapoint, threshold float64) bool {
+	above := 0
+	for _, datapoint := range datapoints {
+		if aws.ToFloat64(datapoint.Average) > threshold {
+			above++
+		}
+	}
+	return above*2 > len(datapoints)
+}
@@ -0,0 +1,356 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCloudWatchClient is a mock of the CloudWatch client
+type MockCloudWatchClient struct {
+	mock.Mock
+}
+
+func (m *MockCloudWatchClient) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	return args.Get(0).(*cloudwatch.GetMetricStatisticsOutput), args.Error(1)
+}
+
+func metricNamed(name string) interface{} {
+	return mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.MetricName == name
+	})
+}
+
+// useMetricsSource selects a metrics source for the duration of a test, and
+// forgets which clusters were found to publish Container Insights
+func useMetricsSource(t *testing.T, source string) {
+	defaultSource := MetricsSource
+	MetricsSource = source
+	forget := func() {
+		insightsClusters.Range(func(key, value any) bool {
+			insightsClusters.Delete(key)
+			return true
+		})
+	}
+	forget()
+	t.Cleanup(func() {
+		MetricsSource = defaultSource
+		forget()
+	})
+}
+
+func TestGetServiceMetrics(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.MetricName == "CPUUtilization" &&
+			*input.Namespace == "AWS/ECS" &&
+			*input.Dimensions[0].Value == "prod" &&
+			*input.Dimensions[1].Value == "api"
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []cwtypes.Datapoint{
+			{Timestamp: aws.Time(now.Add(-5 * time.Minute)), Average: aws.Float64(10)},
+			{Timestamp: aws.Time(now), Average: aws.Float64(42.5)},
+		},
+	}, nil)
+	mockClient.On("GetMetricStatistics", mock.Anything, metricNamed("MemoryUtilization"), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil)
+
+	metrics, err := GetServiceMetrics(ctx, mockClient, "arn:aws:ecs:us-east-1:123456789012:cluster/prod", "api", StatisticAverage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Float64(42.5), metrics.CPUUtilization)
+	// No datapoints is reported as missing, not as 0%
+	assert.Nil(t, metrics.MemoryUtilization)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceMetricsAccessDenied(t *testing.T) {
+	t.Cleanup(func() {
+		noMetricsClusters.Range(func(key, value any) bool {
+			noMetricsClusters.Delete(key)
+			return true
+		})
+	})
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	mockClient.On("GetMetricStatistics", mock.Anything, metricNamed("CPUUtilization"), mock.Anything).
+		Return((*cloudwatch.GetMetricStatisticsOutput)(nil), &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"}).Once()
+
+	_, err := GetServiceMetrics(ctx, mockClient, "arn:aws:ecs:us-east-1:123456789012:cluster/restricted", "api", StatisticAverage)
+	assert.ErrorIs(t, err, ErrMetricsAccessDenied)
+	assert.True(t, HasNoMetrics("restricted"))
+
+	// The cluster isn't asked about again
+	_, err = GetServiceMetrics(ctx, mockClient, "restricted", "worker", StatisticAverage)
+	assert.ErrorIs(t, err, ErrMetricsAccessDenied)
+	mockClient.AssertNumberOfCalls(t, "GetMetricStatistics", 1)
+
+	SetNoMetricsClusters([]string{"arn:aws:ecs:us-east-1:123456789012:cluster/other"})
+	assert.True(t, HasNoMetrics("other"))
+	assert.False(t, HasNoMetrics("prod"))
+}
+
+func TestGetServiceMetricsMaximum(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return len(input.Statistics) == 1 && input.Statistics[0] == cwtypes.StatisticMaximum
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []cwtypes.Datapoint{
+			{Timestamp: aws.Time(now.Add(-time.Minute)), Maximum: aws.Float64(97)},
+			{Timestamp: aws.Time(now), Maximum: aws.Float64(64)},
+		},
+	}, nil)
+
+	metrics, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticMaximum)
+
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Float64(64), metrics.CPUUtilization)
+	assert.Equal(t, aws.Float64(64), metrics.MemoryUtilization)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLoadServiceMetrics(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.Dimensions[1].Value == "broken"
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, errors.New("throttled"))
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []cwtypes.Datapoint{{Timestamp: aws.Time(time.Now()), Average: aws.Float64(5)}},
+	}, nil)
+
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod"},
+		{ServiceName: "broken", Cluster: "prod"},
+	}
+	LoadServiceMetrics(ctx, mockClient, services, 2)
+
+	assert.Equal(t, &pkg.ServiceMetrics{CPUUtilization: aws.Float64(5), MemoryUtilization: aws.Float64(5), Source: "AWS/ECS"}, services[0].Metrics)
+	assert.Nil(t, services[1].Metrics)
+}
+
+func TestGetServiceMetricsSustainedUtilization(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	datapoints := func(averages ...float64) *cloudwatch.GetMetricStatisticsOutput {
+		output := &cloudwatch.GetMetricStatisticsOutput{}
+		for i, average := range averages {
+			output.Datapoints = append(output.Datapoints, cwtypes.Datapoint{
+				Timestamp: aws.Time(now.Add(time.Duration(i) * time.Minute)),
+				Average:   aws.Float64(average),
+			})
+		}
+		return output
+	}
+	// CPU spiked once; memory stayed high for most of the window
+	mockClient.On("GetMetricStatistics", mock.Anything, metricNamed("CPUUtilization"), mock.Anything).Return(datapoints(20, 95, 30, 25), nil)
+	mockClient.On("GetMetricStatistics", mock.Anything, metricNamed("MemoryUtilization"), mock.Anything).Return(datapoints(85, 90, 70, 92), nil)
+
+	metrics, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Float64(25), metrics.CPUUtilization)
+	assert.False(t, metrics.SustainedHighCPU)
+	assert.True(t, metrics.SustainedHighMemory)
+
+	var service pkg.ServiceDetails
+	service.SetMetrics(metrics)
+	assert.False(t, service.SustainedHighCPU)
+	assert.True(t, service.SustainedHighMemory)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceMetricsTimeout(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	defaultTimeout := MetricsCallTimeout
+	MetricsCallTimeout = 10 * time.Millisecond
+	defer func() { MetricsCallTimeout = defaultTimeout }()
+
+	// Simulate a CloudWatch call that only returns once its context expires
+	mockClient.On("GetMetricStatistics", mock.Anything, metricNamed("CPUUtilization"), mock.Anything).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(&cloudwatch.GetMetricStatisticsOutput{}, context.DeadlineExceeded)
+
+	_, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+
+	assert.ErrorIs(t, err, ErrMetricsUnavailable)
+	mockClient.AssertExpectations(t)
+}
+
+func insightsMetric(name string) interface{} {
+	return mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.Namespace == "ECS/ContainerInsights" && *input.MetricName == name
+	})
+}
+
+func TestGetServiceMetricsContainerInsights(t *testing.T) {
+	useMetricsSource(t, MetricsSourceAuto)
+	insightsClusters.Store("prod", true)
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	datapoint := func(at time.Time, average float64) *cloudwatch.GetMetricStatisticsOutput {
+		return &cloudwatch.GetMetricStatisticsOutput{Datapoints: []cwtypes.Datapoint{{Timestamp: aws.Time(at), Average: aws.Float64(average)}}}
+	}
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("CpuUtilized"), mock.Anything).Return(datapoint(now, 128), nil)
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("CpuReserved"), mock.Anything).Return(datapoint(now, 512), nil)
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("MemoryUtilized"), mock.Anything).Return(datapoint(now, 900), nil)
+	// A reservation without a matching timestamp can't yield a utilization
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("MemoryReserved"), mock.Anything).Return(datapoint(now.Add(-time.Minute), 1024), nil)
+
+	metrics, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Float64(25), metrics.CPUUtilization)
+	assert.Nil(t, metrics.MemoryUtilization)
+	assert.Equal(t, "ECS/ContainerInsights", metrics.Source)
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetServiceMetricsCustomNamespace(t *testing.T) {
+	MetricsNamespace = "Custom/ECS"
+	t.Cleanup(func() { MetricsNamespace = DefaultMetricsNamespace })
+	mockClient := new(MockCloudWatchClient)
+
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.Namespace == "Custom/ECS"
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []cwtypes.Datapoint{{Timestamp: aws.Time(time.Now()), Average: aws.Float64(12)}},
+	}, nil).Twice()
+
+	metrics, err := GetServiceMetrics(context.Background(), mockClient, "prod", "api", StatisticAverage)
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Float64(12), metrics.CPUUtilization)
+	assert.Equal(t, "Custom/ECS", metrics.Source)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDetectContainerInsights(t *testing.T) {
+	useMetricsSource(t, MetricsSourceAuto)
+	ecsClient := new(MockECSClient)
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	// Each cluster is only probed once
+	ecsClient.On("DescribeClusters", ctx, &ecs.DescribeClustersInput{
+		Clusters: []string{"prod", "dev"},
+		Include:  []ecstypes.ClusterField{ecstypes.ClusterFieldSettings},
+	}, mock.Anything).Return(&ecs.DescribeClustersOutput{Clusters: []ecstypes.Cluster{
+		{ClusterName: aws.String("prod"), Settings: []ecstypes.ClusterSetting{{Name: ecstypes.ClusterSettingNameContainerInsights, Value: aws.String("enhanced")}}},
+		{ClusterName: aws.String("dev"), Settings: []ecstypes.ClusterSetting{{Name: ecstypes.ClusterSettingNameContainerInsights, Value: aws.String("disabled")}}},
+	}}, nil).Once()
+	assert.NoError(t, DetectContainerInsights(ctx, ecsClient, []string{"prod", "dev"}))
+	assert.NoError(t, DetectContainerInsights(ctx, ecsClient, []string{"prod", "dev"}))
+	ecsClient.AssertExpectations(t)
+
+	// A service without Container Insights datapoints, e.g. scaled to zero,
+	// falls back to AWS/ECS without changing how the rest of its cluster is read
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("CpuUtilized"), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil).Twice()
+	mockClient.On("GetMetricStatistics", mock.Anything, mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.Namespace == "AWS/ECS"
+	}), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{
+		Datapoints: []cwtypes.Datapoint{{Timestamp: aws.Time(time.Now()), Average: aws.Float64(5)}},
+	}, nil).Times(6)
+
+	for _, cluster := range []string{"prod", "prod", "dev"} {
+		metrics, err := GetServiceMetrics(ctx, mockClient, cluster, "api", StatisticAverage)
+		assert.NoError(t, err)
+		assert.Equal(t, aws.Float64(5), metrics.CPUUtilization)
+		assert.Equal(t, "AWS/ECS", metrics.Source)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestDetectContainerInsightsOnlyInAutoMode(t *testing.T) {
+	// No DescribeClusters calls are expected
+	ecsClient := new(MockECSClient)
+	assert.NoError(t, DetectContainerInsights(context.Background(), ecsClient, []string{"prod"}))
+	assert.False(t, useInsights("prod"))
+}
+
+func TestGetServiceMetricsContainerInsightsOnly(t *testing.T) {
+	useMetricsSource(t, MetricsSourceContainerInsights)
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	mockClient.On("GetMetricStatistics", mock.Anything, insightsMetric("CpuUtilized"), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil)
+
+	metrics, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+
+	assert.NoError(t, err)
+	assert.Nil(t, metrics.CPUUtilization)
+	mockClient.AssertExpectations(t)
+}
+
+func TestValidateMetricsSource(t *testing.T) {
+	assert.NoError(t, ValidateMetricsSource(MetricsSourceAuto))
+	assert.NoError(t, ValidateMetricsSource(MetricsSourceContainerInsights))
+	assert.Error(t, ValidateMetricsSource("insights"))
+}
+
+func TestMetricsPeriodFor(t *testing.T) {
+	assert.Equal(t, time.Minute, MetricsPeriodFor(10*time.Minute))
+	assert.Equal(t, time.Minute, MetricsPeriodFor(time.Minute))
+	assert.Equal(t, 6*time.Minute, MetricsPeriodFor(time.Hour))
+	assert.Equal(t, 144*time.Minute, MetricsPeriodFor(24*time.Hour))
+	assert.Equal(t, 2*time.Minute, MetricsPeriodFor(15*time.Minute))
+}
+
+func TestValidateMetricsPeriod(t *testing.T) {
+	assert.NoError(t, ValidateMetricsPeriod(time.Minute))
+	assert.NoError(t, ValidateMetricsPeriod(5*time.Minute))
+	assert.Error(t, ValidateMetricsPeriod(90*time.Second))
+	assert.Error(t, ValidateMetricsPeriod(0))
+}
+
+func TestGetServiceMetricsPeriod(t *testing.T) {
+	mockClient := new(MockCloudWatchClient)
+	ctx := context.Background()
+
+	defaultWindow, defaultPeriod := MetricsWindow, MetricsPeriod
+	defer func() { MetricsWindow, MetricsPeriod = defaultWindow, defaultPeriod }()
+
+	requestedPeriod := func(seconds int32) interface{} {
+		return mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+			return *input.Period == seconds && input.EndTime.Sub(*input.StartTime) == MetricsWindow
+		})
+	}
+
+	// Derived from the window
+	MetricsWindow = 24 * time.Hour
+	mockClient.On("GetMetricStatistics", mock.Anything, requestedPeriod(8640), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil).Twice()
+	_, err := GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+	assert.NoError(t, err)
+
+	// Set explicitly
+	MetricsPeriod = 5 * time.Minute
+	mockClient.On("GetMetricStatistics", mock.Anything, requestedPeriod(300), mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil).Twice()
+	_, err = GetServiceMetrics(ctx, mockClient, "prod", "api", StatisticAverage)
+	assert.NoError(t, err)
+
+	mockClient.AssertExpectations(t)
+}
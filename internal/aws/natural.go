@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Natural Ordering
+// ----------------
+//
+// Cluster and service names are ordered the way people read them: ignoring
+// case, and comparing runs of digits by their numeric value, so "service2"
+// comes before "service10" and "API" sits next to "api".
+
+// NaturalLess reports whether a sorts before b in natural order. Names that
+// only differ in case or leading zeros are ordered byte-wise, so the order is
+// total and stable across runs.
+func NaturalLess(a, b string) bool {
+	if c := naturalCompare(a, b); c != 0 {
+		return c < 0
+	}
+	return a < b
+}
+
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := digitRun(a)
+			numB, restB := digitRun(b)
+			if c := compareNumbers(numA, numB); c != 0 {
+				return c
+			}
+			a, b = restA, restB
+			continue
+		}
+
+		runeA, sizeA := utf8.DecodeRuneInString(a)
+		runeB, sizeB := utf8.DecodeRuneInString(b)
+		if lowerA, lowerB := unicode.ToLower(runeA), unicode.ToLower(runeB); lowerA != lowerB {
+			if lowerA < lowerB {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// digitRun splits s into its leading run of digits, without leading zeros,
+// and the rest
+func digitRun(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	start := 0
+	for start < end-1 && s[start] == '0' {
+		start++
+	}
+	return s[start:end], s[end:]
+}
+
+// compareNumbers compares two digit strings without leading zeros by value,
+// however many digits they have
+func compareNumbers(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaturalLess(t *testing.T) {
+	names := []string{"service10", "Service1", "service2", "api", "API", "worker-02", "worker-1", "service", "svc-99999999999999999999", "svc-100000000000000000000"}
+	sort.Slice(names, func(i, j int) bool { return NaturalLess(names[i], names[j]) })
+
+	assert.Equal(t, []string{"API", "api", "service", "Service1", "service2", "service10", "svc-99999999999999999999", "svc-100000000000000000000", "worker-1", "worker-02"}, names)
+}
+
+func TestNaturalLessIsTotal(t *testing.T) {
+	// Names equal apart from case or leading zeros still have a fixed order
+	assert.True(t, NaturalLess("Api", "api"))
+	assert.False(t, NaturalLess("api", "Api"))
+	assert.True(t, NaturalLess("web01", "web1"))
+	assert.False(t, NaturalLess("web1", "web01"))
+	assert.False(t, NaturalLess("web1", "web1"))
+}
+
+func TestSortServicesNaturally(t *testing.T) {
+	services := []pkg.ServiceDetails{
+		{Cluster: "prod10", ServiceName: "api"},
+		{Cluster: "prod2", ServiceName: "worker10"},
+		{Cluster: "Prod2", ServiceName: "api"},
+		{Cluster: "prod2", ServiceName: "Worker2"},
+	}
+	SortServices(services)
+
+	assert.Equal(t, []pkg.ServiceDetails{
+		{Cluster: "Prod2", ServiceName: "api"},
+		{Cluster: "prod2", ServiceName: "Worker2"},
+		{Cluster: "prod2", ServiceName: "worker10"},
+		{Cluster: "prod10", ServiceName: "api"},
+	}, services)
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// Paged Service Loading
+// ---------------------
+//
+// Enumerating every service of a large account blocks startup. A
+// ServicePager instead loads one page of services at a time: a page lists up
+// to the page size of one cluster's services and describes them, moving on to
+// the next cluster once one is exhausted.
+
+// MaxPageSize is the most services ListServices returns per call
+const MaxPageSize = 100
+
+// ServicePager loads the services of a set of clusters a page at a time. It
+// is not safe for concurrent use; callers load one page at a time.
+type ServicePager struct {
+	ecsClient ECSClientAPI
+	pageSize  int32
+	clusters  []string
+	cluster   int     // Index of the cluster being listed
+	nextToken *string // Where listing of the current cluster resumes
+}
+
+// NewServicePager returns a pager loading pageSize services at a time, from
+// 1 to MaxPageSize. Call Reset to choose the clusters to load.
+func NewServicePager(ecsClient ECSClientAPI, pageSize int) (*ServicePager, error) {
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", MaxPageSize)
+	}
+	return &ServicePager{ecsClient: ecsClient, pageSize: int32(pageSize)}, nil
+}
+
+// Reset starts loading the services of clusters from the beginning
+func (p *ServicePager) Reset(clusters []string) {
+	p.clusters = clusters
+	p.cluster = 0
+	p.nextToken = nil
+}
+
+// HasMore reports whether there may be services left to load
+func (p *ServicePager) HasMore() bool {
+	return p.cluster < len(p.clusters)
+}
+
+// NextPage loads the next page of services. Clusters with no services left
+// are skipped, so a page is only empty once every cluster is exhausted. If a
+// cluster can't be listed, it is skipped and reported as a ClusterErrors
+// alongside any services that did load.
+func (p *ServicePager) NextPage(ctx context.Context) ([]pkg.ServiceDetails, error) {
+	for p.HasMore() {
+		cluster := p.clusters[p.cluster]
+		output, err := p.ecsClient.ListServices(ctx, &ecs.ListServicesInput{
+			Cluster:    &cluster,
+			MaxResults: &p.pageSize,
+			NextToken:  p.nextToken,
+		})
+		if err != nil {
+			p.nextCluster()
+			return nil, ClusterErrors{cluster: err}
+		}
+
+		p.nextToken = output.NextToken
+		if p.nextToken == nil {
+			p.nextCluster()
+		}
+		if len(output.ServiceArns) == 0 {
+			continue
+		}
+
+		services, err := describeServiceArns(ctx, p.ecsClient, cluster, output.ServiceArns)
+		if err != nil {
+			return services, ClusterErrors{cluster: err}
+		}
+		return services, nil
+	}
+	return nil, nil
+}
+
+func (p *ServicePager) nextCluster() {
+	p.cluster++
+	p.nextToken = nil
+}
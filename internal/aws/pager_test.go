@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func describedAs(names ...string) *ecs.DescribeServicesOutput {
+	output := &ecs.DescribeServicesOutput{}
+	for _, name := range names {
+		output.Services = append(output.Services, types.Service{ServiceName: aws.String(name), Status: aws.String("ACTIVE")})
+	}
+	return output
+}
+
+func TestServicePagerLoadsPageByPage(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1"), MaxResults: aws.Int32(2)}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service1", "service2"},
+		NextToken:   aws.String("page2"),
+	}, nil)
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1"), MaxResults: aws.Int32(2), NextToken: aws.String("page2")}, mock.Anything).Return(&ecs.ListServicesOutput{}, nil)
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster2"), MaxResults: aws.Int32(2)}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service3"},
+	}, nil)
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{Cluster: aws.String("cluster1"), Services: []string{"service1", "service2"}}, mock.Anything).Return(describedAs("service1", "service2"), nil)
+	mockClient.On("DescribeServices", ctx, &ecs.DescribeServicesInput{Cluster: aws.String("cluster2"), Services: []string{"service3"}}, mock.Anything).Return(describedAs("service3"), nil)
+
+	pager, err := NewServicePager(mockClient, 2)
+	assert.NoError(t, err)
+	pager.Reset([]string{"cluster1", "cluster2"})
+
+	first, err := pager.NextPage(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, first, 2)
+	assert.True(t, pager.HasMore())
+
+	// The empty last page of cluster1 is skipped
+	second, err := pager.NextPage(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, second, 1)
+	assert.Equal(t, "cluster2", second[0].Cluster)
+	assert.False(t, pager.HasMore())
+
+	rest, err := pager.NextPage(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, rest)
+	mockClient.AssertExpectations(t)
+}
+
+func TestServicePagerSkipsFailedClusters(t *testing.T) {
+	mockClient := new(MockECSClient)
+	ctx := context.Background()
+
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster1"), MaxResults: aws.Int32(10)}, mock.Anything).Return(&ecs.ListServicesOutput{}, errors.New("access denied"))
+	mockClient.On("ListServices", ctx, &ecs.ListServicesInput{Cluster: aws.String("cluster2"), MaxResults: aws.Int32(10)}, mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"service3"},
+	}, nil)
+	mockClient.On("DescribeServices", ctx, mock.AnythingOfType("*ecs.DescribeServicesInput"), mock.Anything).Return(describedAs("service3"), nil)
+
+	pager, err := NewServicePager(mockClient, 10)
+	assert.NoError(t, err)
+	pager.Reset([]string{"cluster1", "cluster2"})
+
+	_, err = pager.NextPage(ctx)
+	var clusterErrs ClusterErrors
+	assert.True(t, errors.As(err, &clusterErrs))
+	assert.Contains(t, clusterErrs, "cluster1")
+
+	services, err := pager.NextPage(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, services, 1)
+}
+
+func TestNewServicePagerValidatesPageSize(t *testing.T) {
+	_, err := NewServicePager(nil, 0)
+	assert.Error(t, err)
+	_, err = NewServicePager(nil, MaxPageSize+1)
+	assert.Error(t, err)
+}
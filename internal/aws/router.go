@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// Regional Routing
+// ----------------
+//
+// An ECS client only reaches the region it was configured for, while a list
+// merged from several regions holds services whose cluster ARNs name other
+// regions. RouteECSCalls wraps the default client so that calls about a
+// cluster, and in particular the UpdateService calls behind scaling and
+// restarts, are sent to a client for the region in the cluster's ARN.
+// Clusters given by name, and calls that name no cluster, use the default
+// client.
+
+type routingECSClient struct {
+	defaultClient ECSClientAPI
+	defaultRegion string
+	newClient     func(region string) ECSClientAPI
+
+	mu      sync.Mutex
+	clients map[string]ECSClientAPI
+}
+
+// RouteECSCalls wraps defaultClient, which is configured for defaultRegion,
+// so calls about clusters in other regions go to a client for that region.
+// newClient creates those clients the first time a region is seen.
+func RouteECSCalls(defaultClient ECSClientAPI, defaultRegion string, newClient func(region string) ECSClientAPI) ECSClientAPI {
+	return &routingECSClient{
+		defaultClient: defaultClient,
+		defaultRegion: defaultRegion,
+		newClient:     newClient,
+		clients:       make(map[string]ECSClientAPI),
+	}
+}
+
+// ServiceRegion returns the region of the client a service came from, read
+// from its cluster ARN, or "" if the cluster is known only by name
+func ServiceRegion(service pkg.ServiceDetails) string {
+	return resourceRegion(service.Cluster)
+}
+
+func resourceRegion(resource string) string {
+	parsed, err := ParseARN(resource)
+	if err != nil {
+		return ""
+	}
+	return parsed.Region
+}
+
+// clientFor returns the client for the region of resource, an ARN or name
+func (c *routingECSClient) clientFor(resource *string) ECSClientAPI {
+	region := resourceRegion(aws.ToString(resource))
+	if region == "" || region == c.defaultRegion {
+		return c.defaultClient
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client, ok := c.clients[region]
+	if !ok {
+		client = c.newClient(region)
+		c.clients[region] = client
+	}
+	return client
+}
+
+func (c *routingECSClient) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	return c.defaultClient.ListClusters(ctx, params, optFns...)
+}
+
+// DescribeClusters is routed by the first cluster, so callers describe the
+// clusters of one region at a time
+func (c *routingECSClient) DescribeClusters(ctx context.Context, params *ecs.DescribeClustersInput, optFns ...func(*ecs.Options)) (*ecs.DescribeClustersOutput, error) {
+	var cluster *string
+	if len(params.Clusters) > 0 {
+		cluster = &params.Clusters[0]
+	}
+	return c.clientFor(cluster).DescribeClusters(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	return c.clientFor(params.Cluster).ListServices(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	return c.clientFor(params.Cluster).DescribeServices(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error) {
+	return c.clientFor(params.Cluster).UpdateService(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	return c.clientFor(params.Cluster).DescribeTasks(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	return c.clientFor(params.Cluster).ListTasks(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) ListTaskDefinitions(ctx context.Context, params *ecs.ListTaskDefinitionsInput, optFns ...func(*ecs.Options)) (*ecs.ListTaskDefinitionsOutput, error) {
+	return c.defaultClient.ListTaskDefinitions(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) DescribeTaskDefinition(ctx context.Context, params *ecs.DescribeTaskDefinitionInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTaskDefinitionOutput, error) {
+	return c.clientFor(params.TaskDefinition).DescribeTaskDefinition(ctx, params, optFns...)
+}
+
+func (c *routingECSClient) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	return c.clientFor(params.Cluster).DescribeContainerInstances(ctx, params, optFns...)
+}
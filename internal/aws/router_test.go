@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRouteECSCalls(t *testing.T) {
+	ctx := context.Background()
+	usCluster := "arn:aws:ecs:us-east-1:123456789012:cluster/prod"
+	euCluster := "arn:aws:ecs:eu-west-1:123456789012:cluster/prod"
+
+	usClient := new(MockECSClient)
+	euClient := new(MockECSClient)
+	created := map[string]int{}
+	client := RouteECSCalls(usClient, "us-east-1", func(region string) ECSClientAPI {
+		created[region]++
+		assert.Equal(t, "eu-west-1", region)
+		return euClient
+	})
+
+	forCluster := func(cluster string) interface{} {
+		return mock.MatchedBy(func(input *ecs.UpdateServiceInput) bool { return *input.Cluster == cluster })
+	}
+	usClient.On("UpdateService", ctx, forCluster(usCluster), mock.Anything).Return(&ecs.UpdateServiceOutput{}, nil)
+	usClient.On("UpdateService", ctx, forCluster("dev"), mock.Anything).Return(&ecs.UpdateServiceOutput{}, nil)
+	euClient.On("UpdateService", ctx, forCluster(euCluster), mock.Anything).Return(&ecs.UpdateServiceOutput{}, nil)
+
+	// A merged list holds services of both regions
+	us := pkg.ServiceDetails{ServiceName: "api", Cluster: usCluster, DesiredCount: 2}
+	eu := pkg.ServiceDetails{ServiceName: "api", Cluster: euCluster, DesiredCount: 2}
+	assert.Equal(t, "us-east-1", ServiceRegion(us))
+	assert.Equal(t, "eu-west-1", ServiceRegion(eu))
+
+	assert.NoError(t, RestartService(ctx, client, eu.ServiceName, eu.Cluster))
+	assert.NoError(t, UpdateServiceDesiredCount(ctx, client, us.ServiceName, us.Cluster, 3))
+	results := UpdateDesiredCounts(ctx, client, []DesiredCountUpdate{{Service: us, DesiredCount: 0}, {Service: eu, DesiredCount: 0}})
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+	// Clusters known only by name stay with the default client
+	assert.NoError(t, RestartService(ctx, client, "api", "dev"))
+
+	usClient.AssertNumberOfCalls(t, "UpdateService", 3)
+	euClient.AssertNumberOfCalls(t, "UpdateService", 2)
+	assert.Equal(t, map[string]int{"eu-west-1": 1}, created, "a region's client is created once")
+}
@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// NewHTTPClient returns the HTTP client AWS clients are built with. Requests
+// go through the proxy in HTTPS_PROXY (or HTTP_PROXY) unless the host is
+// listed in NO_PROXY. When caBundle holds PEM certificates they are trusted
+// on top of the system roots, so hosts reached around the proxy still verify.
+func NewHTTPClient(caBundle []byte) (*awshttp.BuildableClient, error) {
+	var roots *x509.CertPool
+	if caBundle != nil {
+		var err error
+		if roots, err = x509.SystemCertPool(); err != nil {
+			return nil, fmt.Errorf("error loading system certificates: %v", err)
+		}
+		if !roots.AppendCertsFromPEM(caBundle) {
+			return nil, errors.New("no PEM certificates found")
+		}
+	}
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(t *http.Transport) {
+		t.Proxy = http.ProxyFromEnvironment
+		if roots != nil {
+			if t.TLSClientConfig == nil {
+				t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+			t.TLSClientConfig.RootCAs = roots
+		}
+	}), nil
+}
@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTLSServer serves over TLS with a self-signed certificate for 127.0.0.1,
+// returned as PEM
+func newTLSServer(t *testing.T, name string) (*httptest.Server, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestNewHTTPClientKeepsSystemRoots(t *testing.T) {
+	// The system roots are read once per process, so they are set up before
+	// anything loads them
+	systemHost, systemCert := newTLSServer(t, "system")
+	proxyHost, proxyCert := newTLSServer(t, "proxy")
+	systemFile := filepath.Join(t.TempDir(), "system.pem")
+	if err := os.WriteFile(systemFile, systemCert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SSL_CERT_FILE", systemFile)
+	t.Setenv("SSL_CERT_DIR", t.TempDir())
+
+	client, err := NewHTTPClient(proxyCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, server := range []*httptest.Server{systemHost, proxyHost} {
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := client.Do(request)
+		if assert.NoError(t, err, "a host trusted by the system or the bundle verifies") {
+			response.Body.Close()
+		}
+	}
+
+	// Without a bundle, only the system roots are trusted
+	client, err = NewHTTPClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := http.NewRequest(http.MethodGet, proxyHost.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(request)
+	assert.Error(t, err)
+
+	_, err = NewHTTPClient([]byte("not a certificate"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Copy places text on the system clipboard using the platform's clipboard
+// tool: pbcopy on macOS, clip on Windows, and wl-copy, xclip or xsel
+// elsewhere, whichever is installed first
+func Copy(text string) error {
+	for _, args := range commands() {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("unable to copy to clipboard with %s: %v", args[0], err)
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found")
+}
+
+func commands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	var commands [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	return append(commands, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+}
@@ -0,0 +1,104 @@
+// Package clusterfilter decides which clusters to load from include and
+// exclude patterns given on the command line.
+package clusterfilter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPrefix marks a pattern as a regular expression rather than a glob
+const regexPrefix = "re:"
+
+// Filter matches cluster names against include and exclude patterns. A
+// cluster is kept if it matches any include pattern, or there are none, and
+// matches no exclude pattern: exclude wins when both match.
+type Filter struct {
+	include []matcher
+	exclude []matcher
+}
+
+type matcher func(name string) bool
+
+// New compiles the patterns. Patterns are globs such as "*-prod", or regular
+// expressions when prefixed with "re:", such as "re:^(api|web)-". Matching is
+// case-insensitive, and regular expressions match anywhere in the name unless
+// anchored.
+func New(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	for _, pattern := range include {
+		m, err := compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %v", pattern, err)
+		}
+		f.include = append(f.include, m)
+	}
+	for _, pattern := range exclude {
+		m, err := compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+		}
+		f.exclude = append(f.exclude, m)
+	}
+	return f, nil
+}
+
+func compile(pattern string) (matcher, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+
+	glob := strings.ToLower(pattern)
+	// Match only fails on a malformed pattern, so check it once up front
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, err
+	}
+	return func(name string) bool {
+		matched, _ := path.Match(glob, strings.ToLower(name))
+		return matched
+	}, nil
+}
+
+// Active reports whether the filter has any patterns
+func (f *Filter) Active() bool {
+	return f != nil && (len(f.include) > 0 || len(f.exclude) > 0)
+}
+
+// Match reports whether a cluster name passes the filter
+func (f *Filter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+	for _, m := range f.exclude {
+		if m(name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, m := range f.include {
+		if m(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the clusters that pass the filter. nameOf extracts the name
+// to match from each cluster, such as the name at the end of a cluster ARN.
+func (f *Filter) Apply(clusters []string, nameOf func(string) string) []string {
+	var kept []string
+	for _, cluster := range clusters {
+		if f.Match(nameOf(cluster)) {
+			kept = append(kept, cluster)
+		}
+	}
+	return kept
+}
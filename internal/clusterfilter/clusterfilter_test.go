@@ -0,0 +1,91 @@
+package clusterfilter
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		matches []string
+		rejects []string
+	}{
+		{
+			name:    "no patterns",
+			matches: []string{"api-prod", "sandbox"},
+		},
+		{
+			name:    "include glob",
+			include: []string{"*-prod"},
+			matches: []string{"api-prod", "WEB-PROD"},
+			rejects: []string{"api-staging", "prod-api"},
+		},
+		{
+			name:    "exclude only",
+			exclude: []string{"sandbox*"},
+			matches: []string{"api-prod"},
+			rejects: []string{"sandbox", "sandbox-2"},
+		},
+		{
+			name:    "exclude wins",
+			include: []string{"*-prod"},
+			exclude: []string{"legacy-*"},
+			matches: []string{"api-prod"},
+			rejects: []string{"legacy-prod"},
+		},
+		{
+			name:    "regex",
+			include: []string{"re:^(api|web)-", "batch"},
+			matches: []string{"api-prod", "Web-staging", "batch"},
+			rejects: []string{"worker-api-prod"},
+		},
+		{
+			name:    "unanchored regex",
+			exclude: []string{"re:test"},
+			matches: []string{"api-prod"},
+			rejects: []string{"loadtest-prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude)
+			assert.NoError(t, err)
+			for _, name := range tt.matches {
+				assert.True(t, f.Match(name), name)
+			}
+			for _, name := range tt.rejects {
+				assert.False(t, f.Match(name), name)
+			}
+		})
+	}
+}
+
+func TestNewInvalidPatterns(t *testing.T) {
+	_, err := New([]string{"re:("}, nil)
+	assert.ErrorContains(t, err, `invalid include pattern "re:("`)
+
+	_, err = New(nil, []string{"[a-"})
+	assert.ErrorContains(t, err, `invalid exclude pattern "[a-"`)
+}
+
+func TestApply(t *testing.T) {
+	f, err := New([]string{"*-prod"}, nil)
+	assert.NoError(t, err)
+	assert.True(t, f.Active())
+
+	clusters := []string{
+		"arn:aws:ecs:eu-west-1:123456789012:cluster/api-prod",
+		"arn:aws:ecs:eu-west-1:123456789012:cluster/api-dev",
+	}
+	assert.Equal(t, clusters[:1], f.Apply(clusters, path.Base))
+
+	var none *Filter
+	assert.False(t, none.Active())
+	assert.Equal(t, clusters, none.Apply(clusters, path.Base))
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Config holds user settings read from the config file. Unlike the state
+// file, it is only ever written by the user.
+type Config struct {
+	// BulkConfirmThreshold is the number of services above which bulk
+	// operations require typing the service count or "yes" to proceed
+	BulkConfirmThreshold int `json:"bulkConfirmThreshold"`
+	// ProductionAccounts and ProductionProfiles mark AWS account IDs and
+	// profile names as production. The UI then shows a red banner and every
+	// change has to be confirmed by typing "yes" first.
+	ProductionAccounts []string `json:"productionAccounts"`
+	ProductionProfiles []string `json:"productionProfiles"`
+	// MetricsNamespace overrides the CloudWatch namespace basic utilization
+	// metrics are read from, unless --metrics-namespace is given
+	MetricsNamespace string `json:"metricsNamespace"`
+	// NoMetricsClusters are cluster names whose services show n/a instead
+	// of CloudWatch metrics, e.g. because CloudWatch access is scoped to
+	// other clusters
+	NoMetricsClusters []string `json:"noMetricsClusters"`
+	// FargateRates are the Fargate prices cost estimates use, by region.
+	// Regions listed here replace the built-in rates.
+	FargateRates map[string]FargateRate `json:"fargateRates"`
+
+	// unreadable is set when the config file exists but couldn't be used, so
+	// which accounts and profiles are production is unknown
+	unreadable bool
+}
+
+// FargateRate is the hourly on-demand price of one vCPU and one GB of memory
+type FargateRate struct {
+	VCPUHour float64 `json:"vcpuHour"`
+	GBHour   float64 `json:"gbHour"`
+}
+
+// defaultFargateRates are Linux/x86 on-demand Fargate prices in USD, which
+// only change every few years. They are meant for rough estimates.
+var defaultFargateRates = map[string]FargateRate{
+	"us-east-1":      {VCPUHour: 0.04048, GBHour: 0.004445},
+	"us-east-2":      {VCPUHour: 0.04048, GBHour: 0.004445},
+	"us-west-1":      {VCPUHour: 0.04656, GBHour: 0.00511},
+	"us-west-2":      {VCPUHour: 0.04048, GBHour: 0.004445},
+	"ca-central-1":   {VCPUHour: 0.04456, GBHour: 0.00489},
+	"eu-west-1":      {VCPUHour: 0.04048, GBHour: 0.004445},
+	"eu-west-2":      {VCPUHour: 0.04656, GBHour: 0.00511},
+	"eu-central-1":   {VCPUHour: 0.04656, GBHour: 0.00511},
+	"ap-south-1":     {VCPUHour: 0.04256, GBHour: 0.00467},
+	"ap-southeast-1": {VCPUHour: 0.05056, GBHour: 0.00553},
+	"ap-southeast-2": {VCPUHour: 0.04856, GBHour: 0.00532},
+	"ap-northeast-1": {VCPUHour: 0.05056, GBHour: 0.00553},
+	"sa-east-1":      {VCPUHour: 0.0696, GBHour: 0.0076},
+}
+
+// FargateRate returns the Fargate prices of a region, preferring the config
+// file's rates over the built-in ones. It reports false for regions with
+// neither.
+func (c *Config) FargateRate(region string) (FargateRate, bool) {
+	if rate, ok := c.FargateRates[region]; ok {
+		return rate, true
+	}
+	rate, ok := defaultFargateRates[region]
+	return rate, ok
+}
+
+// Default returns the configuration used for settings the file doesn't set
+func Default() *Config {
+	return &Config{
+		BulkConfirmThreshold: 10,
+	}
+}
+
+// unreadableConfig returns the defaults for a config file that exists but
+// couldn't be used. Every session then counts as production, since the
+// production accounts and profiles it lists are unknown.
+func unreadableConfig() *Config {
+	cfg := Default()
+	cfg.unreadable = true
+	return cfg
+}
+
+// DefaultPath returns the location of the config file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine config directory: %v", err)
+	}
+	return filepath.Join(dir, "bw-cli", "config.json"), nil
+}
+
+// Load reads the config file at path. A missing file yields the defaults, and
+// settings missing from the file keep their default values. A file that can't
+// be read or is invalid yields the defaults too, but marks every session as
+// production.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return unreadableConfig(), fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return unreadableConfig(), fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	if cfg.BulkConfirmThreshold < 0 {
+		return unreadableConfig(), fmt.Errorf("invalid config file %s: bulkConfirmThreshold must not be negative", path)
+	}
+	for region, rate := range cfg.FargateRates {
+		if rate.VCPUHour < 0 || rate.GBHour < 0 {
+			return unreadableConfig(), fmt.Errorf("invalid config file %s: fargateRates for %s must not be negative", path, region)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadDefault loads the config file from DefaultPath
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return Default(), err
+	}
+	return Load(path)
+}
+
+// RequiresTypedConfirmation reports whether an operation affecting count
+// services must be confirmed by typing rather than with a button
+func (c *Config) RequiresTypedConfirmation(count int) bool {
+	return count > c.BulkConfirmThreshold
+}
+
+// IsProductionAccount reports whether accountID is marked as production
+func (c *Config) IsProductionAccount(accountID string) bool {
+	for _, account := range c.ProductionAccounts {
+		if strings.TrimSpace(account) == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProductionProfile reports whether the AWS profile is marked as production
+func (c *Config) IsProductionProfile(profile string) bool {
+	for _, production := range c.ProductionProfiles {
+		if strings.TrimSpace(production) == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductionLabel describes what marks a session using profile on the given
+// accounts as production, e.g. "account 123456789012, profile prod", or is
+// empty when nothing does. With an unreadable config file every session is
+// production.
+func (c *Config) ProductionLabel(accountIDs []string, profile string) string {
+	var reasons []string
+	if c.unreadable {
+		reasons = append(reasons, "config file unreadable")
+	}
+	for _, accountID := range accountIDs {
+		if c.IsProductionAccount(accountID) {
+			reasons = append(reasons, "account "+accountID)
+		}
+	}
+	if profile != "" && c.IsProductionProfile(profile) {
+		reasons = append(reasons, "profile "+profile)
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// IsTypedConfirmation reports whether a typed answer confirms an operation
+// on count services: either the count itself or "yes"
+func IsTypedConfirmation(answer string, count int) bool {
+	answer = strings.TrimSpace(answer)
+	return strings.EqualFold(answer, "yes") || answer == strconv.Itoa(count)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoadOverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bulkConfirmThreshold": 3}`), 0o644))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cfg.BulkConfirmThreshold)
+	assert.False(t, cfg.RequiresTypedConfirmation(3))
+	assert.True(t, cfg.RequiresTypedConfirmation(4))
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bulkConfirmThreshold": "ten"}`), 0o644))
+
+	cfg, err := Load(path)
+
+	assert.Error(t, err)
+	assert.Equal(t, 10, cfg.BulkConfirmThreshold)
+	// Without the production accounts and profiles, everything is production
+	assert.Equal(t, "config file unreadable", cfg.ProductionLabel([]string{"210987654321"}, "staging"))
+}
+
+func TestLoadNegativeThreshold(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"bulkConfirmThreshold": -1}`), 0o644))
+
+	_, err := Load(path)
+
+	assert.Error(t, err)
+}
+
+func TestIsTypedConfirmation(t *testing.T) {
+	assert.True(t, IsTypedConfirmation("12", 12))
+	assert.True(t, IsTypedConfirmation(" YES \n", 12))
+	assert.False(t, IsTypedConfirmation("11", 12))
+	assert.False(t, IsTypedConfirmation("y", 12))
+}
+
+func TestProductionAccountsAndProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"productionAccounts": ["123456789012"], "productionProfiles": ["prod", "prod-admin"]}`), 0o644))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, cfg.BulkConfirmThreshold)
+	assert.True(t, cfg.IsProductionAccount("123456789012"))
+	assert.False(t, cfg.IsProductionAccount("210987654321"))
+	assert.True(t, cfg.IsProductionProfile("prod-admin"))
+	assert.False(t, cfg.IsProductionProfile("staging"))
+	assert.False(t, Default().IsProductionProfile("default"))
+
+	assert.Equal(t, "account 123456789012, profile prod-admin", cfg.ProductionLabel([]string{"123456789012", "210987654321"}, "prod-admin"))
+	assert.Equal(t, "", cfg.ProductionLabel([]string{"210987654321"}, "staging"))
+}
+
+func TestFargateRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"fargateRates": {"eu-west-1": {"vcpuHour": 0.03, "gbHour": 0.003}, "me-south-1": {"vcpuHour": 0.05, "gbHour": 0.005}}}`), 0o644))
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	rate, ok := cfg.FargateRate("eu-west-1")
+	assert.True(t, ok)
+	assert.Equal(t, FargateRate{VCPUHour: 0.03, GBHour: 0.003}, rate)
+	_, ok = cfg.FargateRate("me-south-1")
+	assert.True(t, ok)
+	// Built-in rates cover regions the file leaves out
+	_, ok = cfg.FargateRate("us-east-1")
+	assert.True(t, ok)
+	_, ok = cfg.FargateRate("xx-nowhere-1")
+	assert.False(t, ok)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"fargateRates": {"eu-west-1": {"vcpuHour": -1}}}`), 0o644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,230 @@
+// Package doctor checks that the AWS setup bw-cli relies on works, turning
+// opaque AWS errors into a checklist with remediation hints.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+// Outcomes of a check
+const (
+	StatusPass = "PASS"
+	StatusFail = "FAIL"
+	StatusSkip = "SKIP"
+)
+
+// checkTimeout bounds each check, so an unreachable endpoint fails the check
+// instead of hanging the command
+const checkTimeout = 10 * time.Second
+
+// placeholderService is used to exercise DescribeServices and CloudWatch when
+// the account has no service to check against
+const placeholderService = "bw-cli-doctor"
+
+// Result is the outcome of a single check
+type Result struct {
+	Name   string
+	Status string
+	// Detail describes what was found, or why the check failed or was skipped
+	Detail string
+	// Hint suggests how to fix a failed check
+	Hint string
+}
+
+// Checker runs the checks against an AWS configuration and its clients
+type Checker struct {
+	Config     awssdk.Config
+	ECS        aws.ECSClientAPI
+	CloudWatch aws.CloudWatchClientAPI
+}
+
+// ConfigLoadFailure reports an AWS configuration that could not be loaded,
+// in which case none of the other checks can run
+func ConfigLoadFailure(err error) Result {
+	return Result{
+		Name:   "AWS configuration loads",
+		Status: StatusFail,
+		Detail: err.Error(),
+		Hint:   "Check ~/.aws/config and ~/.aws/credentials for syntax errors, and that AWS_PROFILE names an existing profile.",
+	}
+}
+
+// Run performs every check in order. Checks that call AWS are skipped once
+// credentials or the region are known to be missing, since they would only
+// repeat that failure.
+func (c *Checker) Run(ctx context.Context) []Result {
+	results := []Result{c.checkCredentials(ctx), c.checkRegion()}
+	if results[0].Status == StatusFail || results[1].Status == StatusFail {
+		reason := "requires working credentials and a region"
+		return append(results,
+			skipped("ECS clusters can be listed (ecs:ListClusters)", reason),
+			skipped("ECS services can be described (ecs:ListServices, ecs:DescribeServices)", reason),
+			skipped("CloudWatch metrics can be read (cloudwatch:GetMetricStatistics)", reason),
+		)
+	}
+
+	clusters, clustersResult := c.checkListClusters(ctx)
+	results = append(results, clustersResult)
+	if clustersResult.Status == StatusFail {
+		return append(results,
+			skipped("ECS services can be described (ecs:ListServices, ecs:DescribeServices)", "requires ecs:ListClusters"),
+			c.checkMetrics(ctx, placeholderService, placeholderService),
+		)
+	}
+
+	cluster, service, servicesResult := c.checkDescribeServices(ctx, clusters)
+	return append(results, servicesResult, c.checkMetrics(ctx, cluster, service))
+}
+
+func (c *Checker) checkCredentials(ctx context.Context) Result {
+	result := Result{Name: "AWS credentials resolve"}
+	if c.Config.Credentials == nil {
+		return failed(result, errors.New("no credentials provider configured"), credentialsHint)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	creds, err := c.Config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return failed(result, err, credentialsHint)
+	}
+	result.Status = StatusPass
+	result.Detail = fmt.Sprintf("source: %s", creds.Source)
+	if creds.CanExpire {
+		result.Detail += fmt.Sprintf(", expires %s", creds.Expires.Local().Format(time.RFC1123))
+	}
+	return result
+}
+
+const credentialsHint = "Run `aws configure`, set AWS_PROFILE to a configured profile, or run `aws sso login` if your profile uses SSO."
+
+func (c *Checker) checkRegion() Result {
+	result := Result{Name: "AWS region is set"}
+	if c.Config.Region == "" {
+		return failed(result, errors.New("no region configured"), "Set AWS_REGION, or add a region to your profile in ~/.aws/config.")
+	}
+	result.Status = StatusPass
+	result.Detail = c.Config.Region
+	return result
+}
+
+func (c *Checker) checkListClusters(ctx context.Context) ([]string, Result) {
+	result := Result{Name: "ECS clusters can be listed (ecs:ListClusters)"}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	output, err := c.ECS.ListClusters(ctx, &ecs.ListClustersInput{})
+	if err != nil {
+		return nil, failed(result, err, "Grant ecs:ListClusters to your IAM user or role, and check the region has ECS clusters.")
+	}
+	result.Status = StatusPass
+	result.Detail = fmt.Sprintf("%d cluster(s) found", len(output.ClusterArns))
+	return output.ClusterArns, result
+}
+
+// checkDescribeServices describes a service of the first cluster that has
+// one, returning the cluster and service it used
+func (c *Checker) checkDescribeServices(ctx context.Context, clusters []string) (string, string, Result) {
+	result := Result{Name: "ECS services can be described (ecs:ListServices, ecs:DescribeServices)"}
+	if len(clusters) == 0 {
+		result.Status = StatusSkip
+		result.Detail = "no clusters to check against"
+		return placeholderService, placeholderService, result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	for _, cluster := range clusters {
+		listOutput, err := c.ECS.ListServices(ctx, &ecs.ListServicesInput{Cluster: awssdk.String(cluster), MaxResults: awssdk.Int32(1)})
+		if err != nil {
+			return cluster, placeholderService, failed(result, err, "Grant ecs:ListServices to your IAM user or role.")
+		}
+		if len(listOutput.ServiceArns) == 0 {
+			continue
+		}
+
+		_, err = c.ECS.DescribeServices(ctx, &ecs.DescribeServicesInput{Cluster: awssdk.String(cluster), Services: listOutput.ServiceArns})
+		service := serviceName(listOutput.ServiceArns[0])
+		if err != nil {
+			return cluster, service, failed(result, err, "Grant ecs:DescribeServices to your IAM user or role.")
+		}
+		result.Status = StatusPass
+		result.Detail = fmt.Sprintf("described %s in %s", service, aws.ClusterName(cluster))
+		return cluster, service, result
+	}
+
+	result.Status = StatusSkip
+	result.Detail = "no services to check against"
+	return clusters[0], placeholderService, result
+}
+
+func (c *Checker) checkMetrics(ctx context.Context, cluster, service string) Result {
+	result := Result{Name: "CloudWatch metrics can be read (cloudwatch:GetMetricStatistics)"}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	endTime := time.Now()
+	_, err := c.CloudWatch.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  awssdk.String(aws.MetricsNamespace),
+		MetricName: awssdk.String("CPUUtilization"),
+		Dimensions: []cwtypes.Dimension{
+			{Name: awssdk.String("ClusterName"), Value: awssdk.String(aws.ClusterName(cluster))},
+			{Name: awssdk.String("ServiceName"), Value: awssdk.String(service)},
+		},
+		StartTime:  awssdk.Time(endTime.Add(-5 * time.Minute)),
+		EndTime:    awssdk.Time(endTime),
+		Period:     awssdk.Int32(60),
+		Statistics: []cwtypes.Statistic{cwtypes.StatisticAverage},
+	})
+	if err != nil {
+		return failed(result, err, "Grant cloudwatch:GetMetricStatistics to your IAM user or role. Without it, utilization is shown as n/a.")
+	}
+	result.Status = StatusPass
+	return result
+}
+
+// serviceName returns the service name from a service ARN
+func serviceName(serviceArn string) string {
+	return serviceArn[strings.LastIndex(serviceArn, "/")+1:]
+}
+
+func failed(result Result, err error, hint string) Result {
+	result.Status = StatusFail
+	result.Detail = err.Error()
+	result.Hint = hint
+	return result
+}
+
+func skipped(name, reason string) Result {
+	return Result{Name: name, Status: StatusSkip, Detail: reason}
+}
+
+// Write prints results as a checklist and returns the number of failed checks
+func Write(w io.Writer, results []Result) int {
+	failures := 0
+	for _, result := range results {
+		line := fmt.Sprintf("[%s] %s", result.Status, result.Name)
+		if result.Detail != "" {
+			line += ": " + result.Detail
+		}
+		fmt.Fprintln(w, line)
+		if result.Hint != "" {
+			fmt.Fprintf(w, "       Hint: %s\n", result.Hint)
+		}
+		if result.Status == StatusFail {
+			failures++
+		}
+	}
+	return failures
+}
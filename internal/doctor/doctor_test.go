@@ -0,0 +1,145 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockECSClient mocks the ECS calls made by the checks
+type mockECSClient struct {
+	aws.ECSClientAPI
+	mock.Mock
+}
+
+func (m *mockECSClient) ListClusters(ctx context.Context, params *ecs.ListClustersInput, optFns ...func(*ecs.Options)) (*ecs.ListClustersOutput, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ecs.ListClustersOutput), args.Error(1)
+}
+
+func (m *mockECSClient) ListServices(ctx context.Context, params *ecs.ListServicesInput, optFns ...func(*ecs.Options)) (*ecs.ListServicesOutput, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ecs.ListServicesOutput), args.Error(1)
+}
+
+func (m *mockECSClient) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	args := m.Called(params)
+	return args.Get(0).(*ecs.DescribeServicesOutput), args.Error(1)
+}
+
+// mockCloudWatchClient mocks the CloudWatch call made by the checks
+type mockCloudWatchClient struct {
+	mock.Mock
+}
+
+func (m *mockCloudWatchClient) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	args := m.Called(params)
+	return args.Get(0).(*cloudwatch.GetMetricStatisticsOutput), args.Error(1)
+}
+
+func validConfig() awssdk.Config {
+	return awssdk.Config{
+		Region:      "eu-west-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+}
+
+func statuses(results []Result) []string {
+	var out []string
+	for _, result := range results {
+		out = append(out, result.Status)
+	}
+	return out
+}
+
+func TestRunAllPass(t *testing.T) {
+	ecsClient := new(mockECSClient)
+	ecsClient.On("ListClusters", mock.Anything).Return(&ecs.ListClustersOutput{
+		ClusterArns: []string{"arn:aws:ecs:eu-west-1:123456789012:cluster/empty", "arn:aws:ecs:eu-west-1:123456789012:cluster/prod"},
+	}, nil)
+	ecsClient.On("ListServices", mock.MatchedBy(func(input *ecs.ListServicesInput) bool {
+		return *input.Cluster == "arn:aws:ecs:eu-west-1:123456789012:cluster/empty"
+	})).Return(&ecs.ListServicesOutput{}, nil)
+	ecsClient.On("ListServices", mock.Anything).Return(&ecs.ListServicesOutput{
+		ServiceArns: []string{"arn:aws:ecs:eu-west-1:123456789012:service/prod/api"},
+	}, nil)
+	ecsClient.On("DescribeServices", mock.Anything).Return(&ecs.DescribeServicesOutput{}, nil)
+	cwClient := new(mockCloudWatchClient)
+	cwClient.On("GetMetricStatistics", mock.MatchedBy(func(input *cloudwatch.GetMetricStatisticsInput) bool {
+		return *input.Dimensions[0].Value == "prod" && *input.Dimensions[1].Value == "api"
+	})).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil)
+
+	checker := &Checker{Config: validConfig(), ECS: ecsClient, CloudWatch: cwClient}
+	results := checker.Run(context.Background())
+
+	assert.Equal(t, []string{StatusPass, StatusPass, StatusPass, StatusPass, StatusPass}, statuses(results))
+	assert.Equal(t, "eu-west-1", results[1].Detail)
+	assert.Equal(t, "described api in prod", results[3].Detail)
+	cwClient.AssertExpectations(t)
+}
+
+func TestRunMissingRegionSkipsAWSChecks(t *testing.T) {
+	cfg := validConfig()
+	cfg.Region = ""
+	checker := &Checker{Config: cfg, ECS: new(mockECSClient), CloudWatch: new(mockCloudWatchClient)}
+
+	results := checker.Run(context.Background())
+
+	assert.Equal(t, []string{StatusPass, StatusFail, StatusSkip, StatusSkip, StatusSkip}, statuses(results))
+	assert.NotEmpty(t, results[1].Hint)
+}
+
+func TestRunMissingCredentials(t *testing.T) {
+	cfg := validConfig()
+	cfg.Credentials = credentials.NewStaticCredentialsProvider("", "", "")
+	checker := &Checker{Config: cfg, ECS: new(mockECSClient), CloudWatch: new(mockCloudWatchClient)}
+
+	results := checker.Run(context.Background())
+
+	assert.Equal(t, []string{StatusFail, StatusPass, StatusSkip, StatusSkip, StatusSkip}, statuses(results))
+}
+
+func TestRunListClustersDenied(t *testing.T) {
+	ecsClient := new(mockECSClient)
+	ecsClient.On("ListClusters", mock.Anything).Return(&ecs.ListClustersOutput{}, errors.New("AccessDeniedException"))
+	cwClient := new(mockCloudWatchClient)
+	cwClient.On("GetMetricStatistics", mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, nil)
+
+	checker := &Checker{Config: validConfig(), ECS: ecsClient, CloudWatch: cwClient}
+	results := checker.Run(context.Background())
+
+	assert.Equal(t, []string{StatusPass, StatusPass, StatusFail, StatusSkip, StatusPass}, statuses(results))
+	assert.Contains(t, results[2].Hint, "ecs:ListClusters")
+}
+
+func TestRunNoClusters(t *testing.T) {
+	ecsClient := new(mockECSClient)
+	ecsClient.On("ListClusters", mock.Anything).Return(&ecs.ListClustersOutput{}, nil)
+	cwClient := new(mockCloudWatchClient)
+	cwClient.On("GetMetricStatistics", mock.Anything).Return(&cloudwatch.GetMetricStatisticsOutput{}, errors.New("AccessDenied"))
+
+	checker := &Checker{Config: validConfig(), ECS: ecsClient, CloudWatch: cwClient}
+	results := checker.Run(context.Background())
+
+	assert.Equal(t, []string{StatusPass, StatusPass, StatusPass, StatusSkip, StatusFail}, statuses(results))
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	failures := Write(&buf, []Result{
+		{Name: "AWS region is set", Status: StatusPass, Detail: "eu-west-1"},
+		{Name: "AWS credentials resolve", Status: StatusFail, Detail: "no credentials", Hint: "Run aws configure."},
+	})
+
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, "[PASS] AWS region is set: eu-west-1\n[FAIL] AWS credentials resolve: no credentials\n       Hint: Run aws configure.\n", buf.String())
+}
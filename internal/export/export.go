@@ -0,0 +1,113 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Supported export formats
+const (
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatTable = "table"
+)
+
+var csvHeader = []string{"cluster", "service", "running", "desired", "status", "cpu", "mem"}
+
+// Write encodes services to w in the given format
+func Write(w io.Writer, format string, services []pkg.ServiceDetails) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, services)
+	case FormatJSON:
+		return WriteJSON(w, services)
+	case FormatTable:
+		return WriteTable(w, services)
+	default:
+		return fmt.Errorf("unsupported export format %q (expected %s, %s or %s)", format, FormatCSV, FormatJSON, FormatTable)
+	}
+}
+
+// WriteCSV writes one row per service, preceded by a header row
+func WriteCSV(w io.Writer, services []pkg.ServiceDetails) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("error writing CSV header: %v", err)
+	}
+
+	for _, service := range services {
+		row := []string{
+			aws.ClusterName(service.Cluster),
+			service.ServiceName,
+			strconv.FormatInt(service.RunningCount, 10),
+			strconv.FormatInt(service.DesiredCount, 10),
+			service.Status,
+			"",
+			"",
+		}
+		// Leave utilization blank for services without metrics rather than reporting 0
+		if service.Metrics != nil {
+			row[5] = formatUtilization(service.Metrics.CPUUtilization)
+			row[6] = formatUtilization(service.Metrics.MemoryUtilization)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV row for service %s: %v", service.ServiceName, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatUtilization formats a utilization for CSV, leaving it blank when
+// CloudWatch had no datapoints
+func formatUtilization(utilization *float64) string {
+	if utilization == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*utilization, 'f', 2, 64)
+}
+
+// WriteTable writes the services as a plain-text table with aligned columns,
+// for pasting into chat or tickets. Missing utilization is shown as n/a.
+func WriteTable(w io.Writer, services []pkg.ServiceDetails) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CLUSTER\tSERVICE\tRUNNING\tDESIRED\tSTATUS\tCPU\tMEM")
+	for _, service := range services {
+		cpu, mem := "n/a", "n/a"
+		if service.Metrics != nil {
+			cpu = tablePercent(service.Metrics.CPUUtilization)
+			mem = tablePercent(service.Metrics.MemoryUtilization)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			aws.ClusterName(service.Cluster), service.ServiceName, service.RunningCount, service.DesiredCount, service.Status, cpu, mem)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing table: %v", err)
+	}
+	return nil
+}
+
+func tablePercent(utilization *float64) string {
+	if utilization == nil {
+		return "n/a"
+	}
+	return formatUtilization(utilization) + "%"
+}
+
+// WriteJSON writes the services as an indented JSON array
+func WriteJSON(w io.Writer, services []pkg.ServiceDetails) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(services); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+var testServices = []pkg.ServiceDetails{
+	{ServiceName: "api", Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", RunningCount: 2, DesiredCount: 3, Status: "ACTIVE",
+		Metrics: &pkg.ServiceMetrics{CPUUtilization: aws.Float64(12.5), MemoryUtilization: aws.Float64(40)}},
+	{ServiceName: `legacy, "v1"`, Cluster: "dev", RunningCount: 0, DesiredCount: 0, Status: "DRAINING"},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatCSV, testServices)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster,service,running,desired,status,cpu,mem\n"+
+		"prod,api,2,3,ACTIVE,12.50,40.00\n"+
+		"dev,\"legacy, \"\"v1\"\"\",0,0,DRAINING,,\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatJSON, testServices)
+	assert.NoError(t, err)
+
+	var decoded []pkg.ServiceDetails
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, testServices, decoded)
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, FormatTable, testServices)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CLUSTER  SERVICE       RUNNING  DESIRED  STATUS    CPU     MEM\n"+
+		"prod     api           2        3        ACTIVE    12.50%  40.00%\n"+
+		"dev      legacy, \"v1\"  0        0        DRAINING  n/a     n/a\n", buf.String())
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Write(&buf, "xml", testServices)
+
+	assert.Error(t, err)
+}
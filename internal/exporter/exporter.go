@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Exporter serves the most recently polled services as Prometheus gauges.
+// It never calls AWS itself; the UI hands it each refresh via Update.
+type Exporter struct {
+	mu       sync.RWMutex
+	services []pkg.ServiceDetails
+}
+
+func New() *Exporter {
+	return &Exporter{}
+}
+
+// Update replaces the services reported on the next scrape
+func (e *Exporter) Update(services []pkg.ServiceDetails) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.services = services
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/metrics" {
+		http.NotFound(w, r)
+		return
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeGauge(w, "bwcli_service_running_count", "Number of running tasks for the service.", e.services,
+		func(service pkg.ServiceDetails) float64 { return float64(service.RunningCount) })
+	writeGauge(w, "bwcli_service_desired_count", "Desired number of tasks for the service.", e.services,
+		func(service pkg.ServiceDetails) float64 { return float64(service.DesiredCount) })
+
+	// Metrics are loaded lazily by the UI, so only services that have them are reported
+	cpu := func(metrics *pkg.ServiceMetrics) *float64 { return metrics.CPUUtilization }
+	memory := func(metrics *pkg.ServiceMetrics) *float64 { return metrics.MemoryUtilization }
+	writeGauge(w, "bwcli_service_cpu_utilization", "CPU utilization of the service as a percentage of its reservation.", withMetric(e.services, cpu),
+		func(service pkg.ServiceDetails) float64 { return *cpu(service.Metrics) })
+	writeGauge(w, "bwcli_service_memory_utilization", "Memory utilization of the service as a percentage of its reservation.", withMetric(e.services, memory),
+		func(service pkg.ServiceDetails) float64 { return *memory(service.Metrics) })
+}
+
+// withMetric returns the services that have a value for the given metric
+func withMetric(services []pkg.ServiceDetails, metric func(*pkg.ServiceMetrics) *float64) []pkg.ServiceDetails {
+	var with []pkg.ServiceDetails
+	for _, service := range services {
+		if service.Metrics != nil && metric(service.Metrics) != nil {
+			with = append(with, service)
+		}
+	}
+	return with
+}
+
+func writeGauge(w http.ResponseWriter, name, help string, services []pkg.ServiceDetails, value func(pkg.ServiceDetails) float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	for _, service := range services {
+		fmt.Fprintf(w, "%s{cluster=\"%s\",service=\"%s\"} %g\n", name,
+			escapeLabel(aws.ClusterName(service.Cluster)), escapeLabel(service.ServiceName), value(service))
+	}
+}
+
+// escapeLabel escapes a label value per the Prometheus text exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
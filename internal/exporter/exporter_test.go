@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeMetrics(t *testing.T) {
+	e := New()
+	e.Update([]pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", RunningCount: 2, DesiredCount: 3,
+			Metrics: &pkg.ServiceMetrics{CPUUtilization: aws.Float64(12.5), MemoryUtilization: aws.Float64(40)}},
+		{ServiceName: `odd"name`, Cluster: "dev", RunningCount: 1, DesiredCount: 1},
+		{ServiceName: "idle", Cluster: "dev", Metrics: &pkg.ServiceMetrics{CPUUtilization: aws.Float64(0)}},
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, body, "# TYPE bwcli_service_running_count gauge")
+	assert.Contains(t, body, `bwcli_service_running_count{cluster="prod",service="api"} 2`)
+	assert.Contains(t, body, `bwcli_service_desired_count{cluster="prod",service="api"} 3`)
+	assert.Contains(t, body, `bwcli_service_running_count{cluster="dev",service="odd\"name"} 1`)
+	assert.Contains(t, body, `bwcli_service_cpu_utilization{cluster="prod",service="api"} 12.5`)
+	assert.Contains(t, body, `bwcli_service_memory_utilization{cluster="prod",service="api"} 40`)
+	assert.NotContains(t, body, `bwcli_service_cpu_utilization{cluster="dev",service="odd`)
+	assert.Contains(t, body, `bwcli_service_cpu_utilization{cluster="dev",service="idle"} 0`)
+	assert.NotContains(t, body, `bwcli_service_memory_utilization{cluster="dev",service="idle"}`)
+}
+
+func TestServeUnknownPath(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
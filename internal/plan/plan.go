@@ -0,0 +1,167 @@
+package plan
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+var header = []string{"cluster", "service", "desiredCount"}
+
+// Entry is one row of a scaling plan: the desired count a service should have
+type Entry struct {
+	Cluster      string
+	Service      string
+	DesiredCount int64
+}
+
+// Write writes the current desired count of every service as a plan,
+// preceded by a header row
+func Write(w io.Writer, services []pkg.ServiceDetails) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing plan header: %v", err)
+	}
+
+	for _, service := range services {
+		row := []string{
+			aws.ClusterName(service.Cluster),
+			service.ServiceName,
+			strconv.FormatInt(service.DesiredCount, 10),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing plan row for service %s: %v", service.ServiceName, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Read parses a plan written by Write. Every row must name a service once
+// and give it a non-negative desired count.
+func Read(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(header)
+	reader.Comment = '#'
+
+	first, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("plan is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan header: %v", err)
+	}
+	if strings.Join(first, ",") != strings.Join(header, ",") {
+		return nil, fmt.Errorf("unexpected plan header %q (expected %q)", strings.Join(first, ","), strings.Join(header, ","))
+	}
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading plan: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		entry := Entry{Cluster: strings.TrimSpace(row[0]), Service: strings.TrimSpace(row[1])}
+		if entry.Cluster == "" || entry.Service == "" {
+			return nil, fmt.Errorf("line %d: cluster and service are required", line)
+		}
+		entry.DesiredCount, err = strconv.ParseInt(strings.TrimSpace(row[2]), 10, 64)
+		if err != nil || entry.DesiredCount < 0 {
+			return nil, fmt.Errorf("line %d: invalid desired count %q", line, row[2])
+		}
+
+		k := key(entry.Cluster, entry.Service)
+		if seen[k] {
+			return nil, fmt.Errorf("line %d: service %s appears more than once", line, k)
+		}
+		seen[k] = true
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Load reads the plan at path
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening plan %s: %v", path, err)
+	}
+	defer f.Close()
+
+	entries, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("error in plan %s: %v", path, err)
+	}
+	return entries, nil
+}
+
+func key(cluster, serviceName string) string {
+	return cluster + "/" + serviceName
+}
+
+// Diff compares the plan against the live services. It returns an update for
+// every service whose desired count differs from the plan, in plan order,
+// and the entries that name a service that does not exist.
+func Diff(entries []Entry, services []pkg.ServiceDetails) ([]aws.DesiredCountUpdate, []Entry) {
+	live := liveServices(services)
+
+	var updates []aws.DesiredCountUpdate
+	var missing []Entry
+	for _, entry := range entries {
+		service, ok := live[key(aws.ClusterName(entry.Cluster), entry.Service)]
+		if !ok {
+			missing = append(missing, entry)
+			continue
+		}
+		if service.DesiredCount != entry.DesiredCount {
+			updates = append(updates, aws.DesiredCountUpdate{Service: service, DesiredCount: entry.DesiredCount})
+		}
+	}
+	return updates, missing
+}
+
+func liveServices(services []pkg.ServiceDetails) map[string]pkg.ServiceDetails {
+	live := make(map[string]pkg.ServiceDetails, len(services))
+	for _, service := range services {
+		live[key(aws.ClusterName(service.Cluster), service.ServiceName)] = service
+	}
+	return live
+}
+
+// WritePreview writes every service in the plan with its current and target
+// desired count as an aligned table, marking the rows that will change with
+// an asterisk so a stale plan is noticed before it is applied
+func WritePreview(w io.Writer, entries []Entry, services []pkg.ServiceDetails) error {
+	live := liveServices(services)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tCLUSTER\tSERVICE\tCURRENT\tTARGET\tCHANGE")
+	for _, entry := range entries {
+		cluster := aws.ClusterName(entry.Cluster)
+		service, ok := live[key(cluster, entry.Service)]
+		switch {
+		case !ok:
+			fmt.Fprintf(tw, "\t%s\t%s\t-\t%d\tnot found\n", cluster, entry.Service, entry.DesiredCount)
+		case service.DesiredCount != entry.DesiredCount:
+			fmt.Fprintf(tw, "*\t%s\t%s\t%d\t%d\t%+d\n", cluster, entry.Service, service.DesiredCount, entry.DesiredCount, entry.DesiredCount-service.DesiredCount)
+		default:
+			fmt.Fprintf(tw, "\t%s\t%s\t%d\t%d\t\n", cluster, entry.Service, service.DesiredCount, entry.DesiredCount)
+		}
+	}
+	return tw.Flush()
+}
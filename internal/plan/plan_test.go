@@ -0,0 +1,87 @@
+package plan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAndRead(t *testing.T) {
+	services := []pkg.ServiceDetails{
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "api", DesiredCount: 3},
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "worker", DesiredCount: 0},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, services))
+	assert.Equal(t, "cluster,service,desiredCount\nprod,api,3\nprod,worker,0\n", buf.String())
+
+	entries, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Cluster: "prod", Service: "api", DesiredCount: 3},
+		{Cluster: "prod", Service: "worker", DesiredCount: 0},
+	}, entries)
+}
+
+func TestReadRejectsInvalidPlans(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"wrong header":   "cluster,service,desired\nprod,api,1\n",
+		"negative count": "cluster,service,desiredCount\nprod,api,-1\n",
+		"not a number":   "cluster,service,desiredCount\nprod,api,two\n",
+		"missing name":   "cluster,service,desiredCount\nprod,,1\n",
+		"duplicate":      "cluster,service,desiredCount\nprod,api,1\nprod,api,2\n",
+		"extra column":   "cluster,service,desiredCount\nprod,api,1,x\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReadSkipsComments(t *testing.T) {
+	entries, err := Read(strings.NewReader("cluster,service,desiredCount\n# scale down overnight\nprod,api, 1\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Cluster: "prod", Service: "api", DesiredCount: 1}}, entries)
+}
+
+func TestDiff(t *testing.T) {
+	api := pkg.ServiceDetails{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "api", DesiredCount: 3}
+	worker := pkg.ServiceDetails{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "worker", DesiredCount: 1}
+
+	entries := []Entry{
+		{Cluster: "prod", Service: "worker", DesiredCount: 4},
+		{Cluster: "prod", Service: "api", DesiredCount: 3},
+		{Cluster: "staging", Service: "api", DesiredCount: 1},
+	}
+
+	updates, missing := Diff(entries, []pkg.ServiceDetails{api, worker})
+	assert.Equal(t, []aws.DesiredCountUpdate{{Service: worker, DesiredCount: 4}}, updates)
+	assert.Equal(t, []Entry{{Cluster: "staging", Service: "api", DesiredCount: 1}}, missing)
+}
+
+func TestWritePreview(t *testing.T) {
+	api := pkg.ServiceDetails{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "api", DesiredCount: 3}
+	worker := pkg.ServiceDetails{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "worker", DesiredCount: 5}
+
+	entries := []Entry{
+		{Cluster: "prod", Service: "worker", DesiredCount: 2},
+		{Cluster: "prod", Service: "api", DesiredCount: 3},
+		{Cluster: "staging", Service: "api", DesiredCount: 1},
+	}
+
+	var out strings.Builder
+	assert.NoError(t, WritePreview(&out, entries, []pkg.ServiceDetails{api, worker}))
+	assert.Equal(t, ""+
+		"   CLUSTER  SERVICE  CURRENT  TARGET  CHANGE\n"+
+		"*  prod     worker   5        2       -3\n"+
+		"   prod     api      3        3       \n"+
+		"   staging  api      -        1       not found\n", out.String())
+}
@@ -0,0 +1,175 @@
+package restartprogress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Progress records which services of a bulk restart have not been restarted
+// yet, keyed by cluster and service. It is saved after every restart, so a
+// restart interrupted by quitting or a crash can be resumed on the next launch.
+// Its methods may be called from concurrent restarts.
+type Progress struct {
+	path      string
+	mu        sync.Mutex
+	StartedAt time.Time `json:"startedAt"`
+	Pending   []string  `json:"pending"`
+}
+
+// DefaultPath returns the location of the restart progress file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine config directory: %v", err)
+	}
+	return filepath.Join(dir, "bw-cli", "restart-progress.json"), nil
+}
+
+// Load reads the restart progress at path. A missing file means no restart
+// was interrupted and yields empty progress.
+func Load(path string) (*Progress, error) {
+	progress := &Progress{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading restart progress %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("error parsing restart progress %s: %v", path, err)
+	}
+	return progress, nil
+}
+
+// ErrUnfinished is returned by Start while services of an earlier restart
+// are still pending, so their progress isn't lost
+var ErrUnfinished = errors.New("an earlier restart is unfinished")
+
+func key(cluster, serviceName string) string {
+	return cluster + "/" + serviceName
+}
+
+// Start records every service as pending and saves the progress. Call it
+// before restarting any of them. It fails with ErrUnfinished while an
+// earlier restart has services pending; resume or clear that one first.
+func (p *Progress) Start(services []pkg.ServiceDetails) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.Pending) > 0 {
+		return fmt.Errorf("%w: %d service(s) started %s are still pending", ErrUnfinished, len(p.Pending), p.StartedAt.Local().Format("2006-01-02 15:04"))
+	}
+	p.StartedAt = time.Now()
+	p.setPending(services)
+	return p.save()
+}
+
+// Resume records services, the ones of an unfinished restart that can still
+// be restarted, as pending and saves the progress. Pending services not among
+// them are dropped.
+func (p *Progress) Resume(services []pkg.ServiceDetails) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.setPending(services)
+	return p.save()
+}
+
+// setPending replaces the pending services. The caller must hold the lock.
+func (p *Progress) setPending(services []pkg.ServiceDetails) {
+	p.Pending = make([]string, 0, len(services))
+	for _, service := range services {
+		p.Pending = append(p.Pending, key(service.Cluster, service.ServiceName))
+	}
+}
+
+// Done records that a service has been restarted and saves the progress.
+// Once no service is pending the file is removed.
+func (p *Progress) Done(cluster, serviceName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k := key(cluster, serviceName)
+	pending := make([]string, 0, len(p.Pending))
+	for _, pendingKey := range p.Pending {
+		if pendingKey != k {
+			pending = append(pending, pendingKey)
+		}
+	}
+	p.Pending = pending
+	return p.save()
+}
+
+// Clear forgets the pending services and removes the file
+func (p *Progress) Clear() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Pending = nil
+	return p.save()
+}
+
+// PendingKeys returns the cluster/service keys of the services not restarted yet
+func (p *Progress) PendingKeys() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.Pending...)
+}
+
+// PendingServices returns the listed services that are still pending, and
+// the keys of pending services that aren't listed, e.g. because they were
+// deleted or their cluster is excluded.
+func (p *Progress) PendingServices(services []pkg.ServiceDetails) ([]pkg.ServiceDetails, []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	listed := make(map[string]pkg.ServiceDetails, len(services))
+	for _, service := range services {
+		listed[key(service.Cluster, service.ServiceName)] = service
+	}
+
+	var found []pkg.ServiceDetails
+	var missing []string
+	for _, k := range p.Pending {
+		if service, ok := listed[k]; ok {
+			found = append(found, service)
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	return found, missing
+}
+
+// save writes the progress, or removes the file when nothing is pending.
+// The caller must hold the lock.
+func (p *Progress) save() error {
+	if len(p.Pending) == 0 {
+		if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing restart progress %s: %v", p.path, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding restart progress: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o755); err != nil {
+		return fmt.Errorf("error creating restart progress directory: %v", err)
+	}
+	if err := os.WriteFile(p.path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing restart progress %s: %v", p.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package restartprogress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressSurvivesInterruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "restart-progress.json")
+	services := []pkg.ServiceDetails{
+		{Cluster: "prod", ServiceName: "api"},
+		{Cluster: "prod", ServiceName: "worker"},
+		{Cluster: "dev", ServiceName: "api"},
+	}
+
+	progress, err := Load(path)
+	assert.NoError(t, err)
+	assert.Empty(t, progress.PendingKeys())
+
+	assert.NoError(t, progress.Start(services))
+	assert.NoError(t, progress.Done("prod", "api"))
+
+	// A later launch sees the services that were never restarted
+	reloaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod/worker", "dev/api"}, reloaded.PendingKeys())
+	assert.False(t, reloaded.StartedAt.IsZero())
+
+	found, missing := reloaded.PendingServices(services[1:2])
+	assert.Equal(t, services[1:2], found)
+	assert.Equal(t, []string{"dev/api"}, missing)
+}
+
+func TestProgressRemovedWhenComplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "restart-progress.json")
+	progress, err := Load(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, progress.Start([]pkg.ServiceDetails{{Cluster: "prod", ServiceName: "api"}}))
+	assert.FileExists(t, path)
+
+	assert.NoError(t, progress.Done("prod", "api"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, progress.Start([]pkg.ServiceDetails{{Cluster: "prod", ServiceName: "api"}}))
+	assert.NoError(t, progress.Clear())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStartRefusesUnfinishedRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "restart-progress.json")
+	services := []pkg.ServiceDetails{
+		{Cluster: "prod", ServiceName: "api"},
+		{Cluster: "prod", ServiceName: "worker"},
+	}
+	progress, err := Load(path)
+	assert.NoError(t, err)
+	assert.NoError(t, progress.Start(services))
+
+	// A new restart doesn't overwrite the pending services of the earlier one
+	reloaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, reloaded.Start([]pkg.ServiceDetails{{Cluster: "dev", ServiceName: "api"}}), ErrUnfinished)
+	assert.Equal(t, []string{"prod/api", "prod/worker"}, reloaded.PendingKeys())
+
+	// Resuming keeps the services that can still be restarted
+	assert.NoError(t, reloaded.Resume(services[1:]))
+	reloaded, err = Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod/worker"}, reloaded.PendingKeys())
+	assert.Equal(t, progress.StartedAt.Unix(), reloaded.StartedAt.Unix())
+}
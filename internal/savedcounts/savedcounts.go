@@ -0,0 +1,149 @@
+package savedcounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Store remembers the desired counts services had before being scaled to
+// zero, keyed by cluster and service, so they can be restored later.
+type Store struct {
+	path   string
+	Counts map[string]int64 `json:"counts"`
+}
+
+// DefaultPath returns the location of the saved counts file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine config directory: %v", err)
+	}
+	return filepath.Join(dir, "bw-cli", "saved-counts.json"), nil
+}
+
+// Load reads the saved counts at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Counts: make(map[string]int64)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading saved counts %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("error parsing saved counts %s: %v", path, err)
+	}
+	if store.Counts == nil {
+		store.Counts = make(map[string]int64)
+	}
+	for k, count := range store.Counts {
+		// Counts saved before keys were normalized may be keyed by cluster ARN
+		if i := strings.Index(k, ":cluster/"); strings.HasPrefix(k, "arn:") && i >= 0 {
+			delete(store.Counts, k)
+			store.Counts[k[i+len(":cluster/"):]] = count
+		}
+	}
+	return store, nil
+}
+
+// Save writes the store back to the file it was loaded from
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding saved counts: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("error creating saved counts directory: %v", err)
+	}
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing saved counts %s: %v", s.path, err)
+	}
+	return nil
+}
+
+// key identifies a service by cluster name rather than ARN, so counts saved
+// by the UI, which knows clusters by ARN, can be restored by scale-cluster,
+// which is given a name, and the other way round
+func key(cluster, serviceName string) string {
+	return aws.ClusterName(cluster) + "/" + serviceName
+}
+
+// Remember records the desired count of a service
+func (s *Store) Remember(cluster, serviceName string, desiredCount int64) {
+	s.Counts[key(cluster, serviceName)] = desiredCount
+}
+
+// Get returns the saved desired count of a service, if any
+func (s *Store) Get(cluster, serviceName string) (int64, bool) {
+	count, ok := s.Counts[key(cluster, serviceName)]
+	return count, ok
+}
+
+// Forget removes the saved desired count of a service
+func (s *Store) Forget(cluster, serviceName string) {
+	delete(s.Counts, key(cluster, serviceName))
+}
+
+// HasCluster reports whether any service in cluster has a saved count
+func (s *Store) HasCluster(cluster string) bool {
+	for k := range s.Counts {
+		if strings.HasPrefix(k, aws.ClusterName(cluster)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ZeroUpdates remembers the desired count of every service that is not
+// already scaled to zero, and returns the updates that scale them to zero.
+// Daemon services have no desired count to set and are left alone. Save the
+// store before applying the updates so the counts are never lost.
+func (s *Store) ZeroUpdates(services []pkg.ServiceDetails) []aws.DesiredCountUpdate {
+	var updates []aws.DesiredCountUpdate
+	for _, service := range services {
+		if service.DesiredCount == 0 || service.IsDaemon() {
+			continue
+		}
+		s.Remember(service.Cluster, service.ServiceName, service.DesiredCount)
+		updates = append(updates, aws.DesiredCountUpdate{Service: service, DesiredCount: 0})
+	}
+	return updates
+}
+
+// RestoreUpdates returns the updates that set each service with a saved
+// count back to it. Counts saved for daemon services can never be restored,
+// so they are forgotten instead.
+func (s *Store) RestoreUpdates(services []pkg.ServiceDetails) []aws.DesiredCountUpdate {
+	var updates []aws.DesiredCountUpdate
+	for _, service := range services {
+		if service.IsDaemon() {
+			s.Forget(service.Cluster, service.ServiceName)
+			continue
+		}
+		if count, ok := s.Get(service.Cluster, service.ServiceName); ok {
+			updates = append(updates, aws.DesiredCountUpdate{Service: service, DesiredCount: count})
+		}
+	}
+	return updates
+}
+
+// ForgetRestored drops the saved counts of services that were restored
+// successfully, keeping the rest for a later attempt
+func (s *Store) ForgetRestored(results []aws.ServiceResult) {
+	for _, result := range results {
+		if result.Err == nil {
+			s.Forget(result.Service.Cluster, result.Service.ServiceName)
+		}
+	}
+}
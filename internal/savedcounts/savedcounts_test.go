@@ -0,0 +1,126 @@
+package savedcounts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRememberAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved-counts.json")
+
+	store, err := Load(path)
+	assert.NoError(t, err)
+	assert.False(t, store.HasCluster("dev"))
+
+	store.Remember("dev", "api", 3)
+	store.Remember("dev", "worker", 1)
+	store.Remember("development", "api", 2)
+	assert.NoError(t, store.Save())
+
+	reloaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.True(t, reloaded.HasCluster("dev"))
+
+	count, ok := reloaded.Get("dev", "api")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), count)
+
+	reloaded.Forget("dev", "api")
+	reloaded.Forget("dev", "worker")
+	assert.False(t, reloaded.HasCluster("dev"))
+	assert.True(t, reloaded.HasCluster("development"))
+}
+
+func TestZeroAndRestoreUpdates(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "saved-counts.json"))
+	assert.NoError(t, err)
+
+	services := []pkg.ServiceDetails{
+		{Cluster: "dev", ServiceName: "api", DesiredCount: 3},
+		{Cluster: "dev", ServiceName: "worker", DesiredCount: 1},
+		{Cluster: "dev", ServiceName: "idle", DesiredCount: 0},
+	}
+
+	zero := store.ZeroUpdates(services)
+	assert.Len(t, zero, 2)
+	assert.Equal(t, int64(0), zero[0].DesiredCount)
+	_, ok := store.Get("dev", "idle")
+	assert.False(t, ok)
+
+	restore := store.RestoreUpdates(services)
+	assert.Equal(t, []aws.DesiredCountUpdate{
+		{Service: services[0], DesiredCount: 3},
+		{Service: services[1], DesiredCount: 1},
+	}, restore)
+
+	store.ForgetRestored([]aws.ServiceResult{
+		{Service: services[0]},
+		{Service: services[1], Err: errors.New("throttled")},
+	})
+	_, ok = store.Get("dev", "api")
+	assert.False(t, ok)
+	_, ok = store.Get("dev", "worker")
+	assert.True(t, ok)
+}
+
+func TestDaemonsAreNotZeroedOrRestored(t *testing.T) {
+	store, err := Load(filepath.Join(t.TempDir(), "saved-counts.json"))
+	assert.NoError(t, err)
+
+	api := pkg.ServiceDetails{Cluster: "dev", ServiceName: "api", DesiredCount: 2}
+	agent := pkg.ServiceDetails{Cluster: "dev", ServiceName: "log-agent", DesiredCount: 3, SchedulingStrategy: "DAEMON"}
+
+	zero := store.ZeroUpdates([]pkg.ServiceDetails{api, agent})
+	assert.Equal(t, []aws.DesiredCountUpdate{{Service: api, DesiredCount: 0}}, zero)
+	_, ok := store.Get("dev", "log-agent")
+	assert.False(t, ok)
+
+	// A count saved for a daemon before they were skipped is dropped
+	store.Remember("dev", "log-agent", 3)
+	restore := store.RestoreUpdates([]pkg.ServiceDetails{api, agent})
+	assert.Equal(t, []aws.DesiredCountUpdate{{Service: api, DesiredCount: 2}}, restore)
+	_, ok = store.Get("dev", "log-agent")
+	assert.False(t, ok)
+}
+
+func TestClusterArnAndNameAreInterchangeable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved-counts.json")
+	arn := "arn:aws:ecs:us-east-1:123456789012:cluster/dev"
+
+	// Zeroed from the UI, which knows the cluster by ARN
+	store, err := Load(path)
+	assert.NoError(t, err)
+	store.ZeroUpdates([]pkg.ServiceDetails{{Cluster: arn, ServiceName: "api", DesiredCount: 3}})
+	assert.NoError(t, store.Save())
+
+	// Restored by scale-cluster, which is given the name
+	reloaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.True(t, reloaded.HasCluster("dev"))
+	restore := reloaded.RestoreUpdates([]pkg.ServiceDetails{{Cluster: "dev", ServiceName: "api"}})
+	assert.Equal(t, []aws.DesiredCountUpdate{{Service: pkg.ServiceDetails{Cluster: "dev", ServiceName: "api"}, DesiredCount: 3}}, restore)
+
+	// And the other way round
+	reloaded.Remember("dev", "worker", 2)
+	assert.True(t, reloaded.HasCluster(arn))
+	count, ok := reloaded.Get(arn, "worker")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestLoadNormalizesArnKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved-counts.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"counts": {"arn:aws:ecs:us-east-1:123456789012:cluster/dev/api": 3}}`), 0o644))
+
+	store, err := Load(path)
+	assert.NoError(t, err)
+	count, ok := store.Get("dev", "api")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), count)
+}
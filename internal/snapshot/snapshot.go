@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Change kinds reported by Diff
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change describes how a single service differs between two snapshots
+type Change struct {
+	Cluster     string              `json:"cluster"`
+	ServiceName string              `json:"serviceName"`
+	Kind        string              `json:"kind"`
+	Before      *pkg.ServiceDetails `json:"before,omitempty"`
+	After       *pkg.ServiceDetails `json:"after,omitempty"`
+}
+
+// Load reads a snapshot written by `bw-cli export --format json`
+func Load(path string) ([]pkg.ServiceDetails, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot %s: %v", path, err)
+	}
+
+	var services []pkg.ServiceDetails
+	if err := json.Unmarshal(data, &services); err != nil {
+		return nil, fmt.Errorf("error parsing snapshot %s: %v", path, err)
+	}
+	return services, nil
+}
+
+// Diff compares two snapshots and reports services that were added, removed,
+// or whose running count, desired count, or status changed.
+func Diff(before, after []pkg.ServiceDetails) []Change {
+	beforeByKey := indexServices(before)
+	afterByKey := indexServices(after)
+
+	var changes []Change
+	for key, b := range beforeByKey {
+		b := b
+		a, ok := afterByKey[key]
+		if !ok {
+			changes = append(changes, Change{Cluster: b.Cluster, ServiceName: b.ServiceName, Kind: Removed, Before: &b})
+			continue
+		}
+		if a.RunningCount != b.RunningCount || a.DesiredCount != b.DesiredCount || a.Status != b.Status {
+			changes = append(changes, Change{Cluster: b.Cluster, ServiceName: b.ServiceName, Kind: Changed, Before: &b, After: &a})
+		}
+	}
+	for key, a := range afterByKey {
+		a := a
+		if _, ok := beforeByKey[key]; !ok {
+			changes = append(changes, Change{Cluster: a.Cluster, ServiceName: a.ServiceName, Kind: Added, After: &a})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Cluster != changes[j].Cluster {
+			return changes[i].Cluster < changes[j].Cluster
+		}
+		return changes[i].ServiceName < changes[j].ServiceName
+	})
+	return changes
+}
+
+func indexServices(services []pkg.ServiceDetails) map[string]pkg.ServiceDetails {
+	byKey := make(map[string]pkg.ServiceDetails, len(services))
+	for _, service := range services {
+		byKey[service.Cluster+"/"+service.ServiceName] = service
+	}
+	return byKey
+}
+
+// WriteText writes one human-readable line per change
+func WriteText(w io.Writer, changes []Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No differences")
+		return err
+	}
+
+	for _, change := range changes {
+		name := fmt.Sprintf("%s/%s", aws.ClusterName(change.Cluster), change.ServiceName)
+		var line string
+		switch change.Kind {
+		case Added:
+			line = fmt.Sprintf("+ %s (added)", name)
+		case Removed:
+			line = fmt.Sprintf("- %s (removed)", name)
+		default:
+			line = fmt.Sprintf("~ %s: %s", name, describeChange(*change.Before, *change.After))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describeChange(before, after pkg.ServiceDetails) string {
+	var parts []string
+	if before.DesiredCount != after.DesiredCount {
+		parts = append(parts, fmt.Sprintf("desired %d -> %d", before.DesiredCount, after.DesiredCount))
+	}
+	if before.RunningCount != after.RunningCount {
+		parts = append(parts, fmt.Sprintf("running %d -> %d", before.RunningCount, after.RunningCount))
+	}
+	if before.Status != after.Status {
+		parts = append(parts, fmt.Sprintf("status %s -> %s", before.Status, after.Status))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteJSON writes the changes as an indented JSON array
+func WriteJSON(w io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(changes); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := []pkg.ServiceDetails{
+		{Cluster: "prod", ServiceName: "api", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{Cluster: "prod", ServiceName: "worker", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{Cluster: "prod", ServiceName: "legacy", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+	after := []pkg.ServiceDetails{
+		{Cluster: "prod", ServiceName: "api", RunningCount: 3, DesiredCount: 4, Status: "ACTIVE"},
+		{Cluster: "prod", ServiceName: "worker", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{Cluster: "prod", ServiceName: "search", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	changes := Diff(before, after)
+
+	assert.Len(t, changes, 3)
+	assert.Equal(t, "api", changes[0].ServiceName)
+	assert.Equal(t, Changed, changes[0].Kind)
+	assert.Equal(t, "legacy", changes[1].ServiceName)
+	assert.Equal(t, Removed, changes[1].Kind)
+	assert.Equal(t, "search", changes[2].ServiceName)
+	assert.Equal(t, Added, changes[2].Kind)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, changes))
+	assert.Equal(t, "~ prod/api: desired 2 -> 4, running 2 -> 3\n"+
+		"- prod/legacy (removed)\n"+
+		"+ prod/search (added)\n", buf.String())
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	services := []pkg.ServiceDetails{
+		{Cluster: "prod", ServiceName: "api", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+	}
+
+	changes := Diff(services, services)
+	assert.Empty(t, changes)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, changes))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	err := os.WriteFile(path, []byte(`[{"cluster": "prod", "serviceName": "api", "desiredCount": 2}]`), 0o644)
+	assert.NoError(t, err)
+
+	services, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pkg.ServiceDetails{{Cluster: "prod", ServiceName: "api", DesiredCount: 2}}, services)
+}
@@ -0,0 +1,84 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CurrentVersion is the schema version written to the state file. Files with
+// any other version are discarded and replaced with defaults on load.
+const CurrentVersion = 1
+
+// State holds the UI settings that are remembered between runs
+type State struct {
+	Version          int             `json:"version"`
+	GroupFilter      string          `json:"groupFilter"`
+	SelectedClusters []string        `json:"selectedClusters,omitempty"` // Clusters last chosen in the startup picker
+	PinnedServices   []string        `json:"pinnedServices,omitempty"`   // "cluster/service" keys listed first
+	MutedServices    []string        `json:"mutedServices,omitempty"`    // "cluster/service" keys left out of the unhealthy count
+	ListVerbosity    string          `json:"listVerbosity,omitempty"`    // Columns shown in the service list; all when empty
+	Views            map[string]View `json:"views,omitempty"`            // Saved views by name
+}
+
+// View is a named combination of list settings that can be switched to at once
+type View struct {
+	Query          string   `json:"query,omitempty"` // Search bar contents
+	GroupFilter    string   `json:"groupFilter,omitempty"`
+	PinnedServices []string `json:"pinnedServices,omitempty"`
+	ListVerbosity  string   `json:"listVerbosity,omitempty"`
+	ClusterFilter  string   `json:"clusterFilter,omitempty"` // Cluster ARN narrowed to with f
+	Clusters       []string `json:"clusters,omitempty"`      // Cluster ARNs covered; empty for every cluster
+}
+
+// Default returns the state used when nothing has been persisted yet
+func Default() *State {
+	return &State{Version: CurrentVersion}
+}
+
+// DefaultPath returns the location of the state file in the user's config directory
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine config directory: %v", err)
+	}
+	return filepath.Join(dir, "bw-cli", "state.json"), nil
+}
+
+// Load reads the state file at path. A missing, corrupt, or differently
+// versioned file yields the default state rather than an error, so a schema
+// change never prevents the UI from starting.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), fmt.Errorf("error reading state file %s: %v", path, err)
+	}
+
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil || st.Version != CurrentVersion {
+		return Default(), nil
+	}
+	return &st, nil
+}
+
+// Save writes the state to path, creating the parent directory if needed
+func Save(path string, st *State) error {
+	st.Version = CurrentVersion
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating state directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing state file %s: %v", path, err)
+	}
+	return nil
+}
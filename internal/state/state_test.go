@@ -0,0 +1,77 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	st, err := Load(filepath.Join(t.TempDir(), "state.json"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), st)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	err := Save(path, &State{GroupFilter: "payments", SelectedClusters: []string{"payments-prod"}})
+	assert.NoError(t, err)
+
+	st, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, CurrentVersion, st.Version)
+	assert.Equal(t, "payments", st.GroupFilter)
+	assert.Equal(t, []string{"payments-prod"}, st.SelectedClusters)
+}
+
+func TestSaveAndLoadViews(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	views := map[string]View{
+		"incidents": {Query: "running<1", ListVerbosity: "status"},
+		"payments":  {GroupFilter: "payments", PinnedServices: []string{"payments-prod/api"}},
+	}
+
+	assert.NoError(t, Save(path, &State{Views: views}))
+
+	st, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, views, st.Views)
+}
+
+func TestLoadVersionZeroFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// Files written before versioning have no version field
+	err := os.WriteFile(path, []byte(`{"groupFilter": "payments"}`), 0o644)
+	assert.NoError(t, err)
+
+	st, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), st)
+}
+
+func TestLoadUnknownVersionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	err := os.WriteFile(path, []byte(`{"version": 99, "groupFilter": "payments"}`), 0o644)
+	assert.NoError(t, err)
+
+	st, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), st)
+}
+
+func TestLoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	err := os.WriteFile(path, []byte(`{"version": 1, "groupFil`), 0o644)
+	assert.NoError(t, err)
+
+	st, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), st)
+}
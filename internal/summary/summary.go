@@ -0,0 +1,104 @@
+package summary
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Columns that summaries can be sorted by
+const (
+	SortByName     = "name"
+	SortByServices = "services"
+	SortByRunning  = "running"
+	SortByDesired  = "desired"
+)
+
+// ClusterSummary totals the services and tasks of a cluster
+type ClusterSummary struct {
+	Cluster      string `json:"cluster"`
+	Services     int    `json:"services"`
+	RunningCount int64  `json:"runningCount"`
+	DesiredCount int64  `json:"desiredCount"`
+}
+
+// Summarize aggregates services by cluster, ordered by cluster name
+func Summarize(services []pkg.ServiceDetails) []ClusterSummary {
+	byCluster := make(map[string]*ClusterSummary)
+	for _, service := range services {
+		summary, ok := byCluster[service.Cluster]
+		if !ok {
+			summary = &ClusterSummary{Cluster: service.Cluster}
+			byCluster[service.Cluster] = summary
+		}
+		summary.Services++
+		summary.RunningCount += service.RunningCount
+		summary.DesiredCount += service.DesiredCount
+	}
+
+	summaries := make([]ClusterSummary, 0, len(byCluster))
+	for _, summary := range byCluster {
+		summaries = append(summaries, *summary)
+	}
+	Sort(summaries, SortByName)
+	return summaries
+}
+
+// ValidSortKey reports whether key is one of the SortBy columns
+func ValidSortKey(key string) bool {
+	switch key {
+	case SortByName, SortByServices, SortByRunning, SortByDesired:
+		return true
+	}
+	return false
+}
+
+// Sort orders summaries by the given column. Names sort ascending and counts
+// descending, so the largest clusters come first; ties are broken by name.
+func Sort(summaries []ClusterSummary, key string) {
+	sort.SliceStable(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		switch key {
+		case SortByServices:
+			if a.Services != b.Services {
+				return a.Services > b.Services
+			}
+		case SortByRunning:
+			if a.RunningCount != b.RunningCount {
+				return a.RunningCount > b.RunningCount
+			}
+		case SortByDesired:
+			if a.DesiredCount != b.DesiredCount {
+				return a.DesiredCount > b.DesiredCount
+			}
+		}
+		return aws.NaturalLess(a.Cluster, b.Cluster)
+	})
+}
+
+// Totals sums the summaries of every cluster
+func Totals(summaries []ClusterSummary) ClusterSummary {
+	var totals ClusterSummary
+	for _, summary := range summaries {
+		totals.Services += summary.Services
+		totals.RunningCount += summary.RunningCount
+		totals.DesiredCount += summary.DesiredCount
+	}
+	return totals
+}
+
+// WriteTable writes the summaries as an aligned table with a totals row
+func WriteTable(w io.Writer, summaries []ClusterSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLUSTER\tSERVICES\tRUNNING\tDESIRED")
+	for _, summary := range summaries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", aws.ClusterName(summary.Cluster), summary.Services, summary.RunningCount, summary.DesiredCount)
+	}
+	totals := Totals(summaries)
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\n", totals.Services, totals.RunningCount, totals.DesiredCount)
+	return tw.Flush()
+}
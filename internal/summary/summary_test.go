@@ -0,0 +1,49 @@
+package summary
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testServices = []pkg.ServiceDetails{
+	{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "api", RunningCount: 4, DesiredCount: 4},
+	{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "web", RunningCount: 1, DesiredCount: 2},
+	{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/dev", ServiceName: "api", RunningCount: 1, DesiredCount: 1},
+}
+
+func TestSummarize(t *testing.T) {
+	summaries := Summarize(testServices)
+
+	assert.Equal(t, []ClusterSummary{
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/dev", Services: 1, RunningCount: 1, DesiredCount: 1},
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", Services: 2, RunningCount: 5, DesiredCount: 6},
+	}, summaries)
+	assert.Equal(t, ClusterSummary{Services: 3, RunningCount: 6, DesiredCount: 7}, Totals(summaries))
+}
+
+func TestSort(t *testing.T) {
+	summaries := Summarize(testServices)
+
+	Sort(summaries, SortByDesired)
+	assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:cluster/prod", summaries[0].Cluster)
+
+	Sort(summaries, SortByName)
+	assert.Equal(t, "arn:aws:ecs:us-east-1:123456789012:cluster/dev", summaries[0].Cluster)
+
+	assert.True(t, ValidSortKey(SortByRunning))
+	assert.False(t, ValidSortKey("status"))
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTable(&buf, Summarize(testServices))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "CLUSTER  SERVICES  RUNNING  DESIRED\n"+
+		"dev      1         1        1\n"+
+		"prod     2         5        6\n"+
+		"TOTAL    3         6        7\n", buf.String())
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Single Cluster Refresh
+// ----------------------
+//
+// Re-fetches every service of one cluster right away: the cluster the list
+// is narrowed to, or else the selected service's cluster. Services created
+// in the cluster since startup join the list and the polled set, and deleted
+// ones leave them. Other clusters keep their last polled details.
+
+// refreshCluster re-fetches the services of the filtered or selected
+// service's cluster in the background
+func (s *ServiceUI) refreshCluster() {
+	cluster := s.clusterFilter
+	if cluster == "" {
+		selected, ok := s.selectedService()
+		if !ok {
+			return
+		}
+		cluster = selected.Cluster
+	}
+	if s.refreshingCluster != "" {
+		s.showToast(fmt.Sprintf("Still refreshing cluster %s", aws.ClusterName(s.refreshingCluster)))
+		return
+	}
+
+	s.refreshingCluster = cluster
+	s.showToast(fmt.Sprintf("Refreshing cluster %s...", aws.ClusterName(cluster)))
+	go func() {
+		services, err := aws.GetClusterServiceDetails(s.ctx, s.ecsClient, cluster)
+		s.app.QueueUpdateDraw(func() {
+			s.applyClusterRefresh(cluster, services, err)
+		})
+	}()
+}
+
+// applyClusterRefresh swaps a cluster's re-fetched services into the list and
+// the polled set
+func (s *ServiceUI) applyClusterRefresh(cluster string, services []pkg.ServiceDetails, err error) {
+	s.refreshingCluster = ""
+	if aws.IsClusterNotFound(err) {
+		s.forgetDeletedClusters([]string{cluster})
+		s.refreshServices(replaceClusterServices(s.currentServices, cluster, nil))
+		return
+	}
+	if err != nil {
+		s.showToast(fmt.Sprintf("Error refreshing cluster %s: %v", aws.ClusterName(cluster), err))
+		return
+	}
+
+	s.polledServices = replaceClusterServices(s.polledServices, cluster, services)
+	s.restartPolling()
+	s.refreshServices(replaceClusterServices(s.currentServices, cluster, services))
+	s.showToast(fmt.Sprintf("Refreshed %d services in cluster %s", len(services), aws.ClusterName(cluster)))
+}
+
+// replaceClusterServices returns services with those of cluster replaced by
+// clusterServices, sorted
+func replaceClusterServices(services []pkg.ServiceDetails, cluster string, clusterServices []pkg.ServiceDetails) []pkg.ServiceDetails {
+	replaced := make([]pkg.ServiceDetails, 0, len(services)+len(clusterServices))
+	for _, service := range services {
+		if service.Cluster != cluster {
+			replaced = append(replaced, service)
+		}
+	}
+	replaced = append(replaced, clusterServices...)
+	aws.SortServices(replaced)
+	return replaced
+}
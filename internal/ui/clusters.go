@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/state"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Cluster Picker
+// --------------
+
+// clusterPicker lets the user check which clusters to load at startup
+type clusterPicker struct {
+	list     *tview.List
+	clusters []string
+	selected map[string]bool
+}
+
+func newClusterPicker(clusters []string, preselected []string) *clusterPicker {
+	sorted := append([]string(nil), clusters...)
+	sort.Slice(sorted, func(i, j int) bool { return aws.NaturalLess(sorted[i], sorted[j]) })
+
+	p := &clusterPicker{
+		list:     tview.NewList().ShowSecondaryText(false),
+		clusters: sorted,
+		selected: make(map[string]bool),
+	}
+	for _, cluster := range preselected {
+		p.selected[cluster] = true
+	}
+	for _, cluster := range sorted {
+		p.list.AddItem(p.itemText(cluster), "", 0, nil)
+	}
+	return p
+}
+
+func (p *clusterPicker) itemText(cluster string) string {
+	mark := "[ ]"
+	if p.selected[cluster] {
+		mark = "[x]"
+	}
+	return fmt.Sprintf("%s %s", tview.Escape(mark), tview.Escape(aws.ClusterName(cluster)))
+}
+
+func (p *clusterPicker) toggle(index int) {
+	cluster := p.clusters[index]
+	p.selected[cluster] = !p.selected[cluster]
+	p.list.SetItemText(index, p.itemText(cluster), "")
+}
+
+func (p *clusterPicker) toggleAll() {
+	allSelected := len(p.selectedClusters()) == len(p.clusters)
+	for i, cluster := range p.clusters {
+		p.selected[cluster] = !allSelected
+		p.list.SetItemText(i, p.itemText(cluster), "")
+	}
+}
+
+// selectedClusters returns the checked clusters in display order
+func (p *clusterPicker) selectedClusters() []string {
+	var selected []string
+	for _, cluster := range p.clusters {
+		if p.selected[cluster] {
+			selected = append(selected, cluster)
+		}
+	}
+	return selected
+}
+
+// DisplayClusterPicker shows a checklist of clusters, preselecting the ones
+// chosen last time. Once the user confirms, the selection is remembered and
+// passed to onSelect.
+func DisplayClusterPicker(app *tview.Application, clusters []string, onSelect func(selected []string)) {
+	st := state.Default()
+	statePath, err := state.DefaultPath()
+	if err == nil {
+		if loaded, err := state.Load(statePath); err == nil {
+			st = loaded
+		}
+	}
+
+	picker := newClusterPicker(clusters, st.SelectedClusters)
+	picker.list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Select clusters to load (%d found) ", len(clusters)))
+	picker.list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		picker.toggle(index)
+	})
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("[yellow]Enter[-] - Toggle | [yellow]a[-] - Toggle all | [green]l[-] - Load selected | [red]q[-] - Quit")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(picker.list, 0, 1, true).
+		AddItem(help, 1, 1, false)
+
+	picker.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case ' ':
+			picker.toggle(picker.list.GetCurrentItem())
+			return nil
+		case 'a':
+			picker.toggleAll()
+			return nil
+		case 'l':
+			selected := picker.selectedClusters()
+			if len(selected) == 0 {
+				return nil
+			}
+			if statePath != "" {
+				st.SelectedClusters = selected
+				_ = state.Save(statePath, st)
+			}
+			onSelect(selected)
+			return nil
+		case 'q':
+			app.Stop()
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(layout, true)
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/clipboard"
+	"github.com/alexalbu001/bw-cli/internal/export"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Copying the List
+// ----------------
+
+// listTable renders the listed services as an aligned text table, with the
+// cached utilization of services whose metrics have been loaded
+func (s *ServiceUI) listTable() (string, error) {
+	services := make([]pkg.ServiceDetails, len(s.filteredServices))
+	for i, service := range s.filteredServices {
+		if entry, ok := s.metrics[serviceKey(service)]; ok && !entry.unavailable {
+			service.SetMetrics(entry.values)
+		}
+		services[i] = service
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteTable(&buf, services); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// copyList copies the listed services as a table to the clipboard. Without a
+// clipboard tool the table is written to a temporary file instead, and its
+// path is shown.
+func (s *ServiceUI) copyList() {
+	table, err := s.listTable()
+	if err != nil {
+		showMessage(s.app, fmt.Sprintf("Error rendering services: %v", err), s.layout)
+		return
+	}
+
+	if err := clipboard.Copy(table); err == nil {
+		s.showToast(fmt.Sprintf("Copied %d services to the clipboard", len(s.filteredServices)))
+		return
+	}
+
+	f, err := os.CreateTemp("", "bw-cli-services-*.txt")
+	if err != nil {
+		showMessage(s.app, fmt.Sprintf("Error writing services: %v", err), s.layout)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(table); err != nil {
+		showMessage(s.app, fmt.Sprintf("Error writing services to %s: %v", f.Name(), err), s.layout)
+		return
+	}
+	showMessage(s.app, fmt.Sprintf("No clipboard available. Services written to:\n\n%s", f.Name()), s.layout)
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/config"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Fargate Cost Estimates
+// ----------------------
+//
+// Fargate bills the vCPU and memory reserved by each running task, so a
+// service's hourly cost is roughly its running count times the reservation of
+// its task definition, priced at its region's rates. Rates come from the
+// config file or a built-in table. The estimates leave out discounts, Spot,
+// ARM pricing and storage, so they are only meant as a rough guide.
+
+// hourlyCost estimates what count tasks with the given reservation cost per
+// hour
+func hourlyCost(reservation pkg.TaskReservation, count int64, rate config.FargateRate) float64 {
+	perTask := float64(reservation.CPU)/1024*rate.VCPUHour + float64(reservation.MemoryMiB)/1024*rate.GBHour
+	return perTask * float64(count)
+}
+
+// costText is the detail view's estimate for a Fargate service, given the
+// rate of its region if there is one
+func costText(service pkg.ServiceDetails, reservation pkg.TaskReservation, rate config.FargateRate, hasRate bool) string {
+	if !service.IsFargate() {
+		return ""
+	}
+	if !hasRate {
+		return fmt.Sprintf("[yellow]Estimated Cost:[-] no Fargate rates for region %q; add them to fargateRates in the config file\n", aws.ServiceRegion(service))
+	}
+	return fmt.Sprintf("[yellow]Estimated Cost:[-] ~$%.4f/h per task, ~$%.2f/h for %d running tasks (~$%.0f/month)\n",
+		hourlyCost(reservation, 1, rate), hourlyCost(reservation, service.RunningCount, rate),
+		service.RunningCount, hourlyCost(reservation, service.RunningCount, rate)*730)
+}
+
+// fleetCost sums the estimates of the Fargate services whose reservation and
+// region rates are known, and counts the Fargate services left out
+func (s *ServiceUI) fleetCost(services []pkg.ServiceDetails) (total float64, priced, unpriced int) {
+	for _, service := range services {
+		if !service.IsFargate() {
+			continue
+		}
+		reservation, known := s.reservations[service.TaskDefinition]
+		rate, hasRate := s.config.FargateRate(aws.ServiceRegion(service))
+		if !known || !hasRate {
+			unpriced++
+			continue
+		}
+		total += hourlyCost(reservation, service.RunningCount, rate)
+		priced++
+	}
+	return total, priced, unpriced
+}
+
+// fleetCostText is the footer's estimate for every listed Fargate service
+func (s *ServiceUI) fleetCostText() string {
+	total, priced, unpriced := s.fleetCost(s.currentServices)
+	if priced == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("Fargate: ~$%.2f/h (~$%.0f/month)", total, total*730)
+	if unpriced > 0 {
+		text += fmt.Sprintf(" [gray](%d not priced)[-]", unpriced)
+	}
+	return text
+}
+
+// updateCostFooter shows the fleet estimate below the list, and collapses
+// the footer when no Fargate service can be priced
+func (s *ServiceUI) updateCostFooter() {
+	text := s.fleetCostText()
+	s.costFooter.SetText(text)
+	if text == "" {
+		s.layout.ResizeItem(s.costFooter, 0, 0)
+		return
+	}
+	s.layout.ResizeItem(s.costFooter, 1, 0)
+}
+
+// loadFargateReservations fetches, in the background, the reservations of the
+// Fargate task definitions the fleet estimate is still missing. Revisions
+// never change, so each one is only fetched once.
+func (s *ServiceUI) loadFargateReservations() {
+	var missing []string
+	for _, service := range s.currentServices {
+		taskDefinition := service.TaskDefinition
+		if !service.IsFargate() || taskDefinition == "" {
+			continue
+		}
+		if _, ok := s.reservations[taskDefinition]; ok || s.reservationsPending[taskDefinition] {
+			continue
+		}
+		s.reservationsPending[taskDefinition] = true
+		missing = append(missing, taskDefinition)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	go func() {
+		for _, taskDefinition := range missing {
+			reservation, err := aws.GetTaskReservation(s.ctx, s.ecsClient, taskDefinition)
+			if err != nil {
+				// Left pending, so a missing permission doesn't cost a call
+				// on every refresh; the service stays unpriced
+				continue
+			}
+			s.app.QueueUpdateDraw(func() {
+				delete(s.reservationsPending, taskDefinition)
+				s.reservations[taskDefinition] = reservation
+				s.updateCostFooter()
+			})
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/rivo/tview"
+)
+
+// Deployment Split
+// ----------------
+//
+// During a rolling, canary or blue/green rollout a service's tasks are split
+// between several deployments (or CodeDeploy task sets). The detail view
+// lists each one with its task counts and a bar showing its share: the task
+// set's scale when ECS reports one, otherwise its share of the running tasks.
+// ECS doesn't report load balancer traffic weights, but behind a load
+// balancer traffic roughly follows the running tasks.
+
+// splitBarWidth is the number of cells in a deployment's share bar
+const splitBarWidth = 20
+
+// deploymentsText lists the deployments of a service that is split between
+// more than one, or returns "" if it isn't
+func deploymentsText(service pkg.ServiceDetails) string {
+	if len(service.Deployments) < 2 {
+		return ""
+	}
+
+	var totalRunning int64
+	for _, deployment := range service.Deployments {
+		totalRunning += deployment.RunningCount
+	}
+
+	var b strings.Builder
+	b.WriteString("[yellow]Deployments:[-]\n")
+	for _, deployment := range service.Deployments {
+		share, label := deploymentShare(deployment, totalRunning)
+		status := tview.Escape(deployment.Status)
+		if deployment.Status == "PRIMARY" {
+			status = "[green]" + status + "[-]"
+		}
+		fmt.Fprintf(&b, "  %s %s %s %3.0f%% %s, %d/%d running",
+			status, tview.Escape(aws.TaskDefinitionName(deployment.TaskDefinition)),
+			splitBar(share), share, label, deployment.RunningCount, deployment.DesiredCount)
+		if deployment.PendingCount > 0 {
+			fmt.Fprintf(&b, ", %d pending", deployment.PendingCount)
+		}
+		switch deployment.RolloutState {
+		case "IN_PROGRESS":
+			b.WriteString(" [blue](in progress)[-]")
+		case "FAILED":
+			b.WriteString(" [red](failed)[-]")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// deploymentShare returns the percentage of the service a deployment accounts
+// for, and what that percentage is of
+func deploymentShare(deployment pkg.Deployment, totalRunning int64) (float64, string) {
+	if deployment.ScalePercent != nil {
+		return *deployment.ScalePercent, "scale"
+	}
+	if totalRunning == 0 {
+		return 0, "of running tasks"
+	}
+	return float64(deployment.RunningCount) / float64(totalRunning) * 100, "of running tasks"
+}
+
+// splitBar draws a percentage as a bar of splitBarWidth cells
+func splitBar(percent float64) string {
+	filled := int(percent/100*splitBarWidth + 0.5)
+	filled = max(0, min(filled, splitBarWidth))
+	return "[green]" + strings.Repeat("█", filled) + "[-][gray]" + strings.Repeat("░", splitBarWidth-filled) + "[-]"
+}
@@ -0,0 +1,389 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Service Detail View
+// -------------------
+
+// detailSection is a part of the service details that is fetched in the
+// background and added once it arrives
+type detailSection struct {
+	title string        // Shown as loading until the section arrives
+	load  func() string // Fetches and renders the section off the UI goroutine
+}
+
+// showServiceDetails displays a service's details, adding each section once
+// it has loaded
+func showServiceDetails(app *tview.Application, service pkg.ServiceDetails, history []countSample, sections []detailSection, layout *tview.Flex) {
+	text := serviceDetailsText(service, history)
+	loaded := make([]string, len(sections))
+	details := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	render := func() {
+		details.SetText(text + strings.Join(loaded, "") + detailsFooter)
+	}
+
+	for i, section := range sections {
+		loaded[i] = fmt.Sprintf("\n[yellow]%s:[-] loading...\n", section.title)
+		go func(i int, section detailSection) {
+			sectionText := section.load()
+			app.QueueUpdateDraw(func() {
+				loaded[i] = sectionText
+				render()
+			})
+		}(i, section)
+	}
+	render()
+	details.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ", tview.Escape(service.ServiceName)))
+
+	details.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc, tcell.KeyEnter:
+			app.SetRoot(layout, true)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(details, true)
+}
+
+func serviceDetailsText(service pkg.ServiceDetails, history []countSample) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Cluster:[-] %s\n", tview.Escape(aws.ClusterName(service.Cluster)))
+	if clusterArn, err := aws.ParseARN(service.Cluster); err == nil {
+		fmt.Fprintf(&b, "[yellow]Region:[-] %s\n", tview.Escape(clusterArn.Region))
+		fmt.Fprintf(&b, "[yellow]Account:[-] %s\n", tview.Escape(clusterArn.AccountID))
+	}
+	fmt.Fprintf(&b, "[yellow]Status:[-] %s\n", tview.Escape(service.Status))
+	fmt.Fprintf(&b, "[yellow]Health:[-] %s\n", healthText(aws.ServiceHealth(service, nil)))
+	if service.CreatedAt != nil {
+		fmt.Fprintf(&b, "[yellow]Created:[-] %s (%s)\n", service.CreatedAt.Local().Format(time.DateOnly), serviceAge(*service.CreatedAt, time.Now()))
+	}
+	fmt.Fprintf(&b, "[yellow]Scheduling Strategy:[-] %s\n", tview.Escape(service.SchedulingStrategy))
+	fmt.Fprintf(&b, "[yellow]Running Count:[-] %d\n", service.RunningCount)
+	if service.IsDaemon() {
+		fmt.Fprintf(&b, "[yellow]Desired Count:[-] %d (one task per container instance)\n", service.DesiredCount)
+	} else {
+		fmt.Fprintf(&b, "[yellow]Desired Count:[-] %d\n", service.DesiredCount)
+	}
+	if len(history) > 1 {
+		fmt.Fprintf(&b, "[yellow]Running Trend:[-] %s over last %d polls\n", countTrend(history, func(c countSample) int64 { return c.running }), len(history))
+		fmt.Fprintf(&b, "[yellow]Desired Trend:[-] %s over last %d polls\n", countTrend(history, func(c countSample) int64 { return c.desired }), len(history))
+		if window := flapWindow(history); FlapChanges > 0 && runningReversals(window) >= FlapChanges {
+			fmt.Fprintf(&b, "[red]Flapping: the running count changed direction %d times over the last %d polls; tasks may be crash looping[-]\n", runningReversals(window), len(window))
+		}
+	}
+	fmt.Fprintf(&b, "[yellow]Task Definition:[-] %s\n", tview.Escape(aws.TaskDefinitionName(service.TaskDefinition)))
+	if service.LastDeployedAt != nil {
+		fmt.Fprintf(&b, "[yellow]Last Deployed:[-] %s\n", lastDeployedText(*service.LastDeployedAt, time.Now()))
+	}
+	if service.PlatformVersion != "" {
+		fmt.Fprintf(&b, "[yellow]Platform Version:[-] %s\n", tview.Escape(service.PlatformVersion))
+	}
+	if network := service.Network; network != nil {
+		fmt.Fprintf(&b, "[yellow]Subnets:[-] %s\n", tview.Escape(joinOrNone(network.Subnets)))
+		fmt.Fprintf(&b, "[yellow]Security Groups:[-] %s\n", tview.Escape(joinOrNone(network.SecurityGroups)))
+		fmt.Fprintf(&b, "[yellow]Public IP:[-] %s\n", tview.Escape(network.AssignPublicIP))
+	}
+	if service.ExecuteCommandEnabled {
+		fmt.Fprintf(&b, "[yellow]ECS Exec:[-] [green]enabled[-]\n")
+	} else {
+		fmt.Fprintf(&b, "[yellow]ECS Exec:[-] [red]disabled[-] (shells with s won't work)\n")
+	}
+	fmt.Fprintf(&b, "[yellow]Tag Propagation:[-] %s\n", tagPropagationText(service.PropagateTags))
+	fmt.Fprintf(&b, "[yellow]ECS Managed Tags:[-] %s\n", onOff(service.ManagedTags))
+	fmt.Fprintf(&b, "[yellow]Minimum Healthy Percent:[-] %s\n", formatPercent(service.MinimumHealthyPercent))
+	fmt.Fprintf(&b, "[yellow]Maximum Percent:[-] %s\n", formatPercent(service.MaximumPercent))
+	fmt.Fprintf(&b, "[yellow]Circuit Breaker:[-] %s\n", circuitBreakerText(service.CircuitBreaker))
+	if service.FailedDeployment != "" {
+		fmt.Fprintf(&b, "[red]Deployment Failed:[-] %s\n", tview.Escape(service.FailedDeployment))
+	}
+	if service.Rollback != "" {
+		fmt.Fprintf(&b, "[yellow]Rollback:[-] %s\n", tview.Escape(service.Rollback))
+	} else if service.FailedDeployment != "" {
+		fmt.Fprintf(&b, "[red]The failed deployment isn't rolled back automatically; deploy a working task definition[-]\n")
+	}
+	b.WriteString(deploymentsText(service))
+	if service.PlacementBlocked {
+		fmt.Fprintf(&b, "[red]ECS is unable to place tasks for this service; check its events[-]\n")
+	}
+	if allowsFullDowntime(service) {
+		fmt.Fprintf(&b, "[red]Redeploys may stop all tasks before replacements start[-]\n")
+	}
+	return b.String()
+}
+
+// tagPropagationText describes where a service's tasks get their tags from
+func tagPropagationText(propagateTags string) string {
+	switch propagateTags {
+	case "TASK_DEFINITION":
+		return "from the task definition"
+	case "SERVICE":
+		return "from the service"
+	case "", "NONE":
+		return "none"
+	}
+	return tview.Escape(propagateTags)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// circuitBreakerText describes whether a service's failed deployments stop
+// and roll back on their own
+func circuitBreakerText(breaker *pkg.CircuitBreaker) string {
+	switch {
+	case breaker == nil:
+		return "unknown"
+	case !breaker.Enabled:
+		return "off"
+	case breaker.Rollback:
+		return "on, rolls back failed deployments"
+	}
+	return "on, without rollback"
+}
+
+// serviceAge describes how long ago a service was created, e.g. "created 3
+// months ago", in the largest whole unit
+func serviceAge(createdAt, now time.Time) string {
+	age := now.Sub(createdAt)
+	day := 24 * time.Hour
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * day},
+		{"month", 30 * day},
+		{"day", day},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int64(age / unit.size); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("created 1 %s ago", unit.name)
+			}
+			return fmt.Sprintf("created %d %ss ago", n, unit.name)
+		}
+	}
+	return "created just now"
+}
+
+const detailsFooter = "\n[gray]Press Esc to return[-]"
+
+// detailSections returns the background-loaded sections of a service's
+// details
+func (s *ServiceUI) detailSections(service pkg.ServiceDetails) []detailSection {
+	var sections []detailSection
+	if loadReservation := s.reservationLoader(service); loadReservation != nil {
+		rate, hasRate := s.config.FargateRate(aws.ServiceRegion(service))
+		sections = append(sections, detailSection{title: "Resource Usage", load: func() string {
+			reservation, err := loadReservation()
+			if err != nil {
+				return resourceUsageText(service, reservation, err)
+			}
+			return resourceUsageText(service, reservation, err) + costText(service, reservation, rate, hasRate)
+		}})
+	}
+	sections = append(sections, detailSection{title: "Tasks by Availability Zone", load: func() string {
+		tasks, err := aws.ListServiceTasks(s.ctx, s.ecsClient, service.Cluster, service.ServiceName)
+		return zoneSpreadText(tasks, err)
+	}})
+	if s.elbClient != nil && len(service.TargetGroupArns) > 0 {
+		sections = append(sections, detailSection{title: "Target Health", load: func() string {
+			results, err := aws.GetTargetHealth(s.ctx, s.ecsClient, s.elbClient, service)
+			text := targetHealthText(results, err)
+			if err == nil {
+				s.app.QueueUpdate(func() {
+					s.setTargetHealth(service, results)
+				})
+				text += fmt.Sprintf("[yellow]Health Including Targets:[-] %s\n", healthText(aws.ServiceHealth(service, results)))
+			}
+			return text
+		}})
+	}
+	return sections
+}
+
+// reservationLoader returns a loader for the reservation of the service's
+// task definition, fetching it only once per revision
+func (s *ServiceUI) reservationLoader(service pkg.ServiceDetails) func() (pkg.TaskReservation, error) {
+	if service.TaskDefinition == "" {
+		return nil
+	}
+	if reservation, ok := s.reservations[service.TaskDefinition]; ok {
+		return func() (pkg.TaskReservation, error) { return reservation, nil }
+	}
+	return func() (pkg.TaskReservation, error) {
+		reservation, err := aws.GetTaskReservation(s.ctx, s.ecsClient, service.TaskDefinition)
+		if err == nil {
+			s.app.QueueUpdate(func() {
+				s.reservations[service.TaskDefinition] = reservation
+			})
+		}
+		return reservation, err
+	}
+}
+
+// resourceUsageText shows the service's utilization as absolute CPU units and
+// memory, computed from the utilization percentages and the reservation of
+// each task
+func resourceUsageText(service pkg.ServiceDetails, reservation pkg.TaskReservation, err error) string {
+	if err != nil {
+		return fmt.Sprintf("\n[yellow]Resource Usage:[-] [red]%s[-]\n", tview.Escape(err.Error()))
+	}
+
+	var b strings.Builder
+	b.WriteString("\n[yellow]Reserved per Task:[-] ")
+	b.WriteString(formatReserved(reservation.CPU, "CPU units"))
+	b.WriteString(", ")
+	b.WriteString(formatReserved(reservation.MemoryMiB, "MiB memory"))
+	b.WriteString("\n")
+	if service.Metrics == nil {
+		b.WriteString("[yellow]In Use:[-] utilization not loaded yet\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "[yellow]CPU In Use:[-] %s\n", usageAmount(service.Metrics.CPUUtilization, reservation.CPU, service.RunningCount, "units"))
+	fmt.Fprintf(&b, "[yellow]Memory In Use:[-] %s\n", usageAmount(service.Metrics.MemoryUtilization, reservation.MemoryMiB, service.RunningCount, "MiB"))
+	if service.Metrics.Source != "" {
+		fmt.Fprintf(&b, "[yellow]Utilization Source:[-] %s\n", tview.Escape(service.Metrics.Source))
+	}
+	return b.String()
+}
+
+func formatReserved(amount int64, unit string) string {
+	if amount == 0 {
+		return "no " + unit
+	}
+	return fmt.Sprintf("%d %s", amount, unit)
+}
+
+// usageAmount converts a utilization percentage of a per-task reservation
+// into the amount in use per task and across the running tasks
+func usageAmount(utilization *float64, reserved, runningCount int64, unit string) string {
+	if utilization == nil {
+		return "n/a (no recent CloudWatch datapoints)"
+	}
+	if reserved == 0 {
+		return fmt.Sprintf("%.1f%% (no reservation to compare against)", *utilization)
+	}
+	perTask := *utilization / 100 * float64(reserved)
+	text := fmt.Sprintf("%.1f%% ≈ %.0f of %d %s per task", *utilization, perTask, reserved, unit)
+	if runningCount > 1 {
+		text += fmt.Sprintf(", %.0f %s across %d tasks", perTask*float64(runningCount), unit, runningCount)
+	}
+	return text
+}
+
+// zoneSpreadText counts running tasks per availability zone, flagging
+// services whose tasks all run in a single zone
+func zoneSpreadText(tasks []pkg.TaskDetails, err error) string {
+	if err != nil {
+		return fmt.Sprintf("\n[yellow]Tasks by Availability Zone:[-] [red]%s[-]\n", tview.Escape(err.Error()))
+	}
+
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		if task.LastStatus != "RUNNING" {
+			continue
+		}
+		zone := task.AvailabilityZone
+		if zone == "" {
+			zone = "unknown"
+		}
+		counts[zone]++
+	}
+	if len(counts) == 0 {
+		return "\n[yellow]Tasks by Availability Zone:[-] no running tasks\n"
+	}
+
+	zones := make([]string, 0, len(counts))
+	for zone := range counts {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	parts := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		parts = append(parts, fmt.Sprintf("%s: %d", tview.Escape(zone), counts[zone]))
+	}
+
+	text := fmt.Sprintf("\n[yellow]Tasks by Availability Zone:[-] %s\n", strings.Join(parts, ", "))
+	if len(zones) == 1 {
+		text += fmt.Sprintf("[red]All running tasks are in %s; an outage of that zone takes the service down[-]\n", tview.Escape(zones[0]))
+	}
+	return text
+}
+
+// targetHealthText lists running tasks that aren't healthy in their target
+// groups: ECS counts them as running, but the load balancer sends them no traffic
+func targetHealthText(results []pkg.TargetHealth, err error) string {
+	if err != nil {
+		return fmt.Sprintf("\n[yellow]Target Health:[-] [red]%s[-]\n", tview.Escape(err.Error()))
+	}
+	if len(results) == 0 {
+		return "\n[yellow]Target Health:[-] no awsvpc tasks to check\n"
+	}
+
+	var b strings.Builder
+	unhealthy := 0
+	for _, result := range results {
+		if result.State == "healthy" {
+			continue
+		}
+		if unhealthy == 0 {
+			b.WriteString("\n[red]Running tasks not healthy in their target group:[-]\n")
+		}
+		unhealthy++
+		fmt.Fprintf(&b, "  %s in %s: [red]%s[-]", tview.Escape(taskID(result.TaskArn)), tview.Escape(targetGroupName(result.TargetGroupArn)), tview.Escape(result.State))
+		if result.Reason != "" {
+			fmt.Fprintf(&b, " - %s", tview.Escape(result.Reason))
+		}
+		b.WriteString("\n")
+	}
+	if unhealthy == 0 {
+		return fmt.Sprintf("\n[yellow]Target Health:[-] [green]all %d registrations healthy[-]\n", len(results))
+	}
+	return b.String()
+}
+
+// targetGroupName returns the name from a target group ARN, which ends in
+// "targetgroup/<name>/<id>"
+func targetGroupName(arn string) string {
+	parts := strings.Split(arn, "/")
+	if len(parts) < 3 {
+		return arn
+	}
+	return parts[len(parts)-2]
+}
+
+func formatPercent(value *int64) string {
+	if value == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d%%", *value)
+}
+
+// joinOrNone lists values separated by commas, or "none" when there are none
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
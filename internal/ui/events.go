@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Service Events View
+// -------------------
+
+// eventsPanel shows a service's events, oldest first, and remembers which
+// events it has shown so that re-fetched events are only appended once.
+type eventsPanel struct {
+	view  *tview.TextView
+	title string
+	seen  map[string]bool
+}
+
+func newEventsPanel(service pkg.ServiceDetails) *eventsPanel {
+	p := &eventsPanel{
+		view: tview.NewTextView().
+			SetDynamicColors(true).
+			SetScrollable(true),
+		title: fmt.Sprintf(" Events for %s ", tview.Escape(service.ServiceName)),
+		seen:  make(map[string]bool),
+	}
+	p.view.SetBorder(true)
+	p.setFollowing(false)
+	return p
+}
+
+// appendEvents writes the events that haven't been shown yet and returns how
+// many were added
+func (p *eventsPanel) appendEvents(events []pkg.ServiceEvent) int {
+	added := 0
+	for _, event := range events {
+		if p.seen[event.ID] {
+			continue
+		}
+		p.seen[event.ID] = true
+		fmt.Fprint(p.view, eventLine(event))
+		added++
+	}
+	return added
+}
+
+func (p *eventsPanel) setFollowing(following bool) {
+	if following {
+		p.view.SetTitle(p.title + "[green](following)[-] ")
+		// Keep the newest events in view until the user scrolls up
+		p.view.ScrollToEnd()
+		return
+	}
+	p.view.SetTitle(p.title)
+}
+
+func eventLine(event pkg.ServiceEvent) string {
+	timestamp := "unknown time"
+	if event.CreatedAt != nil {
+		timestamp = event.CreatedAt.Local().Format(time.DateTime)
+	}
+	return fmt.Sprintf("[yellow]%s[-] %s\n", timestamp, tview.Escape(event.Message))
+}
+
+// showServiceEvents displays a service's events. Pressing f toggles follow
+// mode, which re-fetches the events on every poll and appends new ones.
+func showServiceEvents(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	events, err := aws.GetServiceEvents(ctx, ecsClient, service.Cluster, service.ServiceName)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to fetch service events: %v", err), layout)
+		return
+	}
+
+	panel := newEventsPanel(service)
+	if panel.appendEvents(events) == 0 {
+		fmt.Fprint(panel.view, "No recent events.\n")
+	}
+	panel.view.ScrollToEnd()
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("[yellow]f[-] - Follow | [yellow]End[-] - Jump to newest | [red]Esc[-] - Return")
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(panel.view, 0, 1, true).
+		AddItem(help, 1, 1, false)
+
+	var stopFollowing chan struct{}
+	follow := func() {
+		stop := make(chan struct{})
+		stopFollowing = stop
+		go func() {
+			ticker := time.NewTicker(PollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					events, err := aws.GetServiceEvents(ctx, ecsClient, service.Cluster, service.ServiceName)
+					if err != nil {
+						continue
+					}
+					app.QueueUpdateDraw(func() {
+						select {
+						case <-stop:
+							// Stopped while the fetch was in flight
+						default:
+							panel.appendEvents(events)
+						}
+					})
+				}
+			}
+		}()
+	}
+	unfollow := func() {
+		if stopFollowing != nil {
+			close(stopFollowing)
+			stopFollowing = nil
+		}
+	}
+
+	panel.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			unfollow()
+			app.SetRoot(layout, true)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'f' {
+				if stopFollowing == nil {
+					follow()
+				} else {
+					unfollow()
+				}
+				panel.setFollowing(stopFollowing != nil)
+				return nil
+			}
+		}
+		return event
+	})
+
+	app.SetRoot(view, true)
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Flapping Services
+// -----------------
+//
+// A crash-looping service keeps its ACTIVE status while its tasks keep
+// stopping and being replaced, so at any single poll it may look healthy.
+// Over the last FlapWindow polls, a service whose running count changes
+// direction at least FlapChanges times while its desired count stays the same
+// is flagged as flapping. Changes of the desired count, e.g. from scaling,
+// aren't counted, and neither are polls taken during a deployment, when a
+// rolling update adds and drains tasks.
+
+// MaxFlapWindow is the largest FlapWindow, as only that many polls are
+// remembered
+const MaxFlapWindow = historySize
+
+// FlapWindow is how many recent polls are looked at, at most MaxFlapWindow
+var FlapWindow = MaxFlapWindow
+
+// FlapChanges is how many times the running count must change direction
+// within FlapWindow polls for a service to be flagged. Zero disables the
+// check.
+var FlapChanges = 3
+
+// runningReversals counts how often the running count changed direction
+// across samples, ignoring pairs of samples whose desired count differs or
+// that were taken during a deployment
+func runningReversals(samples []countSample) int {
+	reversals, lastDirection := 0, 0
+	for i := 1; i < len(samples); i++ {
+		previous, current := samples[i-1], samples[i]
+		if current.desired != previous.desired || current.deploying || previous.deploying {
+			lastDirection = 0
+			continue
+		}
+		var direction int
+		switch {
+		case current.running > previous.running:
+			direction = 1
+		case current.running < previous.running:
+			direction = -1
+		default:
+			continue
+		}
+		if lastDirection != 0 && direction != lastDirection {
+			reversals++
+		}
+		lastDirection = direction
+	}
+	return reversals
+}
+
+// flapWindow returns the samples flapping is judged on
+func flapWindow(samples []countSample) []countSample {
+	if len(samples) > FlapWindow {
+		return samples[len(samples)-FlapWindow:]
+	}
+	return samples
+}
+
+// isFlapping reports whether a service's running count has been oscillating
+func (s *ServiceUI) isFlapping(service pkg.ServiceDetails) bool {
+	if FlapChanges <= 0 {
+		return false
+	}
+	return runningReversals(flapWindow(s.serviceHistory(service))) >= FlapChanges
+}
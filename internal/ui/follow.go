@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Deployment Follow Screen
+// ------------------------
+//
+// Tails a single service's deployment after a change: its deployment status,
+// running and desired counts, and new events, refreshed every FollowInterval
+// until the deployment is stable or has failed. Leaving the screen stops the
+// refreshes.
+
+// FollowInterval is how often the deployment follow screen refreshes
+var FollowInterval = 2 * time.Second
+
+// followUpdate is one refresh of the follow screen
+type followUpdate struct {
+	status  string
+	service pkg.ServiceDetails
+	events  []pkg.ServiceEvent
+	err     error
+}
+
+// showDeploymentFollow follows the deployment of a service until it settles,
+// returning to the list on Esc
+func showDeploymentFollow(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	followCtx, stop := context.WithCancel(ctx)
+
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(followStatusText(followUpdate{service: service}, 0))
+	status.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Deployment of %s ", tview.Escape(service.ServiceName)))
+
+	panel := newEventsPanel(service)
+	panel.setFollowing(true)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("[red]Esc[-] - Return")
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(status, 6, 0, false).
+		AddItem(panel.view, 0, 1, true).
+		AddItem(help, 1, 1, false)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			stop()
+			app.SetRoot(layout, true)
+			return nil
+		}
+		return event
+	})
+
+	started := time.Now()
+	go func() {
+		ticker := time.NewTicker(FollowInterval)
+		defer ticker.Stop()
+		for {
+			update := fetchFollowUpdate(followCtx, ecsClient, service)
+			if followCtx.Err() != nil {
+				return
+			}
+			done := isSettled(update.status)
+			app.QueueUpdateDraw(func() {
+				if followCtx.Err() != nil {
+					return
+				}
+				status.SetText(followStatusText(update, time.Since(started)))
+				panel.appendEvents(update.events)
+				panel.view.ScrollToEnd()
+				if done {
+					panel.setFollowing(false)
+					help.SetText("Deployment settled | [red]Esc[-] - Return")
+				}
+			})
+			if done {
+				return
+			}
+
+			select {
+			case <-followCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	app.SetRoot(view, true)
+}
+
+// fetchFollowUpdate fetches the service's deployment status, details and
+// events. Events that can't be fetched are left for the next refresh.
+func fetchFollowUpdate(ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails) followUpdate {
+	update := followUpdate{service: service}
+	update.status, update.err = aws.GetServiceDeploymentStatus(ctx, ecsClient, service.ServiceName, service.Cluster)
+	if update.err != nil {
+		return update
+	}
+	if details, err := aws.GetServiceDetails(ctx, ecsClient, service.ServiceName, service.Cluster); err == nil {
+		update.service = details
+	}
+	update.events, _ = aws.GetServiceEvents(ctx, ecsClient, service.Cluster, service.ServiceName)
+	return update
+}
+
+// isSettled reports whether a deployment status is final
+func isSettled(status string) bool {
+	switch aws.DeploymentState(status) {
+	case aws.DeploymentStateStable, aws.DeploymentStateFailed:
+		return true
+	}
+	return false
+}
+
+// followStatusText summarizes a followed deployment
+func followStatusText(update followUpdate, elapsed time.Duration) string {
+	var b strings.Builder
+	switch {
+	case update.err != nil:
+		fmt.Fprintf(&b, "[yellow]Status:[-] [red]%s[-] (retrying)\n", tview.Escape(update.err.Error()))
+	case update.status == "":
+		b.WriteString("[yellow]Status:[-] loading...\n")
+	default:
+		fmt.Fprintf(&b, "[yellow]Status:[-] %s\n", deploymentStatusText(update.status))
+	}
+
+	service := update.service
+	fmt.Fprintf(&b, "[yellow]Running / Desired:[-] %d / %d\n", service.RunningCount, service.DesiredCount)
+	rollout := "n/a"
+	for _, deployment := range service.Deployments {
+		if deployment.Status == "PRIMARY" && deployment.RolloutState != "" {
+			rollout = deployment.RolloutState
+			break
+		}
+	}
+	fmt.Fprintf(&b, "[yellow]Rollout State:[-] %s\n", tview.Escape(rollout))
+	fmt.Fprintf(&b, "[yellow]Following For:[-] %s", elapsed.Round(time.Second))
+	return b.String()
+}
+
+// deploymentStatusText colors a deployment status by its state
+func deploymentStatusText(status string) string {
+	switch aws.DeploymentState(status) {
+	case aws.DeploymentStateStable:
+		return "[green]" + tview.Escape(status) + "[-]"
+	case aws.DeploymentStateFailed:
+		return "[red]" + tview.Escape(status) + "[-]"
+	case aws.DeploymentStateDeploying:
+		return "[blue]" + tview.Escape(status) + "[-]"
+	}
+	return tview.Escape(status)
+}
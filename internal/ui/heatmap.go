@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Utilization Heatmap
+// -------------------
+//
+// Shows the listed services as a grid of cells colored by utilization, from
+// green when idle to red when saturated, so hotspots stand out across
+// hundreds of services. Cells are colored by the higher of CPU and memory
+// utilization, or by either one alone. Cached metrics are reused; services
+// without fresh metrics are fetched in the background, a few at a time.
+
+// heatmapCellWidth is the width of a cell, including its padding
+const heatmapCellWidth = 12
+
+// heatmapMetricsConcurrency bounds the CloudWatch calls the heatmap makes at
+// once for services without cached metrics
+const heatmapMetricsConcurrency = 10
+
+// Utilizations a heatmap can be colored by
+const (
+	heatmapPeak   = "peak"
+	heatmapCPU    = "CPU"
+	heatmapMemory = "memory"
+)
+
+// showHeatmap shows the listed services as a utilization heatmap
+func (s *ServiceUI) showHeatmap() {
+	if s.cwClient == nil {
+		s.showToast("Metrics are not available")
+		return
+	}
+	services := s.filteredServices
+	if len(services) == 0 {
+		return
+	}
+
+	_, _, width, _ := s.list.GetInnerRect()
+	columns := width / heatmapCellWidth
+	if columns < 1 {
+		columns = 8
+	}
+
+	mode := heatmapPeak
+	table := tview.NewTable().
+		SetSelectable(true, true)
+	table.SetBorder(true)
+
+	footer := tview.NewTextView().
+		SetDynamicColors(true)
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("Color by: [yellow]p[-] Peak | [yellow]c[-] CPU | [yellow]m[-] Memory | [blue]Enter[-] - Details | [red]Esc[-] - Return")
+
+	selected := func() (pkg.ServiceDetails, bool) {
+		row, column := table.GetSelection()
+		index := row*columns + column
+		if index >= len(services) {
+			return pkg.ServiceDetails{}, false
+		}
+		return services[index], true
+	}
+	describe := func() {
+		service, ok := selected()
+		if !ok {
+			footer.SetText("")
+			return
+		}
+		entry, ok := s.metrics[serviceKey(service)]
+		footer.SetText(heatmapCellDetails(service, entry, ok))
+	}
+	fill := func() {
+		table.SetTitle(fmt.Sprintf(" %s utilization of %d services (%s) ", mode, len(services), statisticLabel(s.statistic)))
+		table.Clear()
+		for i, service := range services {
+			entry, ok := s.metrics[serviceKey(service)]
+			var value *float64
+			if ok && !entry.unavailable {
+				value = heatmapValue(entry.values, mode)
+			}
+			table.SetCell(i/columns, i%columns, tview.NewTableCell(tview.Escape(abbreviate(service.ServiceName, heatmapCellWidth-2))).
+				SetExpansion(1).
+				SetAlign(tview.AlignCenter).
+				SetTextColor(tcell.ColorBlack).
+				SetBackgroundColor(utilizationColor(value)))
+		}
+		describe()
+	}
+	fill()
+	table.SetSelectionChangedFunc(func(row, column int) { describe() })
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(footer, 1, 0, false).
+		AddItem(help, 1, 0, false)
+
+	closed := false
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			closed = true
+			s.app.SetRoot(s.layout, true)
+			return nil
+		case tcell.KeyEnter:
+			if service, ok := selected(); ok {
+				closed = true
+				s.selectService(service.ServiceName, service.Cluster)
+				s.showSelectedDetails()
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'p':
+				mode = heatmapPeak
+			case 'c':
+				mode = heatmapCPU
+			case 'm':
+				mode = heatmapMemory
+			default:
+				return event
+			}
+			fill()
+			return nil
+		}
+		return event
+	})
+
+	s.loadMetricsInBackground(services, func() {
+		if !closed {
+			fill()
+		}
+	})
+	s.app.SetRoot(view, true)
+}
+
+// loadMetricsInBackground fetches, at most heatmapMetricsConcurrency at a
+// time, the metrics of services that have none cached or stale ones, calling
+// loaded on the UI goroutine after each is stored
+func (s *ServiceUI) loadMetricsInBackground(services []pkg.ServiceDetails, loaded func()) {
+	var missing []pkg.ServiceDetails
+	for _, service := range services {
+		key := serviceKey(service)
+		if entry, ok := s.metrics[key]; (ok && time.Since(entry.fetchedAt) < MetricsInterval) || s.metricsPending[key] {
+			continue
+		}
+		s.metricsPending[key] = true
+		missing = append(missing, service)
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	statistic := s.statistic
+	go func() {
+		sem := make(chan struct{}, heatmapMetricsConcurrency)
+		var wg sync.WaitGroup
+		for _, service := range missing {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(service pkg.ServiceDetails) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				metrics, err := aws.GetServiceMetrics(s.ctx, s.cwClient, service.Cluster, service.ServiceName, statistic)
+				s.app.QueueUpdateDraw(func() {
+					if s.discardStale(service, statistic) {
+						return
+					}
+					s.storeMetrics(service, metrics, err)
+					loaded()
+				})
+			}(service)
+		}
+		wg.Wait()
+	}()
+}
+
+// heatmapValue returns the utilization a cell is colored by, or nil if
+// CloudWatch had no datapoints for it
+func heatmapValue(metrics pkg.ServiceMetrics, mode string) *float64 {
+	switch mode {
+	case heatmapCPU:
+		return metrics.CPUUtilization
+	case heatmapMemory:
+		return metrics.MemoryUtilization
+	}
+	cpu, memory := metrics.CPUUtilization, metrics.MemoryUtilization
+	if cpu == nil || (memory != nil && *memory > *cpu) {
+		return memory
+	}
+	return cpu
+}
+
+// utilizationColor shades a utilization percentage from green at 0% through
+// yellow at 50% to red at 100% and above. Unknown utilization is gray.
+func utilizationColor(utilization *float64) tcell.Color {
+	if utilization == nil {
+		return tcell.ColorGray
+	}
+	fraction := *utilization / 100
+	fraction = max(0, min(fraction, 1))
+	if fraction < 0.5 {
+		return tcell.NewRGBColor(int32(fraction*2*255), 200, 0)
+	}
+	return tcell.NewRGBColor(255, int32((1-fraction)*2*200), 0)
+}
+
+// abbreviate shortens name to at most width characters, marking the cut
+// with an ellipsis
+func abbreviate(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width {
+		return name
+	}
+	if width < 2 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// heatmapCellDetails describes the selected cell below the grid
+func heatmapCellDetails(service pkg.ServiceDetails, entry metricsEntry, loaded bool) string {
+	text := fmt.Sprintf("[yellow]%s[-] in %s: ", tview.Escape(service.ServiceName), tview.Escape(aws.ClusterName(service.Cluster)))
+	switch {
+	case !loaded:
+		return text + "loading metrics..."
+	case entry.noAccess:
+		return text + "no metrics access"
+	case entry.unavailable:
+		return text + "metrics unavailable"
+	}
+	return text + fmt.Sprintf("CPU %s | Mem %s", formatUtilization(entry.values.CPUUtilization), formatUtilization(entry.values.MemoryUtilization))
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Running/Desired History
+// -----------------------
+//
+// Each poll's running and desired counts are kept in a small ring buffer per
+// service, so the detail view can show whether a service is scaling or
+// flapping without any extra AWS calls.
+
+// historySize is the number of polls remembered per service
+const historySize = 10
+
+type countSample struct {
+	running   int64
+	desired   int64
+	deploying bool
+}
+
+// countHistory is a fixed-size ring buffer of count samples
+type countHistory struct {
+	samples [historySize]countSample
+	next    int
+	size    int
+}
+
+func (h *countHistory) add(sample countSample) {
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % historySize
+	if h.size < historySize {
+		h.size++
+	}
+}
+
+// values returns the remembered samples, oldest first
+func (h *countHistory) values() []countSample {
+	values := make([]countSample, 0, h.size)
+	start := (h.next - h.size + historySize) % historySize
+	for i := 0; i < h.size; i++ {
+		values = append(values, h.samples[(start+i)%historySize])
+	}
+	return values
+}
+
+// recordHistory adds a sample for every service from a poll
+func (s *ServiceUI) recordHistory(services []pkg.ServiceDetails) {
+	for _, service := range services {
+		key := serviceKey(service)
+		history, ok := s.history[key]
+		if !ok {
+			history = &countHistory{}
+			s.history[key] = history
+		}
+		history.add(countSample{running: service.RunningCount, desired: service.DesiredCount, deploying: service.Deploying})
+	}
+}
+
+// serviceHistory returns the remembered samples of a service, oldest first
+func (s *ServiceUI) serviceHistory(service pkg.ServiceDetails) []countSample {
+	if history, ok := s.history[serviceKey(service)]; ok {
+		return history.values()
+	}
+	return nil
+}
+
+// countTrend formats counts as e.g. "2→3→3"
+func countTrend(samples []countSample, count func(countSample) int64) string {
+	values := make([]string, len(samples))
+	for i, sample := range samples {
+		values[i] = fmt.Sprintf("%d", count(sample))
+	}
+	return strings.Join(values, "→")
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Idle Timeout
+// ------------
+//
+// A session left open in a background tab keeps polling AWS. With an idle
+// timeout set, polling pauses after that long without a keypress or click,
+// and the next keypress or click resumes it with an immediate refresh.
+
+// SetIdleTimeout pauses polling after timeout without input. Zero disables
+// the timeout.
+func (s *ServiceUI) SetIdleTimeout(timeout time.Duration) {
+	s.idleTimeout = timeout
+	if timeout <= 0 {
+		return
+	}
+
+	s.lastInput = time.Now()
+	s.idleTimer = time.AfterFunc(timeout, func() {
+		s.app.QueueUpdateDraw(s.pauseIfIdle)
+	})
+	s.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		s.lastInput = time.Now()
+		if s.paused {
+			// The key only wakes the UI up, so it isn't acted on
+			s.resumePolling()
+			return nil
+		}
+		return event
+	})
+	s.app.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		if action == tview.MouseMove {
+			return event, action
+		}
+		s.lastInput = time.Now()
+		if s.paused && action == tview.MouseLeftClick {
+			s.resumePolling()
+			return nil, action
+		}
+		return event, action
+	})
+}
+
+// pauseIfIdle stops polling if there has been no input for idleTimeout, or
+// checks again once the timeout would be reached
+func (s *ServiceUI) pauseIfIdle() {
+	if s.paused {
+		return
+	}
+	if idle := time.Since(s.lastInput); idle < s.idleTimeout {
+		s.idleTimer.Reset(s.idleTimeout - idle)
+		return
+	}
+
+	s.paused = true
+	if s.stopPolling != nil {
+		s.stopPolling()
+	}
+	s.updateHeader()
+}
+
+// resumePolling restarts polling and refreshes right away rather than
+// waiting for the next poll
+func (s *ServiceUI) resumePolling() {
+	s.paused = false
+	s.updateHeader()
+	s.startPolling()
+	s.idleTimer.Reset(s.idleTimeout)
+
+	polled := s.polledServices
+	go func() {
+		updatedServices, deletedClusters := aws.FetchServiceUpdates(s.ctx, s.ecsClient, polled)
+		s.queueUpdateDraw(s.ctx, func() {
+			s.refreshServices(updatedServices)
+			s.forgetDeletedClusters(deletedClusters)
+		})
+	}()
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/rivo/tview"
+)
+
+// Container Instances
+// -------------------
+//
+// Tasks of EC2 launch type services run on container instances registered to
+// the cluster. The tasks view lists the instance each running task is placed
+// on, with its status and the CPU and memory it has left, to help diagnose
+// placement on constrained or draining instances.
+
+// constrainedFraction is the share of an instance's registered CPU or memory
+// below which what remains is highlighted
+const constrainedFraction = 0.1
+
+// loadContainerInstancesText fetches the container instances tasks run on,
+// and renders them
+func loadContainerInstancesText(ctx context.Context, ecsClient aws.ECSClientAPI, cluster string, tasks []pkg.TaskDetails) string {
+	arns := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		arns = append(arns, task.ContainerInstanceArn)
+	}
+	instances, err := aws.GetContainerInstances(ctx, ecsClient, cluster, arns)
+	return containerInstancesText(tasks, instances, err)
+}
+
+// containerInstancesText lists each running task's container instance
+func containerInstancesText(tasks []pkg.TaskDetails, instances map[string]pkg.ContainerInstance, err error) string {
+	if err != nil {
+		return fmt.Sprintf("\n[yellow]Container Instances:[-] [red]%s[-]\n", tview.Escape(err.Error()))
+	}
+
+	var b strings.Builder
+	for _, task := range tasks {
+		if task.LastStatus != "RUNNING" || task.ContainerInstanceArn == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s → ", tview.Escape(taskID(task.TaskArn)))
+		instance, ok := instances[task.ContainerInstanceArn]
+		if !ok {
+			fmt.Fprintf(&b, "%s [gray](not found)[-]\n", tview.Escape(taskID(task.ContainerInstanceArn)))
+			continue
+		}
+		b.WriteString(containerInstanceLine(instance))
+	}
+	if b.Len() == 0 {
+		return "\n[yellow]Container Instances:[-] no running tasks on container instances\n"
+	}
+	return "\n[yellow]Container Instances:[-]\n" + b.String()
+}
+
+func containerInstanceLine(instance pkg.ContainerInstance) string {
+	zone := instance.AvailabilityZone
+	if zone == "" {
+		zone = "unknown zone"
+	}
+	status := tview.Escape(instance.Status)
+	if instance.Status != "ACTIVE" {
+		status = "[red]" + status + "[-]"
+	}
+	line := fmt.Sprintf("%s (%s, %s", tview.Escape(instance.EC2InstanceID), tview.Escape(zone), status)
+	if !instance.AgentConnected {
+		line += ", [red]agent disconnected[-]"
+	}
+	return line + fmt.Sprintf("): %s CPU units and %s MiB free, %d tasks\n",
+		remainingAmount(instance.RemainingCPU, instance.RegisteredCPU),
+		remainingAmount(instance.RemainingMemoryMiB, instance.RegisteredMemoryMiB),
+		instance.RunningTasksCount)
+}
+
+// remainingAmount formats what an instance has left of a resource, in red
+// when little is left
+func remainingAmount(remaining, registered int64) string {
+	text := fmt.Sprintf("%d of %d", remaining, registered)
+	if registered > 0 && float64(remaining) < constrainedFraction*float64(registered) {
+		return "[red]" + text + "[-]"
+	}
+	return text
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Lazy Metrics Loading
+// --------------------
+//
+// Metrics are fetched from CloudWatch only for the services currently on
+// screen, so large fleets render immediately. Results are cached per service
+// and refetched on every metrics tick, or when scrolled into view once older
+// than MetricsInterval. The cache is only touched from the UI goroutine.
+// Utilization is read as the average over each period, or as the maximum to
+// show spikes; toggling refetches it, and results fetched for the statistic
+// toggled away from are discarded.
+
+// MetricsInterval is how often metrics are refreshed, independently of the
+// service list. CloudWatch only publishes ECS service metrics once a minute.
+var MetricsInterval = time.Minute
+
+// defaultVisibleItems is used before the list has been drawn and has a size
+const defaultVisibleItems = 20
+
+type metricsEntry struct {
+	values      pkg.ServiceMetrics
+	unavailable bool // CloudWatch timed out or denied access; shown as n/a until refetched
+	noAccess    bool // The service's cluster has no metrics
+	fetchedAt   time.Time
+}
+
+func serviceKey(service pkg.ServiceDetails) string {
+	return service.Cluster + "/" + service.ServiceName
+}
+
+func (s *ServiceUI) setupLazyMetrics() {
+	s.list.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		s.loadVisibleMetrics()
+		s.loadMoreIfNeeded()
+	})
+	s.loadVisibleMetrics()
+}
+
+// visibleServices returns the filtered services currently shown in the list
+func (s *ServiceUI) visibleServices() []pkg.ServiceDetails {
+	offset, _ := s.list.GetOffset()
+	_, _, _, height := s.list.GetInnerRect()
+	if height <= 0 {
+		height = defaultVisibleItems
+	}
+
+	start := offset
+	if current := s.list.GetCurrentItem(); current < start {
+		start = current
+	}
+	end := start + height
+	if end > len(s.filteredServices) {
+		end = len(s.filteredServices)
+	}
+	if start >= end {
+		return nil
+	}
+	return s.filteredServices[start:end]
+}
+
+// loadVisibleMetrics fetches metrics in the background for visible services
+// that have none cached, or whose cached metrics are stale. Nothing is fetched
+// while the list is too terse to show metrics.
+func (s *ServiceUI) loadVisibleMetrics() {
+	if s.cwClient == nil || s.verbosityRank() < verbosityRank(verbosityMetrics) {
+		return
+	}
+
+	for _, service := range s.visibleServices() {
+		key := serviceKey(service)
+		if entry, ok := s.metrics[key]; (ok && time.Since(entry.fetchedAt) < MetricsInterval) || s.metricsPending[key] {
+			continue
+		}
+
+		s.metricsPending[key] = true
+		statistic := s.statistic
+		go func(service pkg.ServiceDetails) {
+			metrics, err := aws.GetServiceMetrics(s.ctx, s.cwClient, service.Cluster, service.ServiceName, statistic)
+			s.app.QueueUpdateDraw(func() {
+				if s.discardStale(service, statistic) {
+					return
+				}
+				s.storeMetrics(service, metrics, err)
+			})
+		}(service)
+	}
+}
+
+// refreshMetrics marks every cached metric as stale and refetches the
+// visible ones; the rest are refetched when scrolled into view
+func (s *ServiceUI) refreshMetrics() {
+	for key, entry := range s.metrics {
+		entry.fetchedAt = time.Time{}
+		s.metrics[key] = entry
+	}
+	s.loadVisibleMetrics()
+}
+
+// refreshServiceMetrics refetches the metrics of a single service right away,
+// whatever their age, and confirms the result in the header
+func (s *ServiceUI) refreshServiceMetrics(service pkg.ServiceDetails) {
+	if s.cwClient == nil {
+		s.showToast("Metrics are not available")
+		return
+	}
+	key := serviceKey(service)
+	if s.metricsPending[key] {
+		s.showToast(fmt.Sprintf("Still loading metrics for %s", service.ServiceName))
+		return
+	}
+
+	s.metricsPending[key] = true
+	s.showToast(fmt.Sprintf("Refreshing metrics for %s...", service.ServiceName))
+	statistic := s.statistic
+	go func() {
+		metrics, err := aws.GetServiceMetrics(s.ctx, s.cwClient, service.Cluster, service.ServiceName, statistic)
+		s.app.QueueUpdateDraw(func() {
+			if s.discardStale(service, statistic) {
+				return
+			}
+			s.storeMetrics(service, metrics, err)
+			switch {
+			case errors.Is(err, aws.ErrMetricsUnavailable):
+				s.showToast(fmt.Sprintf("Metrics for %s are unavailable", service.ServiceName))
+			case errors.Is(err, aws.ErrMetricsAccessDenied):
+				s.showToast(fmt.Sprintf("No metrics access in cluster %s", aws.ClusterName(service.Cluster)))
+			case err != nil:
+				s.showToast(fmt.Sprintf("Error refreshing metrics for %s: %v", service.ServiceName, err))
+			default:
+				s.showToast(fmt.Sprintf("%s CPU: %s | Mem: %s", service.ServiceName, formatUtilization(metrics.CPUUtilization), formatUtilization(metrics.MemoryUtilization)))
+			}
+		})
+	}()
+}
+
+// toggleStatistic switches utilization between the average and the maximum
+// over each period and refetches it
+func (s *ServiceUI) toggleStatistic() {
+	if s.statistic == aws.StatisticMaximum {
+		s.statistic = aws.StatisticAverage
+	} else {
+		s.statistic = aws.StatisticMaximum
+	}
+	s.showToast(fmt.Sprintf("Showing %s utilization", strings.ToLower(statisticLabel(s.statistic))))
+	s.refreshMetrics()
+}
+
+// discardStale drops metrics fetched with a statistic that has since been
+// toggled away from, and fetches them again if still visible
+func (s *ServiceUI) discardStale(service pkg.ServiceDetails, statistic aws.Statistic) bool {
+	if statistic == s.statistic {
+		return false
+	}
+	delete(s.metricsPending, serviceKey(service))
+	s.loadVisibleMetrics()
+	return true
+}
+
+func statisticLabel(statistic aws.Statistic) string {
+	if statistic == aws.StatisticMaximum {
+		return "Maximum"
+	}
+	return "Average"
+}
+
+// storeMetrics caches fetched metrics and updates the service's row in place,
+// so the selection isn't disturbed.
+func (s *ServiceUI) storeMetrics(service pkg.ServiceDetails, metrics pkg.ServiceMetrics, err error) {
+	key := serviceKey(service)
+	delete(s.metricsPending, key)
+	switch {
+	case errors.Is(err, aws.ErrMetricsUnavailable):
+		s.metrics[key] = metricsEntry{unavailable: true, fetchedAt: time.Now()}
+	case errors.Is(err, aws.ErrMetricsAccessDenied):
+		s.metrics[key] = metricsEntry{unavailable: true, noAccess: true, fetchedAt: time.Now()}
+		s.noteNoMetricsCluster(service.Cluster)
+	case err != nil:
+		return
+	default:
+		s.metrics[key] = metricsEntry{values: metrics, fetchedAt: time.Now()}
+	}
+
+	for i, filtered := range s.filteredServices {
+		if serviceKey(filtered) == key && i < s.list.GetItemCount() {
+			s.list.SetItemText(i, s.serviceItemText(filtered), "")
+			break
+		}
+	}
+}
+
+// noteNoMetricsCluster tells the user, once per cluster, that its services
+// show n/a because it has no metrics, e.g. after CloudWatch denied access
+func (s *ServiceUI) noteNoMetricsCluster(cluster string) {
+	name := aws.ClusterName(cluster)
+	if s.noMetricsNoted[name] {
+		return
+	}
+	s.noMetricsNoted[name] = true
+	s.showToast(fmt.Sprintf("No metrics access in cluster %s; its services show n/a", name))
+}
+
+// formatUtilization shows a utilization percentage, or n/a when CloudWatch
+// had no datapoints for it
+func formatUtilization(utilization *float64) string {
+	if utilization == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%%", *utilization)
+}
+
+// attachMetrics sets the cached metrics on each service that has them
+func (s *ServiceUI) attachMetrics(services []pkg.ServiceDetails) {
+	for i := range services {
+		if entry, ok := s.metrics[serviceKey(services[i])]; ok && !entry.unavailable {
+			services[i].SetMetrics(entry.values)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package ui
+
+// Holding the List Under Modals
+// -----------------------------
+//
+// Confirmations, detail views and other screens act on the service that was
+// selected when they opened. Polled updates re-sort and re-filter the list,
+// so re-listing behind an open screen could move the service out from under
+// the action. Polled services keep being applied, so history, deployment
+// tracking and refresh hooks stay current, but while anything other than the
+// list is shown the list itself is left as it is, and re-listed as soon as it
+// is back.
+
+// setupModalTracking tracks whether the list is on screen. Every screen that
+// covers it takes focus away from the list and search input, and returning
+// to the list gives one of them focus again.
+func (s *ServiceUI) setupModalTracking() {
+	covered := func() { s.setModalOpen(true) }
+	shown := func() { s.setModalOpen(false) }
+	s.list.SetBlurFunc(covered)
+	s.list.SetFocusFunc(shown)
+	s.searchInput.SetBlurFunc(covered)
+	s.searchInput.SetFocusFunc(shown)
+}
+
+// setModalOpen records whether a modal or other screen covers the list, and
+// re-lists the services refreshed meanwhile once the list is shown again
+func (s *ServiceUI) setModalOpen(open bool) {
+	s.modalOpen = open
+	if !open && s.listStale {
+		s.listStale = false
+		s.relist()
+	}
+}
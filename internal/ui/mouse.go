@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Mouse Support
+// -------------
+//
+// A click selects a service and a double-click opens its details. The list
+// would otherwise open the service options on every click, which is the
+// Enter key's job. The search input and modals handle the mouse themselves.
+
+// MouseEnabled turns on mouse support. Disabling it leaves text selection
+// to the terminal.
+var MouseEnabled = true
+
+func (s *ServiceUI) setupMouse() {
+	if !MouseEnabled {
+		return
+	}
+	s.app.EnableMouse(true)
+
+	s.list.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action != tview.MouseLeftClick && action != tview.MouseLeftDoubleClick {
+			return action, event
+		}
+		index := s.listIndexAt(event.Position())
+		if index < 0 {
+			return action, event
+		}
+
+		s.app.SetFocus(s.list)
+		s.list.SetCurrentItem(index)
+		if action == tview.MouseLeftDoubleClick {
+			s.showSelectedDetails()
+		}
+		return action, nil
+	})
+}
+
+// listItemHeight is the number of lines each service takes in the list: its
+// text and the list's empty secondary line
+const listItemHeight = 2
+
+// listIndexAt returns the index of the service listed at screen position x, y,
+// or -1 if there is none
+func (s *ServiceUI) listIndexAt(x, y int) int {
+	if !s.list.InRect(x, y) {
+		return -1
+	}
+	_, top, _, height := s.list.GetInnerRect()
+	if y < top || y >= top+height {
+		return -1
+	}
+	offset, _ := s.list.GetOffset()
+	index := (y-top)/listItemHeight + offset
+	if index >= s.list.GetItemCount() {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Muted Services
+// --------------
+//
+// Muted services are left out of the unhealthy count, e.g. while they are
+// scaled to zero for maintenance, but are still listed with a marker. Mutes
+// are stored in the state file by cluster and service name.
+
+func (s *ServiceUI) isMuted(service pkg.ServiceDetails) bool {
+	key := serviceKey(service)
+	for _, muted := range s.state.MutedServices {
+		if muted == key {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleMute mutes or unmutes a service and persists the change
+func (s *ServiceUI) toggleMute(service pkg.ServiceDetails) {
+	key := serviceKey(service)
+	if s.isMuted(service) {
+		var mutes []string
+		for _, muted := range s.state.MutedServices {
+			if muted != key {
+				mutes = append(mutes, muted)
+			}
+		}
+		s.state.MutedServices = mutes
+		s.showToast("Unmuted " + service.ServiceName)
+	} else {
+		s.state.MutedServices = append(s.state.MutedServices, key)
+		s.showToast("Muted " + service.ServiceName)
+	}
+	s.saveState()
+
+	s.filterServices(s.searchInput.GetText())
+	s.selectService(service.ServiceName, service.Cluster)
+}
+
+// unmuted returns the services that are not muted
+func (s *ServiceUI) unmuted(services []pkg.ServiceDetails) []pkg.ServiceDetails {
+	var result []pkg.ServiceDetails
+	for _, service := range services {
+		if !s.isMuted(service) {
+			result = append(result, service)
+		}
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// One-shot Rendering
+// ------------------
+//
+// RenderOnce draws the service list as the interactive UI would, with the
+// saved group filter, pins and verbosity, onto an off-screen buffer and
+// writes it as ANSI-colored text, for status dashboards and scripts.
+
+// onceMetricsConcurrency bounds the CloudWatch calls made before rendering
+const onceMetricsConcurrency = 10
+
+// RenderOnce writes a single frame of the service list, width columns wide,
+// to w. loadErr is shown in the header as it would be in the UI. Metrics are
+// fetched first when cwClient is set and the list shows them.
+func RenderOnce(ctx context.Context, w io.Writer, cwClient aws.CloudWatchClientAPI, services []pkg.ServiceDetails, loadErr error, width int) error {
+	// Draw on the terminal's own background rather than black
+	defaultBackground := tview.Styles.PrimitiveBackgroundColor
+	tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+	defer func() { tview.Styles.PrimitiveBackgroundColor = defaultBackground }()
+
+	s := NewServiceUI(tview.NewApplication(), ctx, nil, cwClient, services)
+	s.loadState()
+	s.loadConfig()
+	s.loadError = loadErr
+	s.searchInput.SetFieldBackgroundColor(tcell.ColorDefault)
+	// Nothing is selected in a snapshot
+	s.list.SetSelectedFocusOnly(true)
+	s.filterServices("")
+
+	if cwClient != nil && s.verbosityRank() >= verbosityRank(verbosityMetrics) {
+		s.fetchAllMetrics()
+		s.updateList()
+	}
+
+	// Top bar, search line, the services, the cost footer if shown, and the
+	// legend
+	height := 6 + 1 + listItemHeight*len(s.filteredServices) + 1
+	if s.costFooter.GetText(false) != "" {
+		height++
+	}
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("error initializing screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	s.layout.SetRect(0, 0, width, height)
+	s.layout.Draw(screen)
+	return writeANSI(w, screen, width, height)
+}
+
+// fetchAllMetrics loads the metrics of every listed service into the cache,
+// waiting for all of them
+func (s *ServiceUI) fetchAllMetrics() {
+	type result struct {
+		service pkg.ServiceDetails
+		metrics pkg.ServiceMetrics
+		err     error
+	}
+	results := make([]result, len(s.filteredServices))
+	sem := make(chan struct{}, onceMetricsConcurrency)
+	var wg sync.WaitGroup
+	for i, service := range s.filteredServices {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, service pkg.ServiceDetails) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			metrics, err := aws.GetServiceMetrics(s.ctx, s.cwClient, service.Cluster, service.ServiceName, s.statistic)
+			results[i] = result{service: service, metrics: metrics, err: err}
+		}(i, service)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		s.storeMetrics(r.service, r.metrics, r.err)
+	}
+}
+
+// writeANSI writes the screen's contents as text, switching colors and
+// attributes with ANSI escape sequences where the style changes. Trailing
+// blank cells of each line are dropped.
+func writeANSI(w io.Writer, screen tcell.SimulationScreen, width, height int) error {
+	out := bufio.NewWriter(w)
+	for y := 0; y < height; y++ {
+		end := width
+		for end > 0 {
+			mainc, _, style, _ := screen.GetContent(end-1, y)
+			if _, bg, _ := style.Decompose(); (mainc != ' ' && mainc != 0) || bg != tcell.ColorDefault {
+				break
+			}
+			end--
+		}
+
+		current := tcell.StyleDefault
+		for x := 0; x < end; x++ {
+			mainc, combc, style, cellWidth := screen.GetContent(x, y)
+			if style != current {
+				out.WriteString(sgr(style))
+				current = style
+			}
+			if mainc == 0 {
+				mainc = ' '
+			}
+			out.WriteRune(mainc)
+			for _, r := range combc {
+				out.WriteRune(r)
+			}
+			if cellWidth > 1 {
+				x += cellWidth - 1
+			}
+		}
+		if current != tcell.StyleDefault {
+			out.WriteString("\x1b[0m")
+		}
+		out.WriteString("\n")
+	}
+	return out.Flush()
+}
+
+// sgr returns the escape sequence that resets the terminal style and then
+// selects style's colors and attributes
+func sgr(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	codes := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		codes = append(codes, "2")
+	}
+	if attrs&tcell.AttrItalic != 0 {
+		codes = append(codes, "3")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		codes = append(codes, "4")
+	}
+	if attrs&tcell.AttrReverse != 0 {
+		codes = append(codes, "7")
+	}
+	if r, g, b := fg.RGB(); r >= 0 {
+		codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+	}
+	if r, g, b := bg.RGB(); r >= 0 {
+		codes = append(codes, fmt.Sprintf("48;2;%d;%d;%d", r, g, b))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
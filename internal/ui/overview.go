@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/summary"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Cluster Overview
+// ----------------
+
+// overviewSortKeys maps the keys that sort the overview to their columns
+var overviewSortKeys = map[rune]string{
+	'n': summary.SortByName,
+	's': summary.SortByServices,
+	'r': summary.SortByRunning,
+	'd': summary.SortByDesired,
+}
+
+// showClusterOverview shows the service and task totals of every cluster
+func showClusterOverview(app *tview.Application, services []pkg.ServiceDetails, layout *tview.Flex) {
+	summaries := summary.Summarize(services)
+
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(" Cluster overview ")
+	fillOverviewTable(table, summaries, summary.SortByName)
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetText("Sort by: [yellow]n[-] Name | [yellow]s[-] Services | [yellow]r[-] Running | [yellow]d[-] Desired | [red]Esc[-] - Return")
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 1, false)
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			app.SetRoot(layout, true)
+			return nil
+		case tcell.KeyRune:
+			if key, ok := overviewSortKeys[event.Rune()]; ok {
+				fillOverviewTable(table, summaries, key)
+				return nil
+			}
+		}
+		return event
+	})
+
+	app.SetRoot(view, true)
+}
+
+// fillOverviewTable sorts the summaries and renders them with a totals row.
+// The header of the sorted column is highlighted.
+func fillOverviewTable(table *tview.Table, summaries []summary.ClusterSummary, sortKey string) {
+	summary.Sort(summaries, sortKey)
+	table.Clear()
+
+	headers := []struct {
+		title string
+		key   string
+	}{
+		{"Cluster", summary.SortByName},
+		{"Services", summary.SortByServices},
+		{"Running", summary.SortByRunning},
+		{"Desired", summary.SortByDesired},
+	}
+	for col, header := range headers {
+		color := tcell.ColorYellow
+		if header.key == sortKey {
+			color = tcell.ColorGreen
+		}
+		table.SetCell(0, col, tview.NewTableCell(header.title).
+			SetTextColor(color).
+			SetSelectable(false))
+	}
+
+	for i, s := range summaries {
+		row := i + 1
+		table.SetCell(row, 0, tview.NewTableCell(tview.Escape(aws.ClusterName(s.Cluster))))
+		table.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", s.Services)).SetAlign(tview.AlignRight))
+		table.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%d", s.RunningCount)).SetAlign(tview.AlignRight))
+		table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%d", s.DesiredCount)).SetAlign(tview.AlignRight))
+	}
+
+	totals := summary.Totals(summaries)
+	row := len(summaries) + 1
+	table.SetCell(row, 0, tview.NewTableCell("Total").SetSelectable(false))
+	table.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf("%d", totals.Services)).SetAlign(tview.AlignRight).SetSelectable(false))
+	table.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf("%d", totals.RunningCount)).SetAlign(tview.AlignRight).SetSelectable(false))
+	table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf("%d", totals.DesiredCount)).SetAlign(tview.AlignRight).SetSelectable(false))
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Paged Loading
+// -------------
+//
+// With a pager set, only the first page of services is loaded before the UI
+// starts. The next page is loaded in the background whenever the selection
+// comes within pageAhead rows of the end of the list, including when a
+// search or group filter leaves only a few rows, so filters keep covering
+// services as they load. Loaded services join the polled set.
+
+// pageAhead is how close to the end of the list the selection gets before
+// the next page is loaded
+const pageAhead = 10
+
+// SetPager loads further services from pager as the list is scrolled. The
+// pager must have loaded the initial services, and is only used from one
+// goroutine at a time.
+func (s *ServiceUI) SetPager(pager *aws.ServicePager) {
+	s.pager = pager
+	s.morePages = pager.HasMore()
+	s.updateHeader()
+	s.loadMoreIfNeeded()
+}
+
+// loadMoreIfNeeded loads the next page when the selection is near the end
+// of the list and no page is loading already
+func (s *ServiceUI) loadMoreIfNeeded() {
+	if s.pager == nil || !s.morePages || s.pageLoading {
+		return
+	}
+	if s.list.GetItemCount()-s.list.GetCurrentItem() > pageAhead {
+		return
+	}
+
+	s.pageLoading = true
+	s.updateHeader()
+	pager := s.pager
+	go func() {
+		services, err := pager.NextPage(s.ctx)
+		s.app.QueueUpdateDraw(func() {
+			s.pageLoading = false
+			s.morePages = pager.HasMore()
+			s.addPage(services, err)
+		})
+	}()
+}
+
+// addPage adds a loaded page to the list and to the polled services,
+// keeping the selection, then loads another page if still needed
+func (s *ServiceUI) addPage(services []pkg.ServiceDetails, err error) {
+	if err != nil {
+		s.loadError = mergeLoadErrors(s.loadError, err)
+	}
+
+	if len(services) > 0 {
+		all := append(append([]pkg.ServiceDetails(nil), s.currentServices...), services...)
+		aws.SortServices(all)
+		s.currentServices = all
+		polled := append(append([]pkg.ServiceDetails(nil), s.polledServices...), services...)
+		aws.SortServices(polled)
+		s.polledServices = polled
+
+		s.recordHistory(services)
+		// Like polled updates, a page loaded behind an open screen is only
+		// listed once the list is shown again
+		if s.modalOpen {
+			s.listStale = true
+		} else {
+			s.relist()
+		}
+
+		s.restartPolling()
+	}
+
+	s.updateHeader()
+	s.loadMoreIfNeeded()
+}
+
+// mergeLoadErrors combines the clusters that failed to load across pages
+func mergeLoadErrors(existing, err error) error {
+	var added aws.ClusterErrors
+	if !errors.As(err, &added) {
+		return err
+	}
+	merged := aws.ClusterErrors{}
+	var previous aws.ClusterErrors
+	if errors.As(existing, &previous) {
+		for cluster, clusterErr := range previous {
+			merged[cluster] = clusterErr
+		}
+	}
+	for cluster, clusterErr := range added {
+		merged[cluster] = clusterErr
+	}
+	return merged
+}
+
+// pagingText describes services still to be loaded, for the header
+func (s *ServiceUI) pagingText() string {
+	switch {
+	case s.pageLoading:
+		return " | [yellow]Loading more services...[-]"
+	case s.morePages:
+		return " | [yellow]More services load as you scroll[-]"
+	}
+	return ""
+}
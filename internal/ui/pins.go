@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"sort"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Pinned Services
+// ---------------
+//
+// Pinned services are listed above all others, whatever the filter or sort.
+// Pins are stored in the state file by cluster and service name.
+
+func (s *ServiceUI) isPinned(service pkg.ServiceDetails) bool {
+	key := serviceKey(service)
+	for _, pinned := range s.state.PinnedServices {
+		if pinned == key {
+			return true
+		}
+	}
+	return false
+}
+
+// togglePin pins or unpins a service, persists the change, and keeps the
+// service selected as it moves in the list
+func (s *ServiceUI) togglePin(service pkg.ServiceDetails) {
+	key := serviceKey(service)
+	if s.isPinned(service) {
+		var pins []string
+		for _, pinned := range s.state.PinnedServices {
+			if pinned != key {
+				pins = append(pins, pinned)
+			}
+		}
+		s.state.PinnedServices = pins
+	} else {
+		s.state.PinnedServices = append(s.state.PinnedServices, key)
+	}
+	s.saveState()
+
+	s.filterServices(s.searchInput.GetText())
+	s.selectService(service.ServiceName, service.Cluster)
+}
+
+// pinnedFirst returns a copy of services with pinned services moved to the
+// top, otherwise keeping their order
+func (s *ServiceUI) pinnedFirst(services []pkg.ServiceDetails) []pkg.ServiceDetails {
+	ordered := append([]pkg.ServiceDetails(nil), services...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return s.isPinned(ordered[i]) && !s.isPinned(ordered[j])
+	})
+	return ordered
+}
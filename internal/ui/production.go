@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Production Guardrails
+// ---------------------
+//
+// Accounts and profiles marked as production in the config file get a red
+// banner across the top of the UI. Every action that changes services must
+// then be confirmed by typing "yes" before its usual prompt is shown. The
+// account is read from the ARNs of the listed clusters.
+
+// SetProfile sets the name of the AWS profile in use, so the UI can tell
+// whether it is marked as production
+func (s *ServiceUI) SetProfile(profile string) {
+	s.profile = profile
+	s.updateProductionBanner()
+}
+
+// productionLabel describes what marks the session as production, e.g.
+// "account 123456789012, profile prod", or is empty when nothing does
+func (s *ServiceUI) productionLabel() string {
+	return s.config.ProductionLabel(aws.AccountIDs(s.currentServices), s.profile)
+}
+
+// updateProductionBanner shows the banner when the session is production
+// and collapses it otherwise
+func (s *ServiceUI) updateProductionBanner() {
+	label := s.productionLabel()
+	if label == "" {
+		s.banner.SetText("")
+		s.layout.ResizeItem(s.banner, 0, 0)
+		return
+	}
+	s.banner.SetText(fmt.Sprintf("PRODUCTION — %s — changes must be confirmed by typing yes", tview.Escape(label)))
+	s.layout.ResizeItem(s.banner, 1, 0)
+}
+
+// guardProduction runs proceed straight away outside production. In
+// production, it first asks for "yes" to be typed to confirm the action,
+// described e.g. as "restart the listed services".
+func (s *ServiceUI) guardProduction(action string, proceed func()) {
+	label := s.productionLabel()
+	if label == "" {
+		proceed()
+		return
+	}
+
+	message := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("[white:red] PRODUCTION [-:-] You are about to %s in %s.\n\nType yes to continue, or press Esc to cancel.", tview.Escape(action), tview.Escape(label)))
+	inputField := tview.NewInputField().
+		SetLabel("Confirm: ")
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			s.app.SetRoot(s.layout, true)
+			return
+		}
+		if !strings.EqualFold(strings.TrimSpace(inputField.GetText()), "yes") {
+			showMessage(s.app, "Confirmation did not match; nothing was changed.", s.layout)
+			return
+		}
+		s.app.SetRoot(s.layout, true)
+		proceed()
+	})
+
+	form := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(message, 0, 1, false).
+		AddItem(inputField, 1, 0, true)
+	form.SetBorder(true).SetBorderColor(tcell.ColorRed).SetTitle(" Confirm production change ")
+
+	s.app.SetRoot(form, true)
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Quick Scaling
+// -------------
+//
+// The + and - keys change the selected service's desired count by one
+// without a prompt. Only one change per service is in flight at a time, so
+// quick repeated presses can't be applied out of order.
+
+// adjustDesiredCount changes a service's desired count by delta in the
+// background, reporting the outcome in a toast
+func (s *ServiceUI) adjustDesiredCount(service pkg.ServiceDetails, delta int64) {
+	key := serviceKey(service)
+	newCount := service.DesiredCount + delta
+	switch {
+	case service.IsDaemon():
+		s.showToast(fmt.Sprintf("%s is a daemon service; its desired count follows the instance count", service.ServiceName))
+		return
+	case newCount < 0:
+		s.showToast(fmt.Sprintf("%s is already at a desired count of 0", service.ServiceName))
+		return
+	case s.scalePending[key]:
+		s.showToast(fmt.Sprintf("Still updating %s, try again in a moment", service.ServiceName))
+		return
+	}
+
+	s.scalePending[key] = true
+	go func() {
+		err := s.updateDesiredCountWithinBounds(service, newCount)
+		s.app.QueueUpdateDraw(func() {
+			delete(s.scalePending, key)
+			if err != nil {
+				s.showToast(err.Error())
+				return
+			}
+			s.setDesiredCount(service, newCount)
+			s.showToast(fmt.Sprintf("%s desired count %d → %d", service.ServiceName, service.DesiredCount, newCount))
+		})
+	}()
+}
+
+// updateDesiredCountWithinBounds updates the desired count unless the service
+// is auto-scaled and the count is outside its bounds
+func (s *ServiceUI) updateDesiredCountWithinBounds(service pkg.ServiceDetails, desiredCount int64) error {
+	if s.scalingClient != nil {
+		bounds, err := aws.GetScalingBounds(s.ctx, s.scalingClient, service.Cluster, service.ServiceName)
+		if err == nil && bounds != nil && !bounds.Contains(desiredCount) {
+			return fmt.Errorf("%s is auto-scaled between %d and %d tasks", service.ServiceName, bounds.MinCapacity, bounds.MaxCapacity)
+		}
+	}
+	return aws.UpdateServiceDesiredCount(s.ctx, s.ecsClient, service.ServiceName, service.Cluster, desiredCount)
+}
+
+// setDesiredCount records a new desired count until the next poll confirms
+// it, updating the service's row in place so the selection isn't disturbed
+func (s *ServiceUI) setDesiredCount(service pkg.ServiceDetails, desiredCount int64) {
+	key := serviceKey(service)
+	s.currentServices = withDesiredCount(s.currentServices, key, desiredCount)
+	s.filteredServices = withDesiredCount(s.filteredServices, key, desiredCount)
+	for i, filtered := range s.filteredServices {
+		if serviceKey(filtered) == key && i < s.list.GetItemCount() {
+			s.list.SetItemText(i, s.serviceItemText(filtered), "")
+		}
+	}
+	s.updateHeader()
+}
+
+// withDesiredCount returns a copy of services in which the service with key
+// has desiredCount. The original may be shared with other goroutines, so it
+// isn't modified.
+func withDesiredCount(services []pkg.ServiceDetails, key string, desiredCount int64) []pkg.ServiceDetails {
+	updated := append([]pkg.ServiceDetails(nil), services...)
+	for i := range updated {
+		if serviceKey(updated[i]) == key {
+			updated[i].DesiredCount = desiredCount
+		}
+	}
+	return updated
+}
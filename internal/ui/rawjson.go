@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Raw Service JSON
+// ----------------
+//
+// Shows everything DescribeServices returns for a service, for debugging and
+// for attaching to AWS support tickets without reaching for the AWS CLI.
+
+// showServiceJSON fetches the service and shows it as JSON in a scrollable
+// view, returning to the list on Esc
+func showServiceJSON(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	text, err := aws.GetServiceJSON(ctx, ecsClient, service.Cluster, service.ServiceName)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to describe service: %v", err), layout)
+		return
+	}
+
+	view := tview.NewTextView().
+		SetScrollable(true).
+		SetText(text)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" DescribeServices: %s (Esc to return) ", tview.Escape(service.ServiceName)))
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc {
+			app.SetRoot(layout, true)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(view, true)
+}
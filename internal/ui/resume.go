@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/restartprogress"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/rivo/tview"
+)
+
+// Resuming Restarts
+// -----------------
+//
+// Bulk restarts record the services not restarted yet in a progress file.
+// Services that fail can be retried straight away, and if bw-cli quits or
+// crashes mid-restart, the next launch offers to resume the pending ones.
+
+// maxListedServices caps how many service names are spelled out in a prompt
+const maxListedServices = 10
+
+// retryRestartFunc restarts services that failed to restart, once the retry
+// has been confirmed like the restart itself
+type retryRestartFunc func(failed []pkg.ServiceDetails, progress *restartprogress.Progress)
+
+// showRestartFailures lists the services that failed to restart and offers
+// to retry them. They stay pending either way.
+func showRestartFailures(app *tview.Application, failed []pkg.ServiceDetails, progress *restartprogress.Progress, layout *tview.Flex, retry retryRestartFunc) {
+	names := make([]string, 0, len(failed))
+	for _, service := range failed {
+		names = append(names, service.ServiceName)
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Failed to restart %d service(s):\n\n%s\n\nRetry them now? Otherwise they are offered again on the next launch.", len(failed), listedNames(names))).
+		AddButtons([]string{"Retry", "Later"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.SetRoot(layout, true)
+			if buttonLabel == "Retry" {
+				retry(failed, progress)
+			}
+		})
+	app.SetRoot(modal, false)
+}
+
+// offerRestartResume offers to resume a bulk restart that was interrupted in
+// an earlier session, or to discard it. Pending services that are no longer
+// listed are reported and can't be resumed.
+func (s *ServiceUI) offerRestartResume() {
+	if progress := unfinishedRestart(); progress != nil {
+		s.showRestartResume(progress, false)
+	}
+}
+
+// unfinishedRestart returns the progress of a bulk restart that still has
+// services pending, or nil if there is none
+func unfinishedRestart() *restartprogress.Progress {
+	path, err := restartprogress.DefaultPath()
+	if err != nil {
+		return nil
+	}
+	progress, err := restartprogress.Load(path)
+	if err != nil || len(progress.PendingKeys()) == 0 {
+		return nil
+	}
+	return progress
+}
+
+// showRestartResume offers to resume or discard an unfinished restart.
+// starting is set when the user was about to start another restart, which
+// has to wait until this one is resumed or discarded.
+func (s *ServiceUI) showRestartResume(progress *restartprogress.Progress, starting bool) {
+	pending, missing := progress.PendingServices(s.currentServices)
+	text := restartResumeText(progress.StartedAt, pending, missing)
+	if starting {
+		text = "Resume or discard the unfinished restart before starting another.\n\n" + text
+	}
+
+	buttons := []string{"Resume", "Discard", "Later"}
+	if len(pending) == 0 {
+		buttons = buttons[1:]
+	}
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			s.app.SetRoot(s.layout, true)
+			switch buttonLabel {
+			case "Resume":
+				s.guardProduction("resume restarting services", func() {
+					showBulkConfirm(s.app, fmt.Sprintf("Restart the %d pending services?", len(pending)), len(pending), s.config, func() {
+						resumeRestart(s.app, s.ctx, s.ecsClient, pending, progress, s.layout, s.retryRestart)
+					}, s.layout)
+				})
+			case "Discard":
+				if err := progress.Clear(); err != nil {
+					showMessage(s.app, fmt.Sprintf("Failed to discard restart progress: %v", err), s.layout)
+				}
+			}
+		})
+	s.app.SetRoot(modal, false)
+}
+
+// resumeRestart restarts the pending services of an unfinished restart,
+// forgetting the pending services that are no longer listed
+func resumeRestart(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, pending []pkg.ServiceDetails, progress *restartprogress.Progress, layout *tview.Flex, retry retryRestartFunc) {
+	if err := progress.Resume(pending); err != nil {
+		showMessage(app, fmt.Sprintf("Failed to save restart progress: %v", err), layout)
+		return
+	}
+	go restartAllServices(app, ctx, ecsClient, pending, progress, layout, retry)
+}
+
+// retryRestart retries the services that failed to restart after the same
+// production and bulk confirmations as starting a restart
+func (s *ServiceUI) retryRestart(failed []pkg.ServiceDetails, progress *restartprogress.Progress) {
+	s.guardProduction("retry restarting services", func() {
+		showBulkConfirm(s.app, fmt.Sprintf("Restart the %d failed services again?", len(failed)), len(failed), s.config, func() {
+			go restartAllServices(s.app, s.ctx, s.ecsClient, failed, progress, s.layout, s.retryRestart)
+		}, s.layout)
+	})
+}
+
+func restartResumeText(startedAt time.Time, pending []pkg.ServiceDetails, missing []string) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "A restart started %s was interrupted.", startedAt.Local().Format("2006-01-02 15:04"))
+	if len(pending) > 0 {
+		names := make([]string, 0, len(pending))
+		for _, service := range pending {
+			names = append(names, serviceKey(service))
+		}
+		fmt.Fprintf(&text, "\n\n%d service(s) were not restarted:\n%s", len(pending), listedNames(names))
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&text, "\n\n%d pending service(s) are no longer listed and can't be resumed:\n%s", len(missing), listedNames(missing))
+	}
+	return text.String()
+}
+
+// listedNames joins names one per line, summarizing the rest past maxListedServices
+func listedNames(names []string) string {
+	if len(names) <= maxListedServices {
+		return strings.Join(names, "\n")
+	}
+	return strings.Join(names[:maxListedServices], "\n") + fmt.Sprintf("\n...and %d more", len(names)-maxListedServices)
+}
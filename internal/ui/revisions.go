@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Task Definition Revision Diff
+// -----------------------------
+//
+// Lists the recent revisions of a service's task definition family. Pressing
+// Enter on two of them shows what changed between them: container images,
+// CPU and memory sizes, and environment variables.
+
+// showRevisionDiff lets the user pick two revisions of the service's task
+// definition family and compares them
+func showRevisionDiff(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	family := aws.TaskDefinitionFamily(service.TaskDefinition)
+	revisions, err := aws.ListTaskDefinitionRevisions(ctx, ecsClient, family, maxTaskDefinitionRevisions)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to list task definitions: %v", err), layout)
+		return
+	}
+	if len(revisions) < 2 {
+		showMessage(app, fmt.Sprintf("%s has fewer than two active revisions to compare.", family), layout)
+		return
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Revisions of %s: press Enter on two to compare ", tview.Escape(family)))
+
+	label := func(taskDefinition string, marked bool) string {
+		label := tview.Escape(aws.TaskDefinitionName(taskDefinition))
+		if taskDefinition == service.TaskDefinition {
+			label += " (current)"
+		}
+		if marked {
+			label = "[green]*[-] " + label
+		}
+		return label
+	}
+
+	marked := -1
+	for i, revision := range revisions {
+		index, taskDefinition := i, revision // Capture the current revision in the loop
+		list.AddItem(label(taskDefinition, false), "", 0, func() {
+			switch marked {
+			case -1:
+				marked = index
+				list.SetItemText(index, label(taskDefinition, true), "")
+			case index:
+				marked = -1
+				list.SetItemText(index, label(taskDefinition, false), "")
+			default:
+				from, to := revisions[marked], taskDefinition
+				list.SetItemText(marked, label(from, false), "")
+				marked = -1
+				if aws.TaskDefinitionRevision(from) > aws.TaskDefinitionRevision(to) {
+					from, to = to, from
+				}
+				showRevisionComparison(app, ctx, ecsClient, from, to, list, layout)
+			}
+		})
+	}
+
+	list.SetDoneFunc(func() {
+		app.SetRoot(layout, true)
+	})
+
+	app.SetRoot(list, true)
+}
+
+// showRevisionComparison shows the differences between two task definition
+// revisions, returning to previousView on Esc
+func showRevisionComparison(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, from, to string, previousView tview.Primitive, layout *tview.Flex) {
+	fromSummary, err := aws.GetTaskDefinitionSummary(ctx, ecsClient, from)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to describe task definition: %v", err), layout)
+		return
+	}
+	toSummary, err := aws.GetTaskDefinitionSummary(ctx, ecsClient, to)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to describe task definition: %v", err), layout)
+		return
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(revisionDiffText(fromSummary, toSummary))
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s → %s ", tview.Escape(aws.TaskDefinitionName(from)), tview.Escape(aws.TaskDefinitionName(to))))
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc, tcell.KeyEnter:
+			app.SetRoot(previousView, true)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(view, true)
+}
+
+// revisionDiffText lists the task and container settings that differ between
+// two revisions, with removed values prefixed by - and added ones by +
+func revisionDiffText(from, to pkg.TaskDefinitionSummary) string {
+	var b strings.Builder
+	changed := writeFieldDiff(&b, "Task", taskFields(from), taskFields(to))
+
+	fromContainers := make(map[string]*pkg.ContainerDefinitionSummary)
+	for i := range from.Containers {
+		fromContainers[from.Containers[i].Name] = &from.Containers[i]
+	}
+	for i := range to.Containers {
+		container := &to.Containers[i]
+		title := "Container " + container.Name
+		previous, ok := fromContainers[container.Name]
+		if !ok {
+			title += " (added)"
+		}
+		if writeFieldDiff(&b, title, containerFields(previous), containerFields(container)) {
+			changed = true
+		}
+		delete(fromContainers, container.Name)
+	}
+	for i := range from.Containers {
+		container := &from.Containers[i]
+		if _, removed := fromContainers[container.Name]; removed {
+			writeFieldDiff(&b, "Container "+container.Name+" (removed)", containerFields(container), nil)
+			changed = true
+		}
+	}
+
+	if !changed {
+		b.WriteString("No differences in images, CPU, memory or environment.\n\n")
+	}
+	b.WriteString("[gray]Press Esc to return[-]")
+	return b.String()
+}
+
+// diffField is one compared setting, e.g. "image" or "env PORT"
+type diffField struct {
+	key   string
+	value string
+}
+
+func taskFields(summary pkg.TaskDefinitionSummary) []diffField {
+	var fields []diffField
+	if summary.CPU != "" {
+		fields = append(fields, diffField{"cpu", summary.CPU})
+	}
+	if summary.Memory != "" {
+		fields = append(fields, diffField{"memory", summary.Memory + " MiB"})
+	}
+	return fields
+}
+
+// containerFields returns a container's compared settings, environment
+// variables last and sorted by name
+func containerFields(container *pkg.ContainerDefinitionSummary) []diffField {
+	if container == nil {
+		return nil
+	}
+	fields := []diffField{{"image", container.Image}}
+	if container.CPU > 0 {
+		fields = append(fields, diffField{"cpu", fmt.Sprintf("%d", container.CPU)})
+	}
+	if container.Memory != nil {
+		fields = append(fields, diffField{"memory", fmt.Sprintf("%d MiB", *container.Memory)})
+	}
+	if container.MemoryReservation != nil {
+		fields = append(fields, diffField{"memory reservation", fmt.Sprintf("%d MiB", *container.MemoryReservation)})
+	}
+
+	names := make([]string, 0, len(container.Environment))
+	for name := range container.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, diffField{"env " + name, container.Environment[name]})
+	}
+	return fields
+}
+
+// writeFieldDiff writes a section with the fields that differ between from
+// and to, and reports whether any did. Nothing is written when none differ.
+func writeFieldDiff(b *strings.Builder, title string, from, to []diffField) bool {
+	toValues := make(map[string]string, len(to))
+	for _, field := range to {
+		toValues[field.key] = field.value
+	}
+	fromValues := make(map[string]string, len(from))
+	for _, field := range from {
+		fromValues[field.key] = field.value
+	}
+
+	var lines strings.Builder
+	for _, field := range from {
+		value, ok := toValues[field.key]
+		if ok && value == field.value {
+			continue
+		}
+		fmt.Fprintf(&lines, "  [red]- %s: %s[-]\n", tview.Escape(field.key), tview.Escape(field.value))
+		if ok {
+			fmt.Fprintf(&lines, "  [green]+ %s: %s[-]\n", tview.Escape(field.key), tview.Escape(value))
+		}
+	}
+	for _, field := range to {
+		if _, ok := fromValues[field.key]; !ok {
+			fmt.Fprintf(&lines, "  [green]+ %s: %s[-]\n", tview.Escape(field.key), tview.Escape(field.value))
+		}
+	}
+
+	if lines.Len() == 0 {
+		return false
+	}
+	fmt.Fprintf(b, "[yellow]%s[-]\n%s\n", tview.Escape(title), lines.String())
+	return true
+}
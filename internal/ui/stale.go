@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Stale Services
+// --------------
+//
+// Services that haven't been deployed in a long time may be abandoned, or
+// running images that are missing patches. A service whose primary
+// deployment started more than StaleDays ago is marked in the list, and the
+// "is:stale" search term lists only those.
+
+// StaleDays is how many days after its last deployment a service is marked
+// stale. Zero disables the marker.
+var StaleDays = 90
+
+// staleQueryTerm is the search term matching stale services
+const staleQueryTerm = "is:stale"
+
+// isStale reports whether a service's last deployment is older than StaleDays
+func isStale(service pkg.ServiceDetails, now time.Time) bool {
+	if StaleDays <= 0 || service.LastDeployedAt == nil {
+		return false
+	}
+	return daysSince(*service.LastDeployedAt, now) >= StaleDays
+}
+
+// daysSince returns the number of whole days between then and now
+func daysSince(then, now time.Time) int {
+	return int(now.Sub(then) / (24 * time.Hour))
+}
+
+// lastDeployedText is the detail view's description of a service's last
+// deployment
+func lastDeployedText(deployedAt, now time.Time) string {
+	text := fmt.Sprintf("%s (%d days ago)", deployedAt.Local().Format(time.DateOnly), daysSince(deployedAt, now))
+	if StaleDays > 0 && daysSince(deployedAt, now) >= StaleDays {
+		text += fmt.Sprintf(" [yellow]stale: not deployed in over %d days[-]", StaleDays)
+	}
+	return text
+}
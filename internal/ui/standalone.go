@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Standalone Tasks View
+// ---------------------
+//
+// Tasks that don't belong to a service, such as scheduled tasks started by
+// EventBridge, never show up in the service list. This view lists a
+// cluster's running and recently stopped standalone tasks, optionally
+// filtered by what started them.
+
+const standaloneTasksFooter = "[gray]Press / to filter by started by, Esc to return[-]"
+
+func showStandaloneTasks(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cluster string, layout *tview.Flex) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	filter := tview.NewInputField().
+		SetLabel("Started by: ")
+	content := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filter, 1, 0, false).
+		AddItem(view, 0, 1, true)
+	content.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Standalone tasks in %s ", tview.Escape(aws.ClusterName(cluster))))
+
+	load := func(startedBy string) {
+		view.SetText("Loading...")
+		go func() {
+			tasks, err := aws.ListStandaloneTasks(ctx, ecsClient, cluster, startedBy)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					view.SetText(fmt.Sprintf("[red]%s[-]\n\n%s", tview.Escape(err.Error()), standaloneTasksFooter))
+					return
+				}
+				view.SetText(standaloneTasksText(tasks, startedBy))
+				view.ScrollToBeginning()
+			})
+		}()
+	}
+
+	filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			load(strings.TrimSpace(filter.GetText()))
+		}
+		app.SetFocus(view)
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			app.SetRoot(layout, true)
+			return nil
+		case event.Rune() == '/':
+			app.SetFocus(filter)
+			return nil
+		}
+		return event
+	})
+
+	load("")
+	app.SetRoot(content, true)
+	app.SetFocus(view)
+}
+
+func standaloneTasksText(tasks []pkg.TaskDetails, startedBy string) string {
+	if len(tasks) == 0 {
+		if startedBy != "" {
+			return fmt.Sprintf("No running or recently stopped tasks started by %s.\n\n%s", tview.Escape(startedBy), standaloneTasksFooter)
+		}
+		return "No running or recently stopped standalone tasks.\n\n" + standaloneTasksFooter
+	}
+
+	var b strings.Builder
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "[yellow]Task:[-] %s (%s)\n", tview.Escape(taskID(task.TaskArn)), tview.Escape(aws.TaskDefinitionName(task.TaskDefinition)))
+		fmt.Fprintf(&b, "  [yellow]Status:[-] %s\n", standaloneTaskStatus(task))
+		if task.StartedBy != "" {
+			fmt.Fprintf(&b, "  [yellow]Started By:[-] %s\n", tview.Escape(task.StartedBy))
+		}
+		if task.StartedAt != nil {
+			fmt.Fprintf(&b, "  [yellow]Started At:[-] %s\n", task.StartedAt.Local().Format(time.RFC1123))
+		}
+		if task.StoppedAt != nil {
+			fmt.Fprintf(&b, "  [yellow]Stopped At:[-] %s\n", task.StoppedAt.Local().Format(time.RFC1123))
+		}
+		if task.StoppedReason != "" {
+			fmt.Fprintf(&b, "  [yellow]Stopped Reason:[-] %s\n", tview.Escape(task.StoppedReason))
+		}
+		if task.LastStatus == "STOPPED" {
+			writeContainerExits(&b, task.Containers)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(standaloneTasksFooter)
+	return b.String()
+}
+
+// standaloneTaskStatus colors a task's status: red for tasks that stopped
+// with a failing container, green for ones that exited cleanly
+func standaloneTaskStatus(task pkg.TaskDetails) string {
+	status := tview.Escape(task.LastStatus)
+	if task.LastStatus != "STOPPED" {
+		return status
+	}
+	for _, container := range task.Containers {
+		if container.ExitCode == nil || *container.ExitCode != 0 {
+			return "[red]" + status + "[-]"
+		}
+	}
+	return "[green]" + status + "[-]"
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/pkg"
+)
+
+// Stuck Deployments
+// -----------------
+//
+// A rollout without a deployment circuit breaker can hang indefinitely, e.g.
+// at "Deploying (1/3)" while new tasks keep failing health checks. The
+// running count of each service's primary deployment is tracked across
+// polls, and a rollout whose count hasn't changed for StuckAfter is flagged.
+
+// StuckAfter is how long a rollout may go without its running count changing
+// before it is flagged as stuck. Zero disables the check.
+var StuckAfter = 15 * time.Minute
+
+// deploymentProgress is when a service's primary deployment last changed its
+// running count
+type deploymentProgress struct {
+	deploymentID string
+	running      int64
+	since        time.Time
+	stuck        bool
+}
+
+// trackDeployments records the progress of every deploying service in a poll,
+// forgetting services that finished deploying, and announces rollouts that
+// just became stuck
+func (s *ServiceUI) trackDeployments(services []pkg.ServiceDetails, now time.Time) {
+	if StuckAfter <= 0 {
+		return
+	}
+	tracked := make(map[string]*deploymentProgress)
+	var newlyStuck []string
+	for _, service := range services {
+		if !service.Deploying {
+			continue
+		}
+		key := serviceKey(service)
+		progress, ok := s.deployments[key]
+		if !ok || progress.deploymentID != service.DeploymentID || progress.running != service.DeploymentRunningCount {
+			progress = &deploymentProgress{deploymentID: service.DeploymentID, running: service.DeploymentRunningCount, since: now}
+		} else if !progress.stuck && now.Sub(progress.since) >= StuckAfter {
+			progress.stuck = true
+			newlyStuck = append(newlyStuck, service.ServiceName)
+		}
+		tracked[key] = progress
+	}
+	s.deployments = tracked
+
+	if len(newlyStuck) > 0 {
+		s.showToast(fmt.Sprintf("Deployment stuck for %s: no progress in %s", strings.Join(newlyStuck, ", "), StuckAfter))
+	}
+}
+
+// isStuck reports whether the service's rollout has made no progress for
+// StuckAfter
+func (s *ServiceUI) isStuck(service pkg.ServiceDetails) bool {
+	progress, ok := s.deployments[serviceKey(service)]
+	return ok && progress.stuck
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Tasks View
+// ----------
+//
+// Lists why a service's recently stopped tasks stopped. For EC2 launch type
+// services, the container instance each running task is placed on is listed
+// first.
+
+func showServiceTasks(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	tasks, err := aws.GetStoppedTasks(ctx, ecsClient, service.Cluster, service.ServiceName)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to fetch stopped tasks: %v", err), layout)
+		return
+	}
+
+	text := stoppedTasksText(tasks)
+	if !service.IsFargate() {
+		text = runningTasksText(ctx, ecsClient, service) + "\n[yellow]Recently Stopped:[-]\n" + text
+	}
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(text)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Tasks for %s ", tview.Escape(service.ServiceName)))
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc, tcell.KeyEnter:
+			app.SetRoot(layout, true)
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(view, true)
+}
+
+// runningTasksText lists the container instances the service's running tasks
+// are placed on
+func runningTasksText(ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails) string {
+	tasks, err := aws.ListServiceTasks(ctx, ecsClient, service.Cluster, service.ServiceName)
+	if err != nil {
+		return strings.TrimPrefix(containerInstancesText(nil, nil, err), "\n")
+	}
+	return strings.TrimPrefix(loadContainerInstancesText(ctx, ecsClient, service.Cluster, tasks), "\n")
+}
+
+func stoppedTasksText(tasks []pkg.TaskDetails) string {
+	if len(tasks) == 0 {
+		return "No recently stopped tasks.\n\n[gray]Press Esc to return[-]"
+	}
+
+	var b strings.Builder
+	for _, task := range tasks {
+		fmt.Fprintf(&b, "[yellow]Task:[-] %s\n", tview.Escape(taskID(task.TaskArn)))
+		if task.StoppedAt != nil {
+			fmt.Fprintf(&b, "  [yellow]Stopped At:[-] %s\n", task.StoppedAt.Local().Format(time.RFC1123))
+		}
+		fmt.Fprintf(&b, "  [yellow]Stopped Reason:[-] %s\n", tview.Escape(task.StoppedReason))
+		writeContainerExits(&b, task.Containers)
+		b.WriteString("\n")
+	}
+	b.WriteString("[gray]Press Esc to return[-]")
+	return b.String()
+}
+
+// writeContainerExits writes each container's exit code and reason,
+// highlighting non-zero exit codes
+func writeContainerExits(b *strings.Builder, containers []pkg.ContainerDetails) {
+	for _, container := range containers {
+		exitCode := "none"
+		if container.ExitCode != nil {
+			exitCode = fmt.Sprintf("%d", *container.ExitCode)
+			if *container.ExitCode != 0 {
+				exitCode = fmt.Sprintf("[red]%s[-]", exitCode)
+			}
+		}
+		fmt.Fprintf(b, "  [yellow]Container %s:[-] exit code %s", tview.Escape(container.Name), exitCode)
+		if container.Reason != "" {
+			fmt.Fprintf(b, " - %s", tview.Escape(container.Reason))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// taskID returns the task ID from a task ARN
+func taskID(taskArn string) string {
+	return taskArn[strings.LastIndex(taskArn, "/")+1:]
+}
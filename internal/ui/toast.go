@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// Toasts
+// ------
+//
+// A toast is a short message shown in the header that disappears on its own,
+// for confirming quick actions without a modal.
+
+const toastDuration = 3 * time.Second
+
+// showToast shows a message in the header for toastDuration. A newer toast
+// replaces an older one, and isn't cleared by the older one's timer.
+func (s *ServiceUI) showToast(message string) {
+	s.toastSeq++
+	seq := s.toastSeq
+	s.toast = message
+	s.updateHeader()
+
+	time.AfterFunc(toastDuration, func() {
+		s.app.QueueUpdateDraw(func() {
+			if s.toastSeq == seq {
+				s.toast = ""
+				s.updateHeader()
+			}
+		})
+	})
+}
+
+func (s *ServiceUI) toastText() string {
+	if s.toast == "" {
+		return ""
+	}
+	return "\n[green]" + tview.Escape(s.toast) + "[-]"
+}
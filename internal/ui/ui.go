@@ -2,15 +2,22 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/browser"
+	"github.com/alexalbu001/bw-cli/internal/config"
+	"github.com/alexalbu001/bw-cli/internal/restartprogress"
+	"github.com/alexalbu001/bw-cli/internal/savedcounts"
+	"github.com/alexalbu001/bw-cli/internal/state"
 	"github.com/alexalbu001/bw-cli/pkg"
-	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -18,45 +25,222 @@ import (
 // ServiceUI struct and initialization
 // -----------------------------------
 
+// ServiceUI is the interactive service list.
+//
+// Its fields belong to the UI goroutine: they are only read and written from
+// tview callbacks and from functions queued with QueueUpdateDraw, never from
+// background goroutines, so they need no locking. Background work receives
+// copies of what it needs and queues its results. Service slices may be
+// shared with the poller and refresh hooks, so they are replaced rather than
+// modified in place once handed out.
 type ServiceUI struct {
-	app              *tview.Application
-	ctx              context.Context
-	ecsClient        *ecs.Client
-	list             *tview.List
-	searchInput      *tview.InputField
-	currentServices  []pkg.ServiceDetails
-	filteredServices []pkg.ServiceDetails
-	layout           *tview.Flex
-	header           *tview.TextView
-	logo             *tview.TextView
-}
-
-func NewServiceUI(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, initialServices []pkg.ServiceDetails) *ServiceUI {
+	app                 *tview.Application
+	ctx                 context.Context
+	ecsClient           aws.ECSClientAPI
+	cwClient            aws.CloudWatchClientAPI
+	elbClient           aws.ELBClientAPI
+	scalingClient       aws.AutoScalingClientAPI
+	list                *tview.List
+	searchInput         *tview.InputField
+	lastMatch           func(pkg.ServiceDetails) bool // Matcher of the last valid search query
+	currentServices     []pkg.ServiceDetails
+	filteredServices    []pkg.ServiceDetails
+	layout              *tview.Flex
+	header              *tview.TextView
+	banner              *tview.TextView // Shown across the top in production
+	costFooter          *tview.TextView // Fargate cost estimate, shown below the list when there is one
+	logo                *tview.TextView
+	groupFilter         string
+	clusterFilter       string   // Cluster ARN the list is narrowed to with f, for this session only
+	clusterScope        []string // Cluster ARNs the applied saved view covers; empty for every loaded cluster
+	pickedClusters      []string // Cluster ARNs chosen in the startup picker; empty when it wasn't shown
+	state               *state.State
+	config              *config.Config
+	statePath           string
+	profile             string // The AWS profile in use, checked against the production profiles
+	loadError           error
+	configError         error // Why the config file couldn't be loaded, if it couldn't
+	refreshHooks        []func([]pkg.ServiceDetails)
+	metrics             map[string]metricsEntry
+	metricsPending      map[string]bool
+	noMetricsNoted      map[string]bool // Clusters without metrics the user has been told about
+	statistic           aws.Statistic   // How utilization is aggregated: average or maximum
+	history             map[string]*countHistory
+	deployments         map[string]*deploymentProgress // Deploying services, to detect stuck rollouts
+	reservations        map[string]pkg.TaskReservation // By task definition ARN; revisions never change
+	reservationsPending map[string]bool                // Reservations being fetched for the Fargate cost estimate
+	spinnerFrame        int                            // Advanced on every poll to animate deploying services
+	scalePending        map[string]bool                // Services with a +/- desired count change in flight
+	targetHealth        map[string][]pkg.TargetHealth  // By service key, as last loaded in the detail view
+	toast               string
+	toastSeq            int
+	polledServices      []pkg.ServiceDetails // The services polled for updates, fixed at startup
+	refreshingCluster   string               // The cluster being re-fetched with r, if any
+	stopPolling         context.CancelFunc
+	idleTimeout         time.Duration
+	idleTimer           *time.Timer
+	lastInput           time.Time
+	paused              bool            // Polling stopped after idleTimeout without input
+	modalOpen           bool            // A modal or other screen covers the list
+	listStale           bool            // Services were refreshed while modalOpen but not yet listed
+	changed             map[string]bool // Services whose counts or status changed in the last poll
+	apiStats            *aws.APIStats
+	apiStatsText        string // Calls made up to the last refresh, shown in the header
+	pager               *aws.ServicePager
+	morePages           bool // The pager has services left to load
+	pageLoading         bool // A page is being loaded in the background
+}
+
+func NewServiceUI(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cwClient aws.CloudWatchClientAPI, initialServices []pkg.ServiceDetails) *ServiceUI {
+	aws.SortServices(initialServices)
 	s := &ServiceUI{
-		app:              app,
-		ctx:              ctx,
-		ecsClient:        ecsClient,
-		list:             tview.NewList(),
-		searchInput:      tview.NewInputField().SetLabel("/ "),
-		currentServices:  initialServices,
-		filteredServices: initialServices,
-		header:           tview.NewTextView().SetTextAlign(tview.AlignLeft),
-		logo:             tview.NewTextView().SetTextAlign(tview.AlignRight),
+		app:                 app,
+		ctx:                 ctx,
+		ecsClient:           ecsClient,
+		cwClient:            cwClient,
+		list:                tview.NewList(),
+		searchInput:         tview.NewInputField().SetLabel("/ "),
+		currentServices:     initialServices,
+		filteredServices:    initialServices,
+		header:              tview.NewTextView().SetTextAlign(tview.AlignLeft).SetDynamicColors(true),
+		banner:              tview.NewTextView().SetTextAlign(tview.AlignCenter).SetTextColor(tcell.ColorWhite),
+		costFooter:          tview.NewTextView().SetTextAlign(tview.AlignCenter).SetDynamicColors(true),
+		logo:                tview.NewTextView().SetTextAlign(tview.AlignRight),
+		state:               state.Default(),
+		config:              config.Default(),
+		metrics:             make(map[string]metricsEntry),
+		metricsPending:      make(map[string]bool),
+		noMetricsNoted:      make(map[string]bool),
+		statistic:           aws.StatisticAverage,
+		history:             make(map[string]*countHistory),
+		deployments:         make(map[string]*deploymentProgress),
+		reservations:        make(map[string]pkg.TaskReservation),
+		targetHealth:        make(map[string][]pkg.TargetHealth),
+		reservationsPending: make(map[string]bool),
+		scalePending:        make(map[string]bool),
 	}
+	s.recordHistory(initialServices)
+	s.trackDeployments(initialServices, time.Now())
 	s.layout = s.createLayout()
 	return s
 }
 
-func DisplayServices(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, initialServices []pkg.ServiceDetails) {
-	serviceUI := NewServiceUI(app, ctx, ecsClient, initialServices)
+func DisplayServices(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cwClient aws.CloudWatchClientAPI, initialServices []pkg.ServiceDetails) *ServiceUI {
+	serviceUI := NewServiceUI(app, ctx, ecsClient, cwClient, initialServices)
 
+	serviceUI.loadState()
+	serviceUI.loadConfig()
+	serviceUI.updateProductionBanner()
 	serviceUI.updateList()
 	serviceUI.setupSearchInput()
 	serviceUI.setupListInputCapture()
+	serviceUI.setupMouse()
+	serviceUI.setupLazyMetrics()
+	serviceUI.setupModalTracking()
 	serviceUI.startPolling()
+	serviceUI.loadFargateReservations()
 
 	app.SetRoot(serviceUI.layout, true)
 	app.SetFocus(serviceUI.list)
+	serviceUI.offerRestartResume()
+	return serviceUI
+}
+
+// DisplayLoadError replaces the UI with an error screen offering to retry the
+// initial load or quit. retry runs in its own goroutine while a loading
+// message is shown, and is responsible for queueing the next screen.
+func DisplayLoadError(app *tview.Application, err error, retry func()) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Failed to load services:\n\n%v", err)).
+		AddButtons([]string{"Retry", "Quit"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel != "Retry" {
+				app.Stop()
+				return
+			}
+			loading := tview.NewTextView().
+				SetText("Loading services...").
+				SetTextAlign(tview.AlignCenter)
+			app.SetRoot(loading, true)
+			go retry()
+		})
+
+	app.SetRoot(modal, true)
+}
+
+// SetLoadError shows a warning in the header when the service list is
+// incomplete, e.g. because some clusters failed to load.
+func (s *ServiceUI) SetLoadError(err error) {
+	s.loadError = err
+	s.updateHeader()
+}
+
+// SetELBClient enables checking the load balancer health of each service's
+// tasks in the detail view
+func (s *ServiceUI) SetELBClient(elbClient aws.ELBClientAPI) {
+	s.elbClient = elbClient
+}
+
+// SetAutoScalingClient enables enforcing auto scaling bounds when changing a
+// service's desired count
+func (s *ServiceUI) SetAutoScalingClient(scalingClient aws.AutoScalingClientAPI) {
+	s.scalingClient = scalingClient
+}
+
+// SetPickedClusters records the clusters chosen in the startup picker, so
+// saved views remember which clusters they cover
+func (s *ServiceUI) SetPickedClusters(clusters []string) {
+	s.pickedClusters = clusters
+}
+
+// SetAPIStats shows the API calls counted in stats in the header, starting
+// with the calls made while loading and then those made between refreshes
+func (s *ServiceUI) SetAPIStats(stats *aws.APIStats) {
+	s.apiStats = stats
+	s.takeAPIStats()
+}
+
+func (s *ServiceUI) takeAPIStats() {
+	if s.apiStats == nil {
+		return
+	}
+	s.apiStatsText = aws.FormatAPIStats(s.apiStats.Take())
+	s.updateHeader()
+}
+
+// loadState restores persisted UI settings. Persistence is best-effort: if the
+// state file cannot be located or read the UI starts with defaults.
+func (s *ServiceUI) loadState() {
+	path, err := state.DefaultPath()
+	if err != nil {
+		return
+	}
+	s.statePath = path
+
+	st, err := state.Load(path)
+	if err != nil {
+		return
+	}
+	s.state = st
+	s.groupFilter = st.GroupFilter
+	s.filterServices(s.searchInput.GetText())
+}
+
+// loadConfig reads the user's config file. An unreadable or invalid config
+// is reported in the header, and every change is then guarded as production
+// since the production accounts and profiles are unknown.
+func (s *ServiceUI) loadConfig() {
+	cfg, err := config.LoadDefault()
+	s.config = cfg
+	s.configError = err
+	s.updateHeader()
+}
+
+func (s *ServiceUI) saveState() {
+	if s.statePath == "" {
+		return
+	}
+	_ = state.Save(s.statePath, s.state)
 }
 
 // UI Layout and Creation
@@ -64,7 +248,7 @@ func DisplayServices(app *tview.Application, ctx context.Context, ecsClient *ecs
 
 func (s *ServiceUI) createLayout() *tview.Flex {
 	legend := tview.NewTextView().
-		SetText("[yellow]s[-] - Shell | [red]R[-] - Redeploy all containers | [#69359C]/[-] - Search").
+		SetText("[yellow]s[-] - Shell | [red]R[-] - Redeploy listed services | [#69359C]/[-] - Search | [green]g[-] - Group | [green]f[-] - This cluster only | [green]0[-] - Clear filters | [yellow]r[-] - Refresh cluster | [yellow]p[-] - Pin | [yellow]m[-] - Mute | [yellow]v[-] - Verbosity | [yellow]M[-] - Refresh metrics | [yellow]a[-] - Avg/Max | [yellow]H[-] - Heatmap | [yellow]V[-] - Views | [blue]c[-] - Clusters | [blue]d[-] - Details | [blue]e[-] - Events | [blue]t[-] - Tasks | [blue]T[-] - Standalone tasks | [blue]D[-] - Diff revisions | [blue]j[-] - JSON | [blue]w[-] - Follow deployment | [blue]o[-] - Console | [blue]y[-] - Copy list | [red]b[-] - Rollback | [red]+/-[-] - Scale by one | [red]C[-] - Scale cluster | [red]Z[-] - Zero/restore cluster").
 		SetTextColor(tcell.ColorWhite).
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter)
@@ -84,11 +268,15 @@ func (s *ServiceUI) createLayout() *tview.Flex {
 		AddItem(s.header, 0, 1, false).
 		AddItem(s.logo, 0, 1, false)
 
+	s.banner.SetBackgroundColor(tcell.ColorRed)
+
 	mainFlex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
+		AddItem(s.banner, 0, 0, false).
 		AddItem(topBar, 6, 1, false).
 		AddItem(s.searchInput, 1, 1, false).
 		AddItem(listFrame, 0, 1, true).
+		AddItem(s.costFooter, 0, 0, false).
 		AddItem(legend, 1, 1, false)
 
 	return mainFlex
@@ -101,43 +289,393 @@ func (s *ServiceUI) updateList() {
 	s.list.Clear()
 	for i, service := range s.filteredServices {
 		index := i
-		status := service.Status
-		statusColor := "[white]"
-		switch strings.ToLower(status) {
-		case "active":
-			statusColor = "[green]"
-		case "draining":
-			statusColor = "[yellow]"
-		case "inactive":
-			statusColor = "[red]"
-		}
-		s.list.AddItem(
-			fmt.Sprintf("%s (Running: %d, Desired: %d) - Status: %s%s[-]",
-				service.ServiceName, service.RunningCount, service.DesiredCount, statusColor, status),
-			"", 0, func() {
-				showServiceOptions(s.app, s.ctx, s.ecsClient, s.filteredServices[index], s.filteredServices, s.layout)
+		s.list.AddItem(s.serviceItemText(service), "", 0, func() {
+			service := s.filteredServices[index]
+			s.guardProduction("change "+service.ServiceName, func() {
+				showServiceOptions(s.app, s.ctx, s.ecsClient, s.scalingClient, service, s.filteredServices, s.layout)
 			})
+		})
 	}
 	s.updateHeader()
 }
 
+// spinnerFrames animate the indicator next to deploying services
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// changedHighlight is the background of rows that changed in the last poll.
+// The rows' own color tags only reset the foreground, so it spans the row.
+const changedHighlight = "[:#3a3a3a]"
+
+func (s *ServiceUI) serviceItemText(service pkg.ServiceDetails) string {
+	text := s.serviceColumnsText(service)
+	if s.changed[serviceKey(service)] {
+		text = changedHighlight + text + "[:-]"
+	}
+	return text
+}
+
+// serviceColumnsText renders the columns of a service's row allowed by the
+// list verbosity
+func (s *ServiceUI) serviceColumnsText(service pkg.ServiceDetails) string {
+	status := service.Status
+	statusColor := healthColor(s.serviceHealth(service))
+	counts := fmt.Sprintf("Running: %d, Desired: %d", service.RunningCount, service.DesiredCount)
+	if service.IsDaemon() {
+		// Daemon services run one task per instance, so desired count isn't a target to compare against
+		counts = fmt.Sprintf("Daemon, Running: %d", service.RunningCount)
+	}
+	verbosity := s.verbosityRank()
+	text := tview.Escape(service.ServiceName)
+	if verbosity >= verbosityRank(verbosityCounts) {
+		text += fmt.Sprintf(" (%s)", counts)
+	}
+	if verbosity >= verbosityRank(verbosityStatus) {
+		text += fmt.Sprintf(" - Status: %s%s[-]", statusColor, tview.Escape(status))
+	}
+	if s.isPinned(service) {
+		text = "[yellow]★[-] " + text
+	}
+	if s.isMuted(service) {
+		text += " [gray](muted)[-]"
+	}
+	if verbosity < verbosityRank(verbosityStatus) {
+		return text
+	}
+	if service.Deploying {
+		text = fmt.Sprintf("[blue]%c[-] %s [blue]Deploying[-]", spinnerFrames[s.spinnerFrame%len(spinnerFrames)], text)
+		if s.isStuck(service) {
+			text = "[red]⚠[-] " + text + " [red]Stuck[-]"
+		}
+	}
+	if service.PlacementBlocked {
+		text = "[red]⚠[-] " + text + " [red]Placement blocked[-]"
+	}
+	if s.isFlapping(service) {
+		text = "[red]⚠[-] " + text + " [red]Flapping[-]"
+	}
+	if isStale(service, time.Now()) {
+		text += " [yellow]⌛ Stale[-]"
+	}
+	if verbosity < verbosityRank(verbosityMetrics) {
+		return text
+	}
+	if metrics, ok := s.metrics[serviceKey(service)]; ok && metrics.unavailable {
+		text += " | CPU: n/a | Mem: n/a"
+		if metrics.noAccess {
+			text += " [gray](no metrics access)[-]"
+		}
+	} else if ok {
+		text += fmt.Sprintf(" | CPU: %s | Mem: %s", formatUtilization(metrics.values.CPUUtilization), formatUtilization(metrics.values.MemoryUtilization))
+		if metrics.values.SustainedHighCPU {
+			text += " [red]⚠ CPU pressure[-]"
+		}
+		if metrics.values.SustainedHighMemory {
+			text += " [red]⚠ Memory pressure[-]"
+		}
+	}
+	return text
+}
+
 func (s *ServiceUI) updateHeader() {
 	s.header.Clear()
-	fmt.Fprintf(s.header, "Total Services: %d", len(s.currentServices))
+	unhealthy, degraded := s.countHealth(s.unmuted(s.currentServices))
+	unhealthyColor, degradedColor := "[white]", "[white]"
+	if unhealthy > 0 {
+		unhealthyColor = healthColor(aws.HealthUnhealthy)
+	}
+	if degraded > 0 {
+		degradedColor = healthColor(aws.HealthDegraded)
+	}
+	fmt.Fprintf(s.header, "Total Services: %d | Unhealthy: %s%d[-] | Degraded: %s%d[-]", len(s.currentServices), unhealthyColor, unhealthy, degradedColor, degraded)
+	if muted := len(s.currentServices) - len(s.unmuted(s.currentServices)); muted > 0 {
+		fmt.Fprintf(s.header, " | Muted: %d", muted)
+	}
+	if s.groupFilter != "" {
+		fmt.Fprintf(s.header, " | Group: %s", tview.Escape(s.groupFilter))
+	}
+	if s.clusterFilter != "" {
+		fmt.Fprintf(s.header, " | Cluster: %s", tview.Escape(aws.ClusterName(s.clusterFilter)))
+	}
+	if len(s.clusterScope) > 0 {
+		fmt.Fprintf(s.header, " | Clusters: %d", len(s.clusterScope))
+	}
+	if s.cwClient != nil {
+		fmt.Fprintf(s.header, " | Utilization: %s", statisticLabel(s.statistic))
+	}
+	fmt.Fprint(s.header, s.pagingText())
+	if s.configError != nil {
+		fmt.Fprintf(s.header, "\n[red]%s[-]", tview.Escape(s.configError.Error()))
+	}
+	if s.loadError != nil {
+		fmt.Fprintf(s.header, "\n[red]%s[-]", tview.Escape(s.loadError.Error()))
+	}
+	if s.paused {
+		fmt.Fprint(s.header, "\n[yellow]Paused — press any key to resume[-]")
+	}
+	if s.apiStatsText != "" {
+		fmt.Fprintf(s.header, "\n[gray]API: %s[-]", s.apiStatsText)
+	}
+	fmt.Fprint(s.header, s.toastText())
+	s.updateCostFooter()
+}
+
+// serviceHealth computes a service's health, including the health of its
+// targets once the detail view has loaded them
+func (s *ServiceUI) serviceHealth(service pkg.ServiceDetails) aws.Health {
+	return aws.ServiceHealth(service, s.targetHealth[serviceKey(service)])
+}
+
+// setTargetHealth caches a service's target health and recolors its row
+func (s *ServiceUI) setTargetHealth(service pkg.ServiceDetails, targets []pkg.TargetHealth) {
+	key := serviceKey(service)
+	s.targetHealth[key] = targets
+	for i, filtered := range s.filteredServices {
+		if serviceKey(filtered) == key && i < s.list.GetItemCount() {
+			s.list.SetItemText(i, s.serviceItemText(filtered), "")
+		}
+	}
+	s.updateHeader()
+}
+
+// healthColor returns the color tag a health verdict is shown in
+func healthColor(health aws.Health) string {
+	switch health {
+	case aws.HealthHealthy:
+		return "[green]"
+	case aws.HealthDegraded:
+		return "[yellow]"
+	case aws.HealthUnhealthy:
+		return "[red]"
+	}
+	return "[white]"
+}
+
+// healthText renders a health verdict in its color
+func healthText(health aws.Health) string {
+	return healthColor(health) + string(health) + "[-]"
+}
+
+// countHealth counts the unhealthy and the degraded services
+func (s *ServiceUI) countHealth(services []pkg.ServiceDetails) (unhealthy, degraded int) {
+	for _, service := range services {
+		switch s.serviceHealth(service) {
+		case aws.HealthUnhealthy:
+			unhealthy++
+		case aws.HealthDegraded:
+			degraded++
+		}
+	}
+	return unhealthy, degraded
 }
 
 func (s *ServiceUI) filterServices(query string) {
-	if query == "" {
+	match, err := queryMatcher(query)
+	if err != nil {
+		// Keep filtering with the last valid query while an invalid regex is
+		// being typed, so refreshed services are still listed
+		s.searchInput.SetFieldTextColor(tcell.ColorRed)
+		match = s.lastMatch
+		if match == nil {
+			match = func(pkg.ServiceDetails) bool { return true }
+		}
+	} else {
+		s.searchInput.SetFieldTextColor(tview.Styles.PrimaryTextColor)
+		s.lastMatch = match
+	}
+
+	if query == "" && s.groupFilter == "" && s.clusterFilter == "" && len(s.clusterScope) == 0 {
 		s.filteredServices = s.currentServices
 	} else {
 		s.filteredServices = []pkg.ServiceDetails{}
 		for _, service := range s.currentServices {
-			if strings.Contains(strings.ToLower(service.ServiceName), strings.ToLower(query)) {
+			if s.groupFilter != "" && service.Group != s.groupFilter {
+				continue
+			}
+			if s.clusterFilter != "" && service.Cluster != s.clusterFilter {
+				continue
+			}
+			if !s.inClusterScope(service) {
+				continue
+			}
+			if match(service) {
 				s.filteredServices = append(s.filteredServices, service)
 			}
 		}
 	}
+	s.filteredServices = s.pinnedFirst(s.filteredServices)
 	s.updateList()
+	s.loadMoreIfNeeded()
+}
+
+// regexQueryPrefix switches the search query to a regular expression
+const regexQueryPrefix = "re:"
+
+// queryMatcher returns a function matching services against query. Terms
+// such as desired>=10 or running==0 are count conditions; see
+// parseCountCondition. The term "is:stale" matches stale services. A term
+// starting with "re:" makes the rest of the query a case-insensitive regular
+// expression for the name. Other terms must all be contained in the name, as
+// in matchesQuery.
+func queryMatcher(query string) (func(service pkg.ServiceDetails) bool, error) {
+	var conditions []func(service pkg.ServiceDetails) bool
+	var terms []string
+	var re *regexp.Regexp
+
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		if pattern, ok := strings.CutPrefix(rest, regexQueryPrefix); ok {
+			var err error
+			if re, err = regexp.Compile("(?i)" + pattern); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		term, remainder, _ := strings.Cut(rest, " ")
+		rest = strings.TrimSpace(remainder)
+		if strings.EqualFold(term, staleQueryTerm) {
+			conditions = append(conditions, func(service pkg.ServiceDetails) bool { return isStale(service, time.Now()) })
+			continue
+		}
+		condition, ok, err := parseCountCondition(term)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			conditions = append(conditions, condition)
+		} else {
+			terms = append(terms, term)
+		}
+	}
+
+	nameQuery := strings.Join(terms, " ")
+	return func(service pkg.ServiceDetails) bool {
+		for _, condition := range conditions {
+			if !condition(service) {
+				return false
+			}
+		}
+		if re != nil && !re.MatchString(service.ServiceName) {
+			return false
+		}
+		return matchesQuery(service.ServiceName, nameQuery)
+	}, nil
+}
+
+// countConditionPattern matches terms that look like a count condition,
+// valid or not, so a half-typed one is reported rather than searched for
+var countConditionPattern = regexp.MustCompile(`^(?i)(desired|running)([<>=!].*)$`)
+
+// parseCountCondition parses a term such as desired>=10 or running==0 into a
+// predicate on the service's desired or running count. ok is false when the
+// term isn't a count condition; err is set when it looks like one but the
+// operator or count is invalid. Supported operators are >, >=, <, <= and ==.
+func parseCountCondition(term string) (condition func(service pkg.ServiceDetails) bool, ok bool, err error) {
+	match := countConditionPattern.FindStringSubmatch(term)
+	if match == nil {
+		return nil, false, nil
+	}
+
+	field, expression := strings.ToLower(match[1]), match[2]
+	var operator string
+	for _, candidate := range []string{">=", "<=", "==", ">", "<"} {
+		if strings.HasPrefix(expression, candidate) {
+			operator = candidate
+			break
+		}
+	}
+	if operator == "" {
+		return nil, false, fmt.Errorf("invalid operator in %q: use >, >=, <, <= or ==", term)
+	}
+	value, err := strconv.ParseInt(strings.TrimPrefix(expression, operator), 10, 64)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid count in %q", term)
+	}
+
+	count := func(service pkg.ServiceDetails) int64 { return service.DesiredCount }
+	if field == "running" {
+		count = func(service pkg.ServiceDetails) int64 { return service.RunningCount }
+	}
+	compare := map[string]func(a, b int64) bool{
+		">":  func(a, b int64) bool { return a > b },
+		">=": func(a, b int64) bool { return a >= b },
+		"<":  func(a, b int64) bool { return a < b },
+		"<=": func(a, b int64) bool { return a <= b },
+		"==": func(a, b int64) bool { return a == b },
+	}[operator]
+	return func(service pkg.ServiceDetails) bool {
+		return compare(count(service), value)
+	}, true, nil
+}
+
+// matchesQuery reports whether name contains every whitespace-separated term
+// of query, ignoring case
+func matchesQuery(name, query string) bool {
+	name = strings.ToLower(name)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(name, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// setGroupFilter limits the list to services whose cluster belongs to group.
+// An empty group shows services from every cluster.
+func (s *ServiceUI) setGroupFilter(group string) {
+	s.groupFilter = group
+	s.state.GroupFilter = group
+	s.saveState()
+	s.filterServices(s.searchInput.GetText())
+}
+
+// toggleClusterFilter limits the list to the selected service's cluster, or
+// lifts the limit if one is set. The selected service stays selected.
+func (s *ServiceUI) toggleClusterFilter() {
+	selected, hasSelection := s.selectedService()
+	if s.clusterFilter != "" {
+		s.clusterFilter = ""
+	} else if hasSelection {
+		s.clusterFilter = selected.Cluster
+	} else {
+		return
+	}
+	s.filterServices(s.searchInput.GetText())
+	if hasSelection {
+		s.selectService(selected.ServiceName, selected.Cluster)
+	}
+}
+
+// resetFilters clears the search query, group filter and cluster filter in one
+// go and returns focus to the list, keeping the selected service selected
+func (s *ServiceUI) resetFilters() {
+	selected, hasSelection := s.selectedService()
+	s.clusterFilter = ""
+	s.clusterScope = nil
+	s.groupFilter = ""
+	s.state.GroupFilter = ""
+	s.saveState()
+	s.searchInput.SetText("")
+	s.filterServices("")
+	if hasSelection {
+		s.selectService(selected.ServiceName, selected.Cluster)
+	}
+	s.app.SetFocus(s.list)
+	s.showToast("Filters cleared")
+}
+
+// serviceGroups returns the distinct cluster groups in services, sorted.
+func serviceGroups(services []pkg.ServiceDetails) []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, service := range services {
+		if !seen[service.Group] {
+			seen[service.Group] = true
+			groups = append(groups, service.Group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return aws.NaturalLess(groups[i], groups[j]) })
+	return groups
 }
 
 // Input Setup
@@ -173,15 +711,155 @@ func (s *ServiceUI) setupListInputCapture() {
 		case tcell.KeyRune:
 			switch event.Rune() {
 			case 'R':
-				showRestartAllServicesPrompt(s.app, s.ctx, s.ecsClient, s.currentServices, s.layout)
+				// Starting a restart would lose track of an unfinished one
+				if progress := unfinishedRestart(); progress != nil {
+					s.showRestartResume(progress, true)
+					return nil
+				}
+				services, filtered := s.filteredServices, s.isFiltered()
+				s.guardProduction("restart the listed services", func() {
+					showRestartServicesPrompt(s.app, s.ctx, s.ecsClient, services, filtered, s.config, s.layout, s.retryRestart)
+				})
 			case 's':
 				if s.list.GetItemCount() > 0 {
 					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					if !currentService.ExecuteCommandEnabled {
+						showExecDisabledWarning(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+						return nil
+					}
 					showContainerExecPrompt(s.app, s.ctx, s.ecsClient, currentService)
 				}
 			case '/':
 				s.app.SetFocus(s.searchInput)
 				return nil
+			case 'g':
+				s.showGroupSelection()
+				return nil
+			case 'd':
+				s.showSelectedDetails()
+				return nil
+			case 'f':
+				s.toggleClusterFilter()
+				return nil
+			case 'r':
+				s.refreshCluster()
+				return nil
+			case '0':
+				s.resetFilters()
+				return nil
+			case 'v':
+				s.cycleVerbosity()
+				return nil
+			case '+', '-':
+				if s.list.GetItemCount() > 0 {
+					delta := int64(1)
+					if event.Rune() == '-' {
+						delta = -1
+					}
+					service := s.filteredServices[s.list.GetCurrentItem()]
+					s.guardProduction(fmt.Sprintf("scale %s by %+d", service.ServiceName, delta), func() {
+						s.adjustDesiredCount(service, delta)
+					})
+				}
+				return nil
+			case 'p':
+				if s.list.GetItemCount() > 0 {
+					s.togglePin(s.filteredServices[s.list.GetCurrentItem()])
+				}
+				return nil
+			case 'm':
+				if s.list.GetItemCount() > 0 {
+					s.toggleMute(s.filteredServices[s.list.GetCurrentItem()])
+				}
+				return nil
+			case 'M':
+				if s.list.GetItemCount() > 0 {
+					s.refreshServiceMetrics(s.filteredServices[s.list.GetCurrentItem()])
+				}
+				return nil
+			case 'a':
+				s.toggleStatistic()
+				return nil
+			case 'H':
+				s.showHeatmap()
+				return nil
+			case 'V':
+				s.showViews()
+				return nil
+			case 'c':
+				showClusterOverview(s.app, s.currentServices, s.layout)
+				return nil
+			case 'e':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showServiceEvents(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+				}
+				return nil
+			case 't':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showServiceTasks(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+				}
+				return nil
+			case 'T':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showStandaloneTasks(s.app, s.ctx, s.ecsClient, currentService.Cluster, s.layout)
+				}
+				return nil
+			case 'w':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showDeploymentFollow(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+				}
+				return nil
+			case 'j':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showServiceJSON(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+				}
+				return nil
+			case 'D':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					showRevisionDiff(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+				}
+				return nil
+			case 'y':
+				s.copyList()
+				return nil
+			case 'o':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					openInConsole(s.app, currentService, s.layout)
+				}
+				return nil
+			case 'C':
+				if s.list.GetItemCount() > 0 {
+					cluster := s.filteredServices[s.list.GetCurrentItem()].Cluster
+					services := servicesInCluster(s.currentServices, cluster)
+					s.guardProduction("scale cluster "+aws.ClusterName(cluster), func() {
+						showScaleClusterPrompt(s.app, s.ctx, s.ecsClient, cluster, services, s.config, s.layout)
+					})
+				}
+				return nil
+			case 'Z':
+				if s.list.GetItemCount() > 0 {
+					cluster := s.filteredServices[s.list.GetCurrentItem()].Cluster
+					services := servicesInCluster(s.currentServices, cluster)
+					s.guardProduction("scale cluster "+aws.ClusterName(cluster)+" to zero or restore it", func() {
+						showScaleToZeroToggle(s.app, s.ctx, s.ecsClient, cluster, services, s.config, s.layout)
+					})
+				}
+				return nil
+			case 'b':
+				if s.list.GetItemCount() > 0 {
+					currentService := s.filteredServices[s.list.GetCurrentItem()]
+					s.guardProduction("roll back "+currentService.ServiceName, func() {
+						showRollbackPrompt(s.app, s.ctx, s.ecsClient, currentService, s.layout)
+					})
+				}
+				return nil
 			}
 		case tcell.KeyUp:
 			if s.list.GetCurrentItem() == 0 {
@@ -193,36 +871,259 @@ func (s *ServiceUI) setupListInputCapture() {
 	})
 }
 
+func (s *ServiceUI) showGroupSelection() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Filter by cluster group ")
+
+	closeSelection := func() {
+		s.app.SetRoot(s.layout, true)
+		s.app.SetFocus(s.list)
+	}
+
+	list.AddItem("All groups", "", 0, func() {
+		s.setGroupFilter("")
+		closeSelection()
+	})
+	for _, group := range serviceGroups(s.currentServices) {
+		group := group // Capture the current group in the loop
+		list.AddItem(tview.Escape(group), "", 0, func() {
+			s.setGroupFilter(group)
+			closeSelection()
+		})
+	}
+
+	list.SetDoneFunc(closeSelection)
+
+	s.app.SetRoot(list, true)
+}
+
 // Service Updates
 // ---------------
 
+// PollInterval is how often the service list, and any followed events, refresh
+var PollInterval = 10 * time.Second
+
 func (s *ServiceUI) startPolling() {
-	updateInterval := 10 * time.Second
-	updates := aws.PollServiceUpdates(s.ctx, s.ecsClient, s.currentServices, updateInterval)
+	if s.polledServices == nil {
+		s.polledServices = append([]pkg.ServiceDetails(nil), s.currentServices...)
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.stopPolling = cancel
+	updates := aws.PollServiceUpdates(ctx, s.ecsClient, s.polledServices, PollInterval, MetricsInterval)
 
-	go func() {
-		for updatedServices := range updates {
-			s.app.QueueUpdateDraw(func() {
-				s.currentServices = updatedServices
-				s.filterServices(s.searchInput.GetText())
-			})
+	go s.consumeUpdates(ctx, updates)
+}
+
+// consumeUpdates applies polled updates on the UI goroutine until updates is
+// closed or ctx is done. Once ctx is done nothing more is queued, and updates
+// that were queued but not yet applied are dropped, so a stopped or paused UI
+// isn't updated behind its back.
+func (s *ServiceUI) consumeUpdates(ctx context.Context, updates <-chan aws.PollUpdate) {
+	for {
+		var update aws.PollUpdate
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok = <-updates:
+			if !ok {
+				return
+			}
 		}
+
+		applied := s.queueUpdateDraw(ctx, func() {
+			if update.MetricsDue {
+				s.refreshMetrics()
+				return
+			}
+			s.refreshServices(update.Services)
+			s.forgetDeletedClusters(update.DeletedClusters)
+		})
+		if !applied {
+			return
+		}
+	}
+}
+
+// queueUpdateDraw runs f on the UI goroutine unless ctx is done first, and
+// reports whether it ran. QueueUpdateDraw never returns once the application
+// has stopped, so it is waited on alongside ctx rather than directly.
+func (s *ServiceUI) queueUpdateDraw(ctx context.Context, f func()) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	ran := make(chan bool, 1)
+	go func() {
+		s.app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				ran <- false
+				return
+			}
+			f()
+			ran <- true
+		})
 	}()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case result := <-ran:
+		return result
+	}
+}
+
+// changedServices returns the keys of services whose running count, desired
+// count or status differ between two polls. Services missing from either
+// poll aren't reported.
+func changedServices(previous, updated []pkg.ServiceDetails) map[string]bool {
+	before := make(map[string]pkg.ServiceDetails, len(previous))
+	for _, service := range previous {
+		before[serviceKey(service)] = service
+	}
+
+	changed := make(map[string]bool)
+	for _, service := range updated {
+		key := serviceKey(service)
+		old, ok := before[key]
+		if !ok {
+			continue
+		}
+		if old.RunningCount != service.RunningCount || old.DesiredCount != service.DesiredCount || old.Status != service.Status {
+			changed[key] = true
+		}
+	}
+	return changed
+}
+
+// OnRefresh registers a function that receives every polled set of services
+func (s *ServiceUI) OnRefresh(hook func([]pkg.ServiceDetails)) {
+	s.refreshHooks = append(s.refreshHooks, hook)
+}
+
+// refreshServices swaps in a new set of services while keeping the
+// currently highlighted service selected, if it is still listed. While a
+// modal or other screen covers the list, the list itself is only updated
+// once it is shown again, so the service being acted on doesn't move.
+func (s *ServiceUI) refreshServices(updatedServices []pkg.ServiceDetails) {
+	s.changed = changedServices(s.currentServices, updatedServices)
+	// Target health goes stale once a service's tasks change
+	for key := range s.changed {
+		delete(s.targetHealth, key)
+	}
+	s.currentServices = updatedServices
+	s.recordHistory(updatedServices)
+	s.trackDeployments(updatedServices, time.Now())
+	s.spinnerFrame++
+	if s.modalOpen {
+		s.listStale = true
+		s.updateHeader()
+	} else {
+		s.relist()
+	}
+	s.attachMetrics(updatedServices)
+	s.loadFargateReservations()
+	for _, hook := range s.refreshHooks {
+		hook(updatedServices)
+	}
+	s.takeAPIStats()
+}
+
+// relist filters the current services into the list again, keeping the
+// highlighted service selected
+func (s *ServiceUI) relist() {
+	selected, hasSelection := s.selectedService()
+	s.filterServices(s.searchInput.GetText())
+	if hasSelection {
+		s.selectService(selected.ServiceName, selected.Cluster)
+	}
+	s.loadVisibleMetrics()
+}
+
+// forgetDeletedClusters stops polling the services of clusters that were
+// deleted mid-session, which the poll has already left out of the list, and
+// mentions it in the header rather than interrupting with a modal
+func (s *ServiceUI) forgetDeletedClusters(clusters []string) {
+	if len(clusters) == 0 {
+		return
+	}
+	deleted := make(map[string]bool, len(clusters))
+	names := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		deleted[cluster] = true
+		names = append(names, aws.ClusterName(cluster))
+	}
+
+	var polled []pkg.ServiceDetails
+	for _, service := range s.polledServices {
+		if !deleted[service.Cluster] {
+			polled = append(polled, service)
+		}
+	}
+	s.polledServices = polled
+	s.restartPolling()
+
+	s.showToast(fmt.Sprintf("Cluster %s no longer exists; its services were removed", strings.Join(names, ", ")))
+}
+
+// restartPolling makes polling pick up a changed set of polled services,
+// unless polling is paused
+func (s *ServiceUI) restartPolling() {
+	if s.stopPolling == nil || s.paused {
+		return
+	}
+	s.stopPolling()
+	s.startPolling()
+}
+
+// showSelectedDetails opens the detail view of the highlighted service
+func (s *ServiceUI) showSelectedDetails() {
+	currentService, ok := s.selectedService()
+	if !ok {
+		return
+	}
+	if entry, ok := s.metrics[serviceKey(currentService)]; ok && !entry.unavailable {
+		currentService.SetMetrics(entry.values)
+	}
+	showServiceDetails(s.app, currentService, s.serviceHistory(currentService), s.detailSections(currentService), s.layout)
+}
+
+// isFiltered reports whether a search, group or cluster filter narrows the list
+func (s *ServiceUI) isFiltered() bool {
+	return strings.TrimSpace(s.searchInput.GetText()) != "" || s.groupFilter != "" || s.clusterFilter != "" || len(s.clusterScope) > 0
+}
+
+func (s *ServiceUI) selectedService() (pkg.ServiceDetails, bool) {
+	index := s.list.GetCurrentItem()
+	if s.list.GetItemCount() == 0 || index >= len(s.filteredServices) {
+		return pkg.ServiceDetails{}, false
+	}
+	return s.filteredServices[index], true
+}
+
+func (s *ServiceUI) selectService(serviceName, cluster string) {
+	for i, service := range s.filteredServices {
+		if service.ServiceName == serviceName && service.Cluster == cluster {
+			s.list.SetCurrentItem(i)
+			return
+		}
+	}
 }
 
 // Service Actions
 // ---------------
 
-func showServiceOptions(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, service pkg.ServiceDetails, services []pkg.ServiceDetails, layout *tview.Flex) {
+func showServiceOptions(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, scalingClient aws.AutoScalingClientAPI, service pkg.ServiceDetails, services []pkg.ServiceDetails, layout *tview.Flex) {
 	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Service: %s\nChoose an action:", service.ServiceName)).
-		AddButtons([]string{"Change Desired Count", "Restart Service", "Cancel"}).
+		SetText(fmt.Sprintf("Service: %s\nChoose an action:", tview.Escape(service.ServiceName))).
+		AddButtons([]string{"Change Desired Count", "Restart Service", "Change Task Definition", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Change Desired Count":
-				showDesiredCountPrompt(app, ctx, ecsClient, service, services, layout)
+				showDesiredCountPrompt(app, ctx, ecsClient, scalingClient, service, services, layout)
 			case "Restart Service":
-				restartService(app, ctx, ecsClient, service, layout)
+				showRestartPrompt(app, ctx, ecsClient, service, layout)
+			case "Change Task Definition":
+				showTaskDefinitionSelection(app, ctx, ecsClient, service, layout)
 			default:
 				app.SetRoot(layout, true)
 			}
@@ -231,70 +1132,449 @@ func showServiceOptions(app *tview.Application, ctx context.Context, ecsClient *
 	app.SetRoot(modal, false)
 }
 
-func restartService(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, service pkg.ServiceDetails, layout *tview.Flex) {
+func showRestartPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	text := fmt.Sprintf("Restart service %s?", tview.Escape(service.ServiceName))
+	if allowsFullDowntime(service) {
+		text += "\n\nWarning: minimum healthy percent is 0%, so all tasks may stop before new ones start."
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Restart", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Restart" {
+				restartService(app, ctx, ecsClient, service, layout)
+				return
+			}
+			app.SetRoot(layout, true)
+		})
+
+	app.SetRoot(modal, false)
+}
+
+// allowsFullDowntime reports whether a redeploy may stop every task of the
+// service before replacements are running.
+func allowsFullDowntime(service pkg.ServiceDetails) bool {
+	return service.MinimumHealthyPercent != nil && *service.MinimumHealthyPercent == 0
+}
+
+// restartService forces a new deployment and follows it
+func restartService(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
 	err := aws.RestartService(ctx, ecsClient, service.ServiceName, service.Cluster)
 	if err != nil {
 		showMessage(app, fmt.Sprintf("Failed to restart service: %v", err), layout)
-	} else {
-		showMessage(app, fmt.Sprintf("Service %s has been restarted.", service.ServiceName), layout)
+		return
 	}
+	showDeploymentFollow(app, ctx, ecsClient, service, layout)
 }
 
-func showRestartAllServicesPrompt(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, services []pkg.ServiceDetails, layout *tview.Flex) {
+// maxTaskDefinitionRevisions is how many recent revisions are offered when
+// switching a service's task definition.
+const maxTaskDefinitionRevisions = 10
+
+func showTaskDefinitionSelection(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	family := aws.TaskDefinitionFamily(service.TaskDefinition)
+	revisions, err := aws.ListTaskDefinitionRevisions(ctx, ecsClient, family, maxTaskDefinitionRevisions)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to list task definitions: %v", err), layout)
+		return
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(fmt.Sprintf(" Task definitions for %s ", tview.Escape(service.ServiceName)))
+	for _, revision := range revisions {
+		taskDefinition := revision // Capture the current revision in the loop
+		label := aws.TaskDefinitionName(taskDefinition)
+		if taskDefinition == service.TaskDefinition {
+			label += " (current)"
+		}
+		list.AddItem(label, "", 0, func() {
+			prompt := fmt.Sprintf("Deploy %s to service %s?", tview.Escape(aws.TaskDefinitionName(taskDefinition)), tview.Escape(service.ServiceName))
+			showTaskDefinitionConfirm(app, ctx, ecsClient, service, taskDefinition, prompt, list, layout)
+		})
+	}
+
+	list.SetDoneFunc(func() {
+		app.SetRoot(layout, true)
+	})
+
+	app.SetRoot(list, true)
+}
+
+func showTaskDefinitionConfirm(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, taskDefinition, prompt string, previousView tview.Primitive, layout *tview.Flex) {
 	modal := tview.NewModal().
-		SetText("Are you sure you want to restart all services?").
-		AddButtons([]string{"Yes", "No"}).
+		SetText(prompt).
+		AddButtons([]string{"Deploy", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Yes" {
-				go restartAllServices(app, ctx, ecsClient, services, layout)
+			if buttonLabel != "Deploy" {
+				app.SetRoot(previousView, true)
+				return
 			}
-			app.SetRoot(layout, true)
+			err := aws.UpdateServiceTaskDefinition(ctx, ecsClient, service.ServiceName, service.Cluster, taskDefinition)
+			if err != nil {
+				showMessage(app, fmt.Sprintf("Failed to update task definition: %v", err), layout)
+				return
+			}
+			showDeploymentFollow(app, ctx, ecsClient, service, layout)
 		})
 
 	app.SetRoot(modal, false)
 }
 
-func restartAllServices(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, services []pkg.ServiceDetails, layout *tview.Flex) {
+func showRollbackPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	previous, err := aws.GetPreviousTaskDefinition(ctx, ecsClient, service.TaskDefinition)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to find a revision to roll back to: %v", err), layout)
+		return
+	}
+
+	prompt := fmt.Sprintf("Roll back %s from %s to %s?", tview.Escape(service.ServiceName),
+		tview.Escape(aws.TaskDefinitionName(service.TaskDefinition)), tview.Escape(aws.TaskDefinitionName(previous)))
+	showTaskDefinitionConfirm(app, ctx, ecsClient, service, previous, prompt, layout, layout)
+}
+
+// openInConsole opens the service in the AWS console, falling back to
+// showing the URL when no browser can be launched.
+func openInConsole(app *tview.Application, service pkg.ServiceDetails, layout *tview.Flex) {
+	consoleURL, err := aws.ServiceConsoleURL(service.Cluster, service.ServiceName)
+	if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to build console URL: %v", err), layout)
+		return
+	}
+
+	if err := browser.Open(consoleURL); err != nil {
+		showMessage(app, fmt.Sprintf("Could not open a browser. Service console URL:\n\n%s", consoleURL), layout)
+	}
+}
+
+// showRestartServicesPrompt confirms restarting the listed services: every
+// service, or only those matching the search and group filter when filtered
+func showRestartServicesPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, services []pkg.ServiceDetails, filtered bool, cfg *config.Config, layout *tview.Flex, retry retryRestartFunc) {
+	if len(services) == 0 {
+		showMessage(app, "No services match the current filter.", layout)
+		return
+	}
+
+	showBulkConfirm(app, restartPromptText(services, filtered), len(services), cfg, func() {
+		startRestart(app, ctx, ecsClient, services, layout, retry)
+	}, layout)
+}
+
+// startRestart records the services as pending before restarting them, so
+// an interrupted restart can be resumed on the next launch
+func startRestart(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, services []pkg.ServiceDetails, layout *tview.Flex, retry retryRestartFunc) {
+	path, err := restartprogress.DefaultPath()
+	if err != nil {
+		showMessage(app, err.Error(), layout)
+		return
+	}
+	progress, err := restartprogress.Load(path)
+	if err != nil {
+		showMessage(app, err.Error(), layout)
+		return
+	}
+	if err := progress.Start(services); errors.Is(err, restartprogress.ErrUnfinished) {
+		showMessage(app, fmt.Sprintf("Not restarting: %v.\n\nResume or discard that restart first.", err), layout)
+		return
+	} else if err != nil {
+		showMessage(app, fmt.Sprintf("Failed to save restart progress: %v", err), layout)
+		return
+	}
+	go restartAllServices(app, ctx, ecsClient, services, progress, layout, retry)
+}
+
+func restartPromptText(services []pkg.ServiceDetails, filtered bool) string {
+	text := fmt.Sprintf("Are you sure you want to restart all %d services?", len(services))
+	if filtered {
+		text = fmt.Sprintf("Are you sure you want to restart %d filtered services?", len(services))
+	}
+	downtime := 0
+	for _, service := range services {
+		if allowsFullDowntime(service) {
+			downtime++
+		}
+	}
+	if downtime > 0 {
+		text += fmt.Sprintf("\n\nWarning: %d service(s) have a minimum healthy percent of 0%% and may be fully down during the redeploy.", downtime)
+	}
+	return text
+}
+
+// restartAllServices restarts the services concurrently, marking each one
+// done in progress as it succeeds. Services that fail stay pending.
+func restartAllServices(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, services []pkg.ServiceDetails, progress *restartprogress.Progress, layout *tview.Flex, retry retryRestartFunc) {
 	var wg sync.WaitGroup
-	failedServices := make(chan string, len(services))
+	failedServices := make(chan pkg.ServiceDetails, len(services))
 
 	for _, service := range services {
 		wg.Add(1)
 		go func(s pkg.ServiceDetails) {
 			defer wg.Done()
 			if err := aws.RestartService(ctx, ecsClient, s.ServiceName, s.Cluster); err != nil {
-				failedServices <- s.ServiceName
+				failedServices <- s
+				return
 			}
+			_ = progress.Done(s.Cluster, s.ServiceName)
 		}(service)
 	}
 
 	wg.Wait()
 	close(failedServices)
 
-	failed := make([]string, 0, len(services))
+	failed := make([]pkg.ServiceDetails, 0, len(services))
 	for s := range failedServices {
 		failed = append(failed, s)
 	}
 
 	app.QueueUpdateDraw(func() {
 		if len(failed) > 0 {
-			showMessage(app, fmt.Sprintf("Failed to restart services: %v", failed), layout)
+			showRestartFailures(app, failed, progress, layout, retry)
 		} else {
-			showMessage(app, "All services have been restarted successfully.", layout)
+			showMessage(app, fmt.Sprintf("%d service(s) have been restarted successfully.", len(services)), layout)
 		}
 	})
 }
 
-func showDesiredCountPrompt(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, service pkg.ServiceDetails, services []pkg.ServiceDetails, layout *tview.Flex) {
+func showScaleClusterPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cluster string, services []pkg.ServiceDetails, cfg *config.Config, layout *tview.Flex) {
+	services, daemons := aws.ScalableServices(services)
+	if len(services) == 0 {
+		showMessage(app, fmt.Sprintf("No services in %s can be scaled: daemon services have no desired count.", aws.ClusterName(cluster)), layout)
+		return
+	}
 	inputField := tview.NewInputField().
-		SetLabel(fmt.Sprintf("Change desired count for %s: ", service.ServiceName)).
+		SetLabel(fmt.Sprintf("Scale all %d services in %s to: ", len(services), tview.Escape(aws.ClusterName(cluster)))).
 		SetFieldWidth(5)
 
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			app.SetRoot(layout, true)
+			return
+		}
+
+		desiredCount, err := strconv.Atoi(inputField.GetText())
+		if err != nil || desiredCount < 0 {
+			showMessage(app, "Invalid input. Please enter a non-negative integer.", layout)
+			return
+		}
+
+		names := make([]string, 0, len(services))
+		for _, service := range services {
+			names = append(names, service.ServiceName)
+		}
+		text := fmt.Sprintf("Scale these services in %s to %d?\n\n%s%s",
+			aws.ClusterName(cluster), desiredCount, strings.Join(names, "\n"), skippedDaemonsText(daemons))
+		showBulkConfirm(app, text, len(services), cfg, func() {
+			go scaleServices(app, ctx, ecsClient, services, int64(desiredCount), layout)
+		}, layout)
+	})
+
+	app.SetRoot(inputField, true)
+}
+
+func scaleServices(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, services []pkg.ServiceDetails, desiredCount int64, layout *tview.Flex) {
+	updates := make([]aws.DesiredCountUpdate, len(services))
+	for i, service := range services {
+		updates[i] = aws.DesiredCountUpdate{Service: service, DesiredCount: desiredCount}
+	}
+	applyDesiredCounts(app, ctx, ecsClient, updates, layout, nil)
+}
+
+// applyDesiredCounts applies the updates and reports the outcome. afterApply,
+// if set, runs on the UI goroutine with the results before they're shown.
+func applyDesiredCounts(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, updates []aws.DesiredCountUpdate, layout *tview.Flex, afterApply func([]aws.ServiceResult)) {
+	results := aws.UpdateDesiredCounts(ctx, ecsClient, updates)
+	var failed []string
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result.Service.ServiceName)
+		}
+	}
+
+	app.QueueUpdateDraw(func() {
+		if afterApply != nil {
+			afterApply(results)
+		}
+		if len(failed) > 0 {
+			showMessage(app, fmt.Sprintf("Failed to scale services: %v", failed), layout)
+		} else {
+			showMessage(app, fmt.Sprintf("All %d services have been scaled.", len(updates)), layout)
+		}
+	})
+}
+
+// showScaleToZeroToggle scales a cluster's services to zero while saving
+// their desired counts, or restores the saved counts if there are any.
+func showScaleToZeroToggle(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cluster string, services []pkg.ServiceDetails, cfg *config.Config, layout *tview.Flex) {
+	path, err := savedcounts.DefaultPath()
+	if err != nil {
+		showMessage(app, err.Error(), layout)
+		return
+	}
+	store, err := savedcounts.Load(path)
+	if err != nil {
+		showMessage(app, err.Error(), layout)
+		return
+	}
+
+	restoring := store.HasCluster(cluster)
+	var updates []aws.DesiredCountUpdate
+	var prompt string
+	if restoring {
+		updates = store.RestoreUpdates(services)
+		prompt = fmt.Sprintf("Restore %d services in %s to their saved desired counts?", len(updates), tview.Escape(aws.ClusterName(cluster)))
+	} else {
+		_, daemons := aws.ScalableServices(services)
+		updates = store.ZeroUpdates(services)
+		prompt = fmt.Sprintf("Scale %d services in %s to zero?\nTheir current desired counts will be saved for restoring.%s", len(updates), tview.Escape(aws.ClusterName(cluster)), skippedDaemonsText(daemons))
+	}
+	if len(updates) == 0 {
+		if restoring {
+			// Persist the daemon counts RestoreUpdates dropped
+			_ = store.Save()
+		}
+		showMessage(app, fmt.Sprintf("No services to scale in %s.", tview.Escape(aws.ClusterName(cluster))), layout)
+		return
+	}
+
+	showBulkConfirm(app, prompt, len(updates), cfg, func() {
+		if restoring {
+			go applyDesiredCounts(app, ctx, ecsClient, updates, layout, func(results []aws.ServiceResult) {
+				store.ForgetRestored(results)
+				_ = store.Save()
+			})
+			return
+		}
+		// Persist the counts before scaling so they're never lost
+		if err := store.Save(); err != nil {
+			showMessage(app, fmt.Sprintf("Failed to save desired counts: %v", err), layout)
+			return
+		}
+		go applyDesiredCounts(app, ctx, ecsClient, updates, layout, nil)
+	}, layout)
+}
+
+// showBulkConfirm asks for confirmation of an operation affecting count
+// services. Up to the configured threshold a Yes/No modal is enough; above
+// it the user has to type the count or "yes", scaling the friction with the
+// blast radius. onConfirm runs after the main layout has been restored.
+func showBulkConfirm(app *tview.Application, text string, count int, cfg *config.Config, onConfirm func(), layout *tview.Flex) {
+	if !cfg.RequiresTypedConfirmation(count) {
+		modal := tview.NewModal().
+			SetText(text).
+			AddButtons([]string{"Yes", "No"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				app.SetRoot(layout, true)
+				if buttonLabel == "Yes" {
+					onConfirm()
+				}
+			})
+		app.SetRoot(modal, false)
+		return
+	}
+
+	message := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("%s\n\n[red]This affects %d services.[-] Type %d or yes to proceed, or press Esc to cancel.", tview.Escape(text), count, count))
+	inputField := tview.NewInputField().
+		SetLabel("Confirm: ")
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			app.SetRoot(layout, true)
+			return
+		}
+		if !config.IsTypedConfirmation(inputField.GetText(), count) {
+			showMessage(app, "Confirmation did not match; nothing was changed.", layout)
+			return
+		}
+		app.SetRoot(layout, true)
+		onConfirm()
+	})
+
+	form := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(message, 0, 1, false).
+		AddItem(inputField, 1, 0, true)
+	form.SetBorder(true).SetTitle(" Confirm bulk operation ")
+
+	app.SetRoot(form, true)
+}
+
+// skippedDaemonsText lists the daemon services a cluster-wide change leaves
+// out, or is empty when there are none
+func skippedDaemonsText(daemons []pkg.ServiceDetails) string {
+	if len(daemons) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(daemons))
+	for _, daemon := range daemons {
+		names = append(names, daemon.ServiceName)
+	}
+	return "\n\nSkipped, daemon services have no desired count:\n" + strings.Join(names, "\n")
+}
+
+// servicesInCluster returns the services that belong to cluster
+func servicesInCluster(services []pkg.ServiceDetails, cluster string) []pkg.ServiceDetails {
+	var inCluster []pkg.ServiceDetails
+	for _, service := range services {
+		if service.Cluster == cluster {
+			inCluster = append(inCluster, service)
+		}
+	}
+	return inCluster
+}
+
+// showDesiredCountPrompt asks for a new desired count. For auto-scaled
+// services the scaling bounds are shown, and counts outside them are
+// rejected since auto scaling would immediately override them.
+// scalingBoundsTimeout limits the wait for a service's auto scaling bounds
+const scalingBoundsTimeout = 5 * time.Second
+
+func showDesiredCountPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, scalingClient aws.AutoScalingClientAPI, service pkg.ServiceDetails, services []pkg.ServiceDetails, layout *tview.Flex) {
+	name := tview.Escape(service.ServiceName)
+	inputField := tview.NewInputField().
+		SetLabel(fmt.Sprintf("Change desired count for %s: ", name)).
+		SetFieldWidth(5)
+
+	// The bounds are fetched in the background so a slow or denied auto
+	// scaling endpoint doesn't hold up the prompt. They are only read and
+	// written on the UI goroutine.
+	var bounds *pkg.ScalingBounds
+	boundsPending := scalingClient != nil
+	if boundsPending {
+		inputField.SetLabel(fmt.Sprintf("Change desired count for %s (checking auto scaling bounds...): ", name))
+		go func() {
+			boundsCtx, cancel := context.WithTimeout(ctx, scalingBoundsTimeout)
+			defer cancel()
+			loaded, err := aws.GetScalingBounds(boundsCtx, scalingClient, service.Cluster, service.ServiceName)
+			app.QueueUpdateDraw(func() {
+				boundsPending = false
+				bounds = loaded
+				switch {
+				case err != nil:
+					inputField.SetLabel(fmt.Sprintf("Change desired count for %s (auto scaling bounds unavailable): ", name))
+				case bounds != nil:
+					inputField.SetLabel(fmt.Sprintf("Change desired count for %s (auto-scaled, min %d, max %d): ", name, bounds.MinCapacity, bounds.MaxCapacity))
+				default:
+					inputField.SetLabel(fmt.Sprintf("Change desired count for %s: ", name))
+				}
+			})
+		}()
+	}
+
 	inputField.SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEnter {
 			newDesiredCount, err := strconv.Atoi(inputField.GetText())
-			if err != nil {
-				showMessage(app, "Invalid input. Please enter a positive integer.", layout)
+			if err != nil || newDesiredCount < 0 {
+				showMessage(app, "Invalid input. Please enter a non-negative integer.", layout)
+				return
+			}
+			if boundsPending {
+				// Keep the count typed so far; Enter again once they arrive
+				return
+			}
+			if bounds != nil && !bounds.Contains(int64(newDesiredCount)) {
+				showMessage(app, desiredCountOutOfBoundsMessage(service, newDesiredCount, *bounds), layout)
 				return
 			}
 
@@ -304,14 +1584,19 @@ func showDesiredCountPrompt(app *tview.Application, ctx context.Context, ecsClie
 				return
 			}
 
-			showMessage(app, fmt.Sprintf("Updated %s to desired count %d. The running count will update shortly.",
-				service.ServiceName, newDesiredCount), layout)
+			// Follow the running count to the new desired count
+			showDeploymentFollow(app, ctx, ecsClient, service, layout)
 		}
 	})
 
 	app.SetRoot(inputField, true)
 }
 
+func desiredCountOutOfBoundsMessage(service pkg.ServiceDetails, desiredCount int, bounds pkg.ScalingBounds) string {
+	return fmt.Sprintf("%s is auto-scaled between %d and %d tasks, so a desired count of %d would be overridden by auto scaling.\n\nChange the service's auto scaling limits instead.",
+		service.ServiceName, bounds.MinCapacity, bounds.MaxCapacity, desiredCount)
+}
+
 // Utility Functions
 // -----------------
 
@@ -326,7 +1611,24 @@ func showMessage(app *tview.Application, message string, previousView tview.Prim
 	app.SetRoot(modal, false)
 }
 
-func showContainerExecPrompt(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, service pkg.ServiceDetails) {
+// showExecDisabledWarning warns that the service doesn't report ECS Exec as
+// enabled, which usually makes the shell fail, and lets the user try anyway
+func showExecDisabledWarning(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails, layout *tview.Flex) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Warning: ECS Exec is not enabled for %s, so opening a shell will likely fail.\n\nEnable it with aws ecs update-service --enable-execute-command and redeploy the service.", tview.Escape(service.ServiceName))).
+		AddButtons([]string{"Try Anyway", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Try Anyway" {
+				showContainerExecPrompt(app, ctx, ecsClient, service)
+				return
+			}
+			app.SetRoot(layout, true)
+		})
+
+	app.SetRoot(modal, false)
+}
+
+func showContainerExecPrompt(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, service pkg.ServiceDetails) {
 	taskArn, err := aws.GetTaskArnForService(ctx, ecsClient, service.Cluster, service.ServiceName)
 	if err != nil {
 		showMessage(app, fmt.Sprintf("Failed to fetch task for service: %v", err), nil)
@@ -342,7 +1644,7 @@ func showContainerExecPrompt(app *tview.Application, ctx context.Context, ecsCli
 	showContainerSelection(app, ctx, ecsClient, service.Cluster, taskArn, containerNames)
 }
 
-func showContainerSelection(app *tview.Application, ctx context.Context, ecsClient *ecs.Client, cluster, taskArn string, containerNames []string) {
+func showContainerSelection(app *tview.Application, ctx context.Context, ecsClient aws.ECSClientAPI, cluster, taskArn string, containerNames []string) {
 	list := tview.NewList()
 	for _, containerName := range containerNames {
 		container := containerName // Capture the current containerName in the loop
@@ -1,11 +1,28 @@
 package ui
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/config"
+	"github.com/alexalbu001/bw-cli/internal/restartprogress"
+	"github.com/alexalbu001/bw-cli/internal/summary"
 	"github.com/alexalbu001/bw-cli/pkg"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +48,7 @@ func TestNewServiceUI(t *testing.T) {
 		{ServiceName: "service2", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
 	}
 
-	serviceUI := NewServiceUI(app, ctx, mockClient, initialServices)
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
 
 	assert.NotNil(t, serviceUI)
 	assert.Equal(t, app, serviceUI.app)
@@ -50,7 +67,7 @@ func TestUpdateList(t *testing.T) {
 		{ServiceName: "service2", RunningCount: 2, DesiredCount: 2, Status: "DRAINING"},
 	}
 
-	serviceUI := NewServiceUI(app, ctx, mockClient, initialServices)
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
 	serviceUI.updateList()
 
 	assert.Equal(t, 2, serviceUI.list.GetItemCount())
@@ -58,12 +75,307 @@ func TestUpdateList(t *testing.T) {
 	item1, _ := serviceUI.list.GetItemText(0)
 	assert.Contains(t, item1, "service1")
 	assert.Contains(t, item1, "(Running: 1, Desired: 2)")
-	assert.Contains(t, item1, "[green]ACTIVE[-]")
+	assert.Contains(t, item1, "[yellow]ACTIVE[-]") // Degraded: fewer tasks running than desired
 
 	item2, _ := serviceUI.list.GetItemText(1)
 	assert.Contains(t, item2, "service2")
 	assert.Contains(t, item2, "(Running: 2, Desired: 2)")
-	assert.Contains(t, item2, "[yellow]DRAINING[-]")
+	assert.Contains(t, item2, "[red]DRAINING[-]")
+}
+
+func TestUpdateHeaderUnhealthyCount(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service2", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service3", RunningCount: 0, DesiredCount: 0, Status: "DRAINING"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateHeader()
+
+	header := serviceUI.header.GetText(true)
+	assert.Contains(t, header, "Total Services: 3")
+	assert.Contains(t, header, "Unhealthy: 1 | Degraded: 1")
+	assert.Contains(t, serviceUI.header.GetText(false), "Unhealthy: [red]1[-] | Degraded: [yellow]1[-]")
+	unhealthy, degraded := serviceUI.countHealth(initialServices)
+	assert.Equal(t, 1, unhealthy)
+	assert.Equal(t, 1, degraded)
+	assert.Contains(t, serviceDetailsText(initialServices[1], nil), "Health:[-] [yellow]Degraded[-]")
+}
+
+func TestTargetHealthColorsList(t *testing.T) {
+	app := tview.NewApplication()
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{service})
+	serviceUI.updateList()
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "[green]ACTIVE[-]")
+
+	// Target health loaded by the detail view counts once it is known
+	serviceUI.setTargetHealth(service, []pkg.TargetHealth{{State: "healthy"}, {State: "unhealthy"}})
+	item, _ = serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "[yellow]ACTIVE[-]")
+	assert.Contains(t, serviceUI.header.GetText(true), "Unhealthy: 0 | Degraded: 1")
+
+	// and is dropped once the service's tasks change
+	scaled := service
+	scaled.RunningCount = 3
+	serviceUI.refreshServices([]pkg.ServiceDetails{scaled})
+	assert.Empty(t, serviceUI.targetHealth)
+}
+
+func TestSetLoadError(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{})
+	serviceUI.SetLoadError(aws.ClusterErrors{"cluster1": errors.New("access denied")})
+
+	assert.Contains(t, serviceUI.header.GetText(true), "1 cluster(s) failed to load")
+}
+
+func TestAPIStatsInHeader(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{})
+	assert.NotContains(t, serviceUI.header.GetText(true), "API:")
+
+	serviceUI.SetAPIStats(aws.NewAPIStats())
+	assert.Contains(t, serviceUI.header.GetText(true), "API: none")
+}
+
+func TestDaemonServices(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	daemon := pkg.ServiceDetails{ServiceName: "agent", RunningCount: 3, DesiredCount: 4, Status: "ACTIVE", SchedulingStrategy: "DAEMON"}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{daemon})
+	serviceUI.updateList()
+
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "(Daemon, Running: 3)")
+	assert.Equal(t, aws.HealthHealthy, serviceUI.serviceHealth(daemon))
+
+	daemon.RunningCount = 0
+	assert.Equal(t, aws.HealthUnhealthy, serviceUI.serviceHealth(daemon))
+	assert.Contains(t, serviceDetailsText(daemon, nil), "Scheduling Strategy:[-] DAEMON")
+}
+
+func TestStoreMetricsUpdatesRowInPlace(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "service2", Cluster: "cluster1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateList()
+	serviceUI.list.SetCurrentItem(1)
+
+	serviceUI.storeMetrics(initialServices[0], pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(12.5), MemoryUtilization: awssdk.Float64(40), SustainedHighMemory: true}, nil)
+
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: 12.50% | Mem: 40.00%")
+	assert.Contains(t, item, "Memory pressure")
+	assert.NotContains(t, item, "CPU pressure")
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+
+	services := []pkg.ServiceDetails{initialServices[0], initialServices[1]}
+	serviceUI.attachMetrics(services)
+	assert.Equal(t, awssdk.Float64(12.5), services[0].Metrics.CPUUtilization)
+	assert.True(t, services[0].SustainedHighMemory)
+	assert.Nil(t, services[1].Metrics)
+}
+
+func TestStoreMetricsUnavailable(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateList()
+
+	serviceUI.storeMetrics(initialServices[0], pkg.ServiceMetrics{}, fmt.Errorf("%w: timed out", aws.ErrMetricsUnavailable))
+
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: n/a | Mem: n/a")
+
+	services := []pkg.ServiceDetails{initialServices[0]}
+	serviceUI.attachMetrics(services)
+	assert.Nil(t, services[0].Metrics)
+}
+
+func TestStoreMetricsAccessDenied(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "service2", Cluster: "cluster1", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateList()
+
+	denied := fmt.Errorf("%w in cluster cluster1", aws.ErrMetricsAccessDenied)
+	serviceUI.storeMetrics(initialServices[0], pkg.ServiceMetrics{}, denied)
+	assert.Contains(t, serviceUI.toast, "No metrics access in cluster cluster1")
+
+	serviceUI.toast = ""
+	serviceUI.storeMetrics(initialServices[1], pkg.ServiceMetrics{}, denied)
+	assert.Empty(t, serviceUI.toast, "each cluster is only reported once")
+
+	item, _ := serviceUI.list.GetItemText(1)
+	assert.Contains(t, item, "CPU: n/a | Mem: n/a [gray](no metrics access)[-]")
+}
+
+func TestDeploymentConfigurationDetails(t *testing.T) {
+	zero, hundred := int64(0), int64(100)
+	service := pkg.ServiceDetails{ServiceName: "api", Status: "ACTIVE", MinimumHealthyPercent: &zero, MaximumPercent: &hundred}
+
+	text := serviceDetailsText(service, nil)
+	assert.Contains(t, text, "Minimum Healthy Percent:[-] 0%")
+	assert.Contains(t, text, "Maximum Percent:[-] 100%")
+	assert.True(t, allowsFullDowntime(service))
+
+	assert.Contains(t, serviceDetailsText(pkg.ServiceDetails{}, nil), "Minimum Healthy Percent:[-] unknown")
+	assert.NotContains(t, serviceDetailsText(pkg.ServiceDetails{Cluster: "prod"}, nil), "Region:")
+	regional := serviceDetailsText(pkg.ServiceDetails{Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/prod"}, nil)
+	assert.Contains(t, regional, "Region:[-] eu-west-1")
+	assert.Contains(t, regional, "Account:[-] 123456789012")
+	assert.False(t, allowsFullDowntime(pkg.ServiceDetails{}))
+	assert.NotContains(t, serviceDetailsText(pkg.ServiceDetails{}, nil), "Platform Version:")
+	assert.Contains(t, serviceDetailsText(pkg.ServiceDetails{PlatformVersion: "LATEST"}, nil), "Platform Version:[-] LATEST")
+
+	network := serviceDetailsText(pkg.ServiceDetails{Network: &pkg.NetworkConfiguration{
+		Subnets:        []string{"subnet-1", "subnet-2"},
+		AssignPublicIP: "ENABLED",
+	}}, nil)
+	assert.Contains(t, network, "Subnets:[-] subnet-1, subnet-2")
+	assert.Contains(t, network, "Security Groups:[-] none")
+	assert.Contains(t, network, "Public IP:[-] ENABLED")
+	assert.NotContains(t, serviceDetailsText(pkg.ServiceDetails{}, nil), "Subnets:")
+}
+
+func TestExecuteCommandAndTagDetails(t *testing.T) {
+	disabled := serviceDetailsText(pkg.ServiceDetails{}, nil)
+	assert.Contains(t, disabled, "ECS Exec:[-] [red]disabled[-]")
+	assert.Contains(t, disabled, "Tag Propagation:[-] none")
+	assert.Contains(t, disabled, "ECS Managed Tags:[-] off")
+
+	enabled := serviceDetailsText(pkg.ServiceDetails{ExecuteCommandEnabled: true, PropagateTags: "TASK_DEFINITION", ManagedTags: true}, nil)
+	assert.Contains(t, enabled, "ECS Exec:[-] [green]enabled[-]")
+	assert.Contains(t, enabled, "Tag Propagation:[-] from the task definition")
+	assert.Contains(t, enabled, "ECS Managed Tags:[-] on")
+}
+
+func TestShellWarnsWhenExecDisabled(t *testing.T) {
+	app := tview.NewApplication()
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{service})
+	serviceUI.updateList()
+	serviceUI.setupListInputCapture()
+
+	// The shell can still be tried, as the setting may be out of date
+	serviceUI.list.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone))
+	button, ok := app.GetFocus().(*tview.Button)
+	assert.True(t, ok)
+	assert.Equal(t, "Try Anyway", button.GetLabel())
+}
+
+func TestCircuitBreakerDetails(t *testing.T) {
+	assert.Contains(t, serviceDetailsText(pkg.ServiceDetails{}, nil), "Circuit Breaker:[-] unknown")
+	assert.Contains(t, serviceDetailsText(pkg.ServiceDetails{CircuitBreaker: &pkg.CircuitBreaker{}}, nil), "Circuit Breaker:[-] off")
+
+	rolledBack := serviceDetailsText(pkg.ServiceDetails{
+		CircuitBreaker:   &pkg.CircuitBreaker{Enabled: true, Rollback: true},
+		FailedDeployment: "ECS deployment circuit breaker: tasks failed to start.",
+		Rollback:         "ECS deployment circuit breaker: rolling back to deploymentId ecs-svc/1.",
+	}, nil)
+	assert.Contains(t, rolledBack, "Circuit Breaker:[-] on, rolls back failed deployments")
+	assert.Contains(t, rolledBack, "Deployment Failed:[-] ECS deployment circuit breaker: tasks failed to start.")
+	assert.Contains(t, rolledBack, "Rollback:[-] ECS deployment circuit breaker: rolling back to deploymentId ecs-svc/1.")
+	assert.NotContains(t, rolledBack, "isn't rolled back")
+
+	stopped := serviceDetailsText(pkg.ServiceDetails{
+		CircuitBreaker:   &pkg.CircuitBreaker{Enabled: true},
+		FailedDeployment: "ECS deployment circuit breaker: tasks failed to start.",
+	}, nil)
+	assert.Contains(t, stopped, "Circuit Breaker:[-] on, without rollback")
+	assert.Contains(t, stopped, "isn't rolled back automatically")
+}
+
+func TestDeploymentsText(t *testing.T) {
+	assert.Empty(t, deploymentsText(pkg.ServiceDetails{Deployments: []pkg.Deployment{{Status: "PRIMARY"}}}))
+
+	rolling := deploymentsText(pkg.ServiceDetails{Deployments: []pkg.Deployment{
+		{Status: "PRIMARY", TaskDefinition: "arn:aws:ecs:eu-west-1:123456789012:task-definition/api:8", DesiredCount: 4, RunningCount: 1, PendingCount: 2, RolloutState: "IN_PROGRESS"},
+		{Status: "ACTIVE", TaskDefinition: "arn:aws:ecs:eu-west-1:123456789012:task-definition/api:7", DesiredCount: 4, RunningCount: 3, RolloutState: "COMPLETED"},
+	}})
+	assert.Contains(t, rolling, "[green]PRIMARY[-] api:8 "+splitBar(25)+"  25% of running tasks, 1/4 running, 2 pending [blue](in progress)[-]")
+	assert.Contains(t, rolling, "ACTIVE api:7 "+splitBar(75)+"  75% of running tasks, 3/4 running\n")
+
+	ten := 10.0
+	canary := deploymentsText(pkg.ServiceDetails{Deployments: []pkg.Deployment{
+		{Status: "ACTIVE", TaskDefinition: "api:8", ScalePercent: &ten},
+		{Status: "PRIMARY", TaskDefinition: "api:7"},
+	}})
+	assert.Contains(t, canary, "ACTIVE api:8 "+splitBar(10)+"  10% scale")
+	assert.Contains(t, canary, "api:7 "+splitBar(0)+"   0% of running tasks")
+
+	assert.Equal(t, "[green]"+strings.Repeat("█", 5)+"[-][gray]"+strings.Repeat("░", 15)+"[-]", splitBar(25))
+}
+
+func TestPlacementBlockedServices(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Status: "ACTIVE", RunningCount: 1, DesiredCount: 3, PlacementBlocked: true},
+		{ServiceName: "web", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2},
+	}
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+
+	blocked, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, blocked, "Placement blocked")
+	healthy, _ := serviceUI.list.GetItemText(1)
+	assert.NotContains(t, healthy, "Placement blocked")
+	assert.Contains(t, serviceDetailsText(services[0], nil), "unable to place tasks")
+}
+
+func TestStoppedTasksText(t *testing.T) {
+	oom, clean := int32(137), int32(0)
+	tasks := []pkg.TaskDetails{
+		{
+			TaskArn:       "arn:aws:ecs:us-east-1:123456789012:task/prod/abc123",
+			StoppedReason: "Essential container in task exited",
+			Containers: []pkg.ContainerDetails{
+				{Name: "app", ExitCode: &oom, Reason: "OutOfMemoryError"},
+				{Name: "sidecar", ExitCode: &clean},
+			},
+		},
+	}
+
+	text := stoppedTasksText(tasks)
+	assert.Contains(t, text, "abc123")
+	assert.Contains(t, text, "Essential container in task exited")
+	assert.Contains(t, text, "exit code [red]137[-] - OutOfMemoryError")
+	assert.Contains(t, text, "exit code 0")
+	assert.Contains(t, stoppedTasksText(nil), "No recently stopped tasks")
 }
 
 func TestFilterServices(t *testing.T) {
@@ -76,7 +388,7 @@ func TestFilterServices(t *testing.T) {
 		{ServiceName: "other", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
 	}
 
-	serviceUI := NewServiceUI(app, ctx, mockClient, initialServices)
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
 
 	// Test filtering
 	serviceUI.filterServices("service")
@@ -93,6 +405,207 @@ func TestFilterServices(t *testing.T) {
 	// Test empty query
 	serviceUI.filterServices("")
 	assert.Equal(t, 3, len(serviceUI.filteredServices))
+
+	// Test multiple terms must all match
+	serviceUI.filterServices("serv 2")
+	assert.Equal(t, 1, len(serviceUI.filteredServices))
+	assert.Equal(t, "service2", serviceUI.filteredServices[0].ServiceName)
+	serviceUI.filterServices("service other")
+	assert.Equal(t, 0, len(serviceUI.filteredServices))
+}
+
+func TestRegexFilterServices(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service2", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "other", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+
+	serviceUI.filterServices("re:^SERVICE[12]$")
+	assert.Equal(t, 2, len(serviceUI.filteredServices))
+
+	// An invalid regex keeps the previous results and marks the query red
+	serviceUI.filterServices("re:^service(")
+	assert.Equal(t, 2, len(serviceUI.filteredServices))
+	fieldColor, _, _ := serviceUI.searchInput.GetFieldStyle().Decompose()
+	assert.Equal(t, tcell.ColorRed, fieldColor)
+
+	// Refreshed services keep being filtered with the last valid query
+	refreshed := []pkg.ServiceDetails{
+		{ServiceName: "other", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "service1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+	}
+	serviceUI.currentServices = refreshed
+	serviceUI.filterServices("re:^service(")
+	assert.Equal(t, refreshed[1:], serviceUI.filteredServices)
+
+	serviceUI.filterServices("re:^oth")
+	assert.Equal(t, 1, len(serviceUI.filteredServices))
+}
+
+func TestCountConditionFilterServices(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", RunningCount: 12, DesiredCount: 12, Status: "ACTIVE"},
+		{ServiceName: "api-worker", RunningCount: 3, DesiredCount: 4, Status: "ACTIVE"},
+		{ServiceName: "idle", RunningCount: 0, DesiredCount: 0, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	names := func() []string {
+		var names []string
+		for _, service := range serviceUI.filteredServices {
+			names = append(names, service.ServiceName)
+		}
+		return names
+	}
+
+	serviceUI.filterServices("desired>=10")
+	assert.Equal(t, []string{"api"}, names())
+	serviceUI.filterServices("desired==0")
+	assert.Equal(t, []string{"idle"}, names())
+	serviceUI.filterServices("Running<4")
+	assert.Equal(t, []string{"api-worker", "idle"}, names())
+	serviceUI.filterServices("running<=3 desired>0")
+	assert.Equal(t, []string{"api-worker"}, names())
+
+	// Conditions combine with name terms and regexes
+	serviceUI.filterServices("api desired<12")
+	assert.Equal(t, []string{"api-worker"}, names())
+	serviceUI.filterServices("desired>3 re:^api$")
+	assert.Equal(t, []string{"api"}, names())
+
+	// A half-typed condition keeps the previous results and marks the query red
+	serviceUI.filterServices("desired>=")
+	assert.Equal(t, []string{"api"}, names())
+	fieldColor, _, _ := serviceUI.searchInput.GetFieldStyle().Decompose()
+	assert.Equal(t, tcell.ColorRed, fieldColor)
+
+	for _, term := range []string{"desired=5", "running!=0", "desired>x"} {
+		_, ok, err := parseCountCondition(term)
+		assert.False(t, ok, term)
+		assert.Error(t, err, term)
+	}
+	_, ok, err := parseCountCondition("desiredstate")
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestRefreshServicesPreservesSelection(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service2", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service3", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateList()
+	serviceUI.list.SetCurrentItem(1)
+
+	var refreshed []pkg.ServiceDetails
+	serviceUI.OnRefresh(func(services []pkg.ServiceDetails) {
+		refreshed = services
+	})
+
+	serviceUI.refreshServices([]pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service2", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service3", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+	})
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+	assert.Len(t, refreshed, 3)
+
+	// A selected service that disappears falls back to the top of the list
+	serviceUI.refreshServices([]pkg.ServiceDetails{
+		{ServiceName: "service1", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "service3", Cluster: "cluster1", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+	})
+	assert.Equal(t, 0, serviceUI.list.GetCurrentItem())
+}
+
+func TestGroupFilter(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "payments-dev", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "api", Cluster: "search-prod", Group: "search", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	assert.Equal(t, []string{"payments", "search"}, serviceGroups(serviceUI.currentServices))
+
+	serviceUI.setGroupFilter("payments")
+	assert.Equal(t, 2, len(serviceUI.filteredServices))
+
+	// Group filter combines with the search query
+	serviceUI.filterServices("api")
+	assert.Equal(t, 1, len(serviceUI.filteredServices))
+	assert.Equal(t, "payments-prod", serviceUI.filteredServices[0].Cluster)
+
+	serviceUI.setGroupFilter("")
+	assert.Equal(t, 3, len(serviceUI.filteredServices))
+}
+
+func TestToggleClusterFilter(t *testing.T) {
+	app := tview.NewApplication()
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "api", Cluster: "search-prod", Group: "search", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, initialServices)
+	serviceUI.updateList()
+	serviceUI.list.SetCurrentItem(1)
+
+	serviceUI.toggleClusterFilter()
+	assert.Equal(t, initialServices[:2], serviceUI.filteredServices)
+	assert.True(t, serviceUI.isFiltered())
+	assert.Contains(t, serviceUI.header.GetText(false), "Cluster: payments-prod")
+	selected, _ := serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+
+	serviceUI.toggleClusterFilter()
+	assert.Equal(t, 3, len(serviceUI.filteredServices))
+	assert.False(t, serviceUI.isFiltered())
+	selected, _ = serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+}
+
+func TestResetFilters(t *testing.T) {
+	app := tview.NewApplication()
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"},
+		{ServiceName: "api", Cluster: "search-prod", Group: "search", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, initialServices)
+	serviceUI.updateList()
+	serviceUI.setGroupFilter("payments")
+	serviceUI.toggleClusterFilter()
+	serviceUI.filterServices("work")
+	assert.Equal(t, 1, len(serviceUI.filteredServices))
+
+	serviceUI.resetFilters()
+	assert.False(t, serviceUI.isFiltered())
+	assert.Equal(t, "", serviceUI.state.GroupFilter)
+	assert.Equal(t, 3, len(serviceUI.filteredServices))
+	selected, _ := serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+	assert.Equal(t, "Filters cleared", serviceUI.toast)
 }
 
 func TestSetupSearchInput(t *testing.T) {
@@ -104,7 +617,7 @@ func TestSetupSearchInput(t *testing.T) {
 		{ServiceName: "service2", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
 	}
 
-	serviceUI := NewServiceUI(app, ctx, mockClient, initialServices)
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
 	serviceUI.setupSearchInput()
 
 	// Test ESC key
@@ -147,7 +660,7 @@ func TestSetupListInputCapture(t *testing.T) {
 		{ServiceName: "service2", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
 	}
 
-	serviceUI := NewServiceUI(app, ctx, mockClient, initialServices)
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
 	serviceUI.setupListInputCapture()
 
 	var capturedEvent *tcell.EventKey
@@ -186,3 +699,1432 @@ func TestSetupListInputCapture(t *testing.T) {
 }
 
 // Add more tests for other functions as needed
+
+func TestClusterPickerSelection(t *testing.T) {
+	picker := newClusterPicker([]string{"payments-prod", "api-prod", "api-dev"}, []string{"api-prod"})
+
+	assert.Equal(t, []string{"api-prod"}, picker.selectedClusters())
+
+	// Items are sorted, so index 0 is api-dev
+	picker.toggle(0)
+	assert.Equal(t, []string{"api-dev", "api-prod"}, picker.selectedClusters())
+	mainText, _ := picker.list.GetItemText(0)
+	assert.Contains(t, mainText, "x")
+
+	picker.toggleAll()
+	assert.Equal(t, []string{"api-dev", "api-prod", "payments-prod"}, picker.selectedClusters())
+	picker.toggleAll()
+	assert.Empty(t, picker.selectedClusters())
+}
+
+func TestEventsPanelAppendsOnlyNewEvents(t *testing.T) {
+	panel := newEventsPanel(pkg.ServiceDetails{ServiceName: "api"})
+
+	started := pkg.ServiceEvent{ID: "1", Message: "(service api) has started 1 tasks."}
+	steady := pkg.ServiceEvent{ID: "2", Message: "(service api) has reached a steady state."}
+
+	assert.Equal(t, 1, panel.appendEvents([]pkg.ServiceEvent{started}))
+	assert.Equal(t, 1, panel.appendEvents([]pkg.ServiceEvent{started, steady}))
+	assert.Equal(t, 0, panel.appendEvents([]pkg.ServiceEvent{started, steady}))
+
+	text := panel.view.GetText(true)
+	assert.Equal(t, 1, strings.Count(text, "has started 1 tasks"))
+	assert.Less(t, strings.Index(text, "has started"), strings.Index(text, "steady state"))
+
+	panel.setFollowing(true)
+	assert.Contains(t, panel.view.GetTitle(), "following")
+	panel.setFollowing(false)
+	assert.NotContains(t, panel.view.GetTitle(), "following")
+}
+
+func TestCountHistory(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 3, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	for _, running := range []int64{3, 3} {
+		serviceUI.refreshServices([]pkg.ServiceDetails{
+			{ServiceName: "api", Cluster: "prod", RunningCount: running, DesiredCount: 3, Status: "ACTIVE"},
+		})
+	}
+
+	history := serviceUI.serviceHistory(services[0])
+	text := serviceDetailsText(services[0], history)
+	assert.Contains(t, text, "Running Trend:[-] 2→3→3 over last 3 polls")
+	assert.Contains(t, text, "Desired Trend:[-] 3→3→3 over last 3 polls")
+	assert.NotContains(t, serviceDetailsText(services[0], history[:1]), "Trend")
+
+	// Only the most recent historySize samples are kept
+	var ring countHistory
+	for i := int64(0); i < historySize+2; i++ {
+		ring.add(countSample{running: i})
+	}
+	values := ring.values()
+	assert.Len(t, values, historySize)
+	assert.Equal(t, int64(2), values[0].running)
+	assert.Equal(t, int64(historySize+1), values[historySize-1].running)
+}
+
+func TestClusterOverviewTable(t *testing.T) {
+	services := []pkg.ServiceDetails{
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "api", RunningCount: 4, DesiredCount: 4},
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/prod", ServiceName: "web", RunningCount: 1, DesiredCount: 2},
+		{Cluster: "arn:aws:ecs:us-east-1:123456789012:cluster/dev", ServiceName: "api", RunningCount: 1, DesiredCount: 1},
+	}
+	summaries := summary.Summarize(services)
+	table := tview.NewTable()
+
+	fillOverviewTable(table, summaries, summary.SortByName)
+	assert.Equal(t, 4, table.GetRowCount())
+	assert.Equal(t, "dev", table.GetCell(1, 0).Text)
+	assert.Equal(t, "Total", table.GetCell(3, 0).Text)
+	assert.Equal(t, "7", table.GetCell(3, 3).Text)
+
+	fillOverviewTable(table, summaries, summary.SortByRunning)
+	assert.Equal(t, "prod", table.GetCell(1, 0).Text)
+	assert.Equal(t, "5", table.GetCell(1, 2).Text)
+}
+
+func TestDeployingSpinnerAdvancesOnPoll(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Status: "ACTIVE", RunningCount: 2, DesiredCount: 3, Deploying: true},
+		{ServiceName: "web", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	first, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, first, string(spinnerFrames[0]))
+	assert.Contains(t, first, "Deploying")
+	stable, _ := serviceUI.list.GetItemText(1)
+	assert.NotContains(t, stable, "Deploying")
+
+	serviceUI.refreshServices(services)
+	second, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, second, string(spinnerFrames[1]))
+}
+
+func TestPinnedServicesListedFirst(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "web", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, initialServices)
+	serviceUI.updateList()
+
+	serviceUI.togglePin(initialServices[2])
+	assert.Equal(t, []string{"prod/worker"}, serviceUI.state.PinnedServices)
+	assert.Equal(t, "worker", serviceUI.filteredServices[0].ServiceName)
+	assert.Equal(t, 0, serviceUI.list.GetCurrentItem())
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "★")
+
+	// Pins survive filtering and polling
+	serviceUI.filterServices("w")
+	assert.Equal(t, "worker", serviceUI.filteredServices[0].ServiceName)
+	assert.Equal(t, "web", serviceUI.filteredServices[1].ServiceName)
+	serviceUI.refreshServices(initialServices)
+	assert.Equal(t, "worker", serviceUI.filteredServices[0].ServiceName)
+
+	serviceUI.togglePin(initialServices[2])
+	assert.Empty(t, serviceUI.state.PinnedServices)
+	assert.Equal(t, "api", serviceUI.filteredServices[0].ServiceName)
+	assert.Equal(t, "worker", serviceUI.filteredServices[2].ServiceName)
+}
+
+func TestMutedServicesLeftOutOfUnhealthyCount(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := tview.NewApplication()
+	ctx := context.Background()
+	initialServices := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "batch", Cluster: "prod", RunningCount: 0, DesiredCount: 1, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 0, DesiredCount: 2, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, &ecs.Client{}, nil, initialServices)
+	serviceUI.updateList()
+	assert.Contains(t, serviceUI.header.GetText(true), "Unhealthy: 2")
+
+	serviceUI.toggleMute(initialServices[1])
+	assert.Equal(t, []string{"prod/batch"}, serviceUI.state.MutedServices)
+	assert.Contains(t, serviceUI.header.GetText(true), "Unhealthy: 1 | Degraded: 0 | Muted: 1")
+	// Muted services are still listed, with a marker
+	assert.Len(t, serviceUI.filteredServices, 3)
+	item, _ := serviceUI.list.GetItemText(1)
+	assert.Contains(t, item, "(muted)")
+
+	serviceUI.toggleMute(initialServices[1])
+	assert.Empty(t, serviceUI.state.MutedServices)
+	assert.Contains(t, serviceUI.header.GetText(true), "Unhealthy: 2")
+	assert.NotContains(t, serviceUI.header.GetText(true), "Muted")
+}
+
+func TestTargetHealthText(t *testing.T) {
+	targetGroup := "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/abc123"
+
+	text := targetHealthText([]pkg.TargetHealth{
+		{TaskArn: "arn:aws:ecs:us-east-1:123456789012:task/prod/healthy1", TargetGroupArn: targetGroup, State: "healthy"},
+		{TaskArn: "arn:aws:ecs:us-east-1:123456789012:task/prod/missing1", TargetGroupArn: targetGroup, State: aws.TargetStateUnregistered},
+	}, nil)
+	assert.Contains(t, text, "missing1 in api: [red]unregistered[-]")
+	assert.NotContains(t, text, "healthy1")
+
+	assert.Contains(t, targetHealthText([]pkg.TargetHealth{{TargetGroupArn: targetGroup, State: "healthy"}}, nil), "all 1 registrations healthy")
+	assert.Contains(t, targetHealthText(nil, errors.New("access denied")), "access denied")
+}
+
+func TestResourceUsageText(t *testing.T) {
+	service := pkg.ServiceDetails{ServiceName: "api", RunningCount: 4}
+	reservation := pkg.TaskReservation{CPU: 512, MemoryMiB: 1024}
+
+	assert.Contains(t, resourceUsageText(service, reservation, nil), "utilization not loaded yet")
+
+	service.SetMetrics(pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(50), MemoryUtilization: awssdk.Float64(25), Source: "ECS/ContainerInsights"})
+	text := resourceUsageText(service, reservation, nil)
+	assert.Contains(t, text, "Reserved per Task:[-] 512 CPU units, 1024 MiB memory")
+	assert.Contains(t, text, "Utilization Source:[-] ECS/ContainerInsights")
+	assert.Contains(t, text, "CPU In Use:[-] 50.0% ≈ 256 of 512 units per task, 1024 units across 4 tasks")
+	assert.Contains(t, text, "Memory In Use:[-] 25.0% ≈ 256 of 1024 MiB per task, 1024 MiB across 4 tasks")
+
+	text = resourceUsageText(service, pkg.TaskReservation{MemoryMiB: 1024}, nil)
+	assert.Contains(t, text, "no CPU units")
+	assert.Contains(t, text, "CPU In Use:[-] 50.0% (no reservation to compare against)")
+
+	assert.Contains(t, resourceUsageText(service, pkg.TaskReservation{}, errors.New("access denied")), "access denied")
+}
+
+func TestStandaloneTasksText(t *testing.T) {
+	failed, succeeded := int32(1), int32(0)
+	tasks := []pkg.TaskDetails{
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/abc", TaskDefinition: "arn:aws:ecs:eu-west-1:123456789012:task-definition/report:3", LastStatus: "RUNNING", StartedBy: "events-rule/nightly"},
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/def", LastStatus: "STOPPED", StoppedReason: "Essential container in task exited",
+			Containers: []pkg.ContainerDetails{{Name: "report", ExitCode: &failed}}},
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/ghi", LastStatus: "STOPPED",
+			Containers: []pkg.ContainerDetails{{Name: "report", ExitCode: &succeeded}}},
+	}
+
+	text := standaloneTasksText(tasks, "")
+	assert.Contains(t, text, "Task:[-] abc (report:3)")
+	assert.Contains(t, text, "Started By:[-] events-rule/nightly")
+	assert.Contains(t, text, "Status:[-] [red]STOPPED[-]")
+	assert.Contains(t, text, "Status:[-] [green]STOPPED[-]")
+	assert.Contains(t, text, "Container report:[-] exit code [red]1[-]")
+
+	assert.Contains(t, standaloneTasksText(nil, ""), "No running or recently stopped standalone tasks")
+	assert.Contains(t, standaloneTasksText(nil, "events-rule/nightly"), "started by events-rule/nightly")
+}
+
+func TestCycleVerbosity(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE", PlacementBlocked: true},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.storeMetrics(services[0], pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(12.5), MemoryUtilization: awssdk.Float64(40)}, nil)
+	serviceUI.updateList()
+	serviceUI.list.SetCurrentItem(1)
+
+	// All columns are shown by default
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: 12.50%")
+
+	serviceUI.cycleVerbosity()
+	assert.Equal(t, verbosityName, serviceUI.state.ListVerbosity)
+	item, _ = serviceUI.list.GetItemText(0)
+	assert.Equal(t, "api", item)
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+
+	serviceUI.cycleVerbosity()
+	item, _ = serviceUI.list.GetItemText(0)
+	assert.Equal(t, "api (Running: 1, Desired: 2)", item)
+
+	serviceUI.cycleVerbosity()
+	item, _ = serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "Status: [yellow]ACTIVE[-]")
+	assert.Contains(t, item, "Placement blocked")
+	assert.NotContains(t, item, "CPU:")
+
+	serviceUI.cycleVerbosity()
+	assert.Equal(t, verbosityMetrics, serviceUI.state.ListVerbosity)
+	item, _ = serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: 12.50%")
+}
+
+func TestMissingMetricsDistinctFromZero(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{{ServiceName: "idle", Cluster: "prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.storeMetrics(services[0], pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(0)}, nil)
+
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: 0.00% | Mem: n/a")
+
+	services[0].SetMetrics(pkg.ServiceMetrics{})
+	assert.Contains(t, resourceUsageText(services[0], pkg.TaskReservation{CPU: 256}, nil), "CPU In Use:[-] n/a")
+}
+
+func TestAdjustDesiredCountGuards(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", RunningCount: 0, DesiredCount: 0, Status: "ACTIVE"}
+	agent := pkg.ServiceDetails{ServiceName: "agent", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE", SchedulingStrategy: "DAEMON"}
+	worker := pkg.ServiceDetails{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{api, agent, worker})
+	serviceUI.updateList()
+
+	serviceUI.adjustDesiredCount(api, -1)
+	assert.Equal(t, "api is already at a desired count of 0", serviceUI.toast)
+	assert.Contains(t, serviceUI.header.GetText(false), "already at a desired count of 0")
+
+	serviceUI.adjustDesiredCount(agent, 1)
+	assert.Contains(t, serviceUI.toast, "daemon service")
+
+	serviceUI.scalePending[serviceKey(worker)] = true
+	serviceUI.adjustDesiredCount(worker, 1)
+	assert.Equal(t, "Still updating worker, try again in a moment", serviceUI.toast)
+}
+
+func TestSetDesiredCount(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.list.SetCurrentItem(1)
+
+	serviceUI.setDesiredCount(services[0], 3)
+
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "Running: 2, Desired: 3")
+	assert.Equal(t, int64(3), serviceUI.currentServices[0].DesiredCount)
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+}
+
+func TestIdleTimeoutPausesAndResumesPolling(t *testing.T) {
+	app := tview.NewApplication()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockClient := &ecs.Client{}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{})
+	serviceUI.SetIdleTimeout(time.Hour)
+	stopped := false
+	serviceUI.stopPolling = func() { stopped = true }
+
+	// Input within the timeout keeps polling running
+	serviceUI.pauseIfIdle()
+	assert.False(t, serviceUI.paused)
+
+	serviceUI.lastInput = time.Now().Add(-2 * time.Hour)
+	serviceUI.pauseIfIdle()
+	assert.True(t, serviceUI.paused)
+	assert.True(t, stopped)
+	assert.Contains(t, serviceUI.header.GetText(false), "Paused — press any key to resume")
+
+	serviceUI.resumePolling()
+	assert.False(t, serviceUI.paused)
+	assert.NotContains(t, serviceUI.header.GetText(false), "Paused")
+}
+
+func TestClickSelectsServiceWithoutOpeningOptions(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"},
+		{ServiceName: "web", Cluster: "prod", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.setupMouse()
+	serviceUI.list.SetRect(0, 0, 80, 5)
+	click := func(y int) {
+		serviceUI.list.MouseHandler()(tview.MouseLeftClick, tcell.NewEventMouse(5, y, tcell.Button1, 0), func(p tview.Primitive) {})
+	}
+
+	// Each service takes two lines
+	click(3)
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+	assert.Equal(t, serviceUI.list, app.GetFocus())
+
+	// Clicking below the last service changes nothing
+	click(4)
+	assert.Equal(t, 1, serviceUI.list.GetCurrentItem())
+	assert.Equal(t, -1, serviceUI.listIndexAt(5, 4))
+	assert.Equal(t, 0, serviceUI.listIndexAt(5, 1))
+	assert.Equal(t, 1, serviceUI.listIndexAt(5, 2))
+}
+
+func TestListTableUsesFilterAndCachedMetrics(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2}
+	worker := pkg.ServiceDetails{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE", RunningCount: 1, DesiredCount: 1}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{api, worker})
+	serviceUI.storeMetrics(api, pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(10), MemoryUtilization: awssdk.Float64(20)}, nil)
+	serviceUI.filterServices("api")
+
+	table, err := serviceUI.listTable()
+	assert.NoError(t, err)
+	assert.Contains(t, table, "api")
+	assert.Contains(t, table, "10.00%")
+	assert.NotContains(t, table, "worker")
+}
+
+func TestConsumeUpdatesStopsWhenCancelledDuringUpdate(t *testing.T) {
+	// The application isn't running, so queued updates are never applied, as
+	// after it has stopped
+	app := tview.NewApplication()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 1, DesiredCount: 1}
+
+	serviceUI := NewServiceUI(app, ctx, &ecs.Client{}, nil, []pkg.ServiceDetails{api})
+	updates := make(chan aws.PollUpdate)
+	done := make(chan struct{})
+	go func() {
+		serviceUI.consumeUpdates(ctx, updates)
+		close(done)
+	}()
+
+	updated := api
+	updated.RunningCount = 0
+	updates <- aws.PollUpdate{Services: []pkg.ServiceDetails{updated}}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("consumeUpdates did not return after cancellation")
+	}
+	// Nothing is consumed once cancelled
+	select {
+	case updates <- aws.PollUpdate{MetricsDue: true}:
+		t.Fatal("update consumed after cancellation")
+	default:
+	}
+}
+
+func TestSaveAndApplyView(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "payments-prod", Group: "payments", Status: "ACTIVE"}
+	web := pkg.ServiceDetails{ServiceName: "web", Cluster: "shop-prod", Group: "shop", Status: "ACTIVE"}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, []pkg.ServiceDetails{api, web})
+	serviceUI.setupSearchInput()
+	// The search input only replaces its text properly once it has a size
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	serviceUI.searchInput.SetRect(0, 0, 40, 1)
+	serviceUI.searchInput.Draw(screen)
+
+	serviceUI.setGroupFilter("payments")
+	serviceUI.togglePin(api)
+	serviceUI.searchInput.SetText("ap")
+	serviceUI.saveView("payments-team")
+
+	// Pins saved in a view aren't affected by later changes
+	serviceUI.togglePin(api)
+	serviceUI.setGroupFilter("")
+	serviceUI.searchInput.SetText("")
+	serviceUI.cycleVerbosity()
+	assert.Len(t, serviceUI.filteredServices, 2)
+
+	assert.Equal(t, []string{"payments-team"}, serviceUI.viewNames())
+	serviceUI.applyView(serviceUI.state.Views["payments-team"])
+	assert.Equal(t, "ap", serviceUI.searchInput.GetText())
+	assert.Equal(t, "payments", serviceUI.state.GroupFilter)
+	assert.True(t, serviceUI.isPinned(api))
+	assert.Equal(t, "", serviceUI.state.ListVerbosity)
+	assert.Equal(t, []pkg.ServiceDetails{api}, serviceUI.filteredServices)
+
+	serviceUI.deleteView("payments-team")
+	assert.Empty(t, serviceUI.viewNames())
+}
+
+func TestViewRestoresClusterScope(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := tview.NewApplication()
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "payments-prod", Status: "ACTIVE"}
+	worker := pkg.ServiceDetails{ServiceName: "worker", Cluster: "payments-prod", Status: "ACTIVE"}
+	web := pkg.ServiceDetails{ServiceName: "web", Cluster: "shop-prod", Status: "ACTIVE"}
+	search := pkg.ServiceDetails{ServiceName: "search", Cluster: "search-prod", Status: "ACTIVE"}
+
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{api, worker, web})
+	serviceUI.SetPickedClusters([]string{"payments-prod", "shop-prod"})
+	serviceUI.updateList()
+	serviceUI.toggleClusterFilter()
+	serviceUI.saveView("payments")
+
+	view := serviceUI.state.Views["payments"]
+	assert.Equal(t, "payments-prod", view.ClusterFilter)
+	assert.Equal(t, []string{"payments-prod", "shop-prod"}, view.Clusters)
+
+	// Restored after the filter was lifted, and with a cluster loaded later
+	// that the view didn't cover
+	serviceUI.toggleClusterFilter()
+	serviceUI.currentServices = append(serviceUI.currentServices, search)
+	serviceUI.filterServices("")
+	assert.Len(t, serviceUI.filteredServices, 4)
+
+	serviceUI.applyView(view)
+	assert.Equal(t, []pkg.ServiceDetails{api, worker}, serviceUI.filteredServices)
+	assert.Contains(t, serviceUI.header.GetText(false), "Cluster: payments-prod")
+	assert.Contains(t, serviceUI.header.GetText(false), "Clusters: 2")
+
+	serviceUI.toggleClusterFilter()
+	assert.Equal(t, []pkg.ServiceDetails{api, worker, web}, serviceUI.filteredServices)
+	assert.Equal(t, []string{"payments-prod", "shop-prod"}, serviceUI.state.SelectedClusters)
+
+	serviceUI.resetFilters()
+	assert.Len(t, serviceUI.filteredServices, 4)
+	assert.False(t, serviceUI.isFiltered())
+}
+
+// fakeECSClient serves the calls made while polling and scaling from an
+// in-memory set of desired counts. Other calls panic on the nil interface.
+type fakeECSClient struct {
+	aws.ECSClientAPI
+	mu        sync.Mutex
+	desired   map[string]int32
+	describes int
+}
+
+func (f *fakeECSClient) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.describes++
+	output := &ecs.DescribeServicesOutput{}
+	for _, name := range params.Services {
+		output.Services = append(output.Services, ecstypes.Service{
+			ServiceName:  awssdk.String(name),
+			Status:       awssdk.String("ACTIVE"),
+			DesiredCount: f.desired[name],
+			RunningCount: f.desired[name],
+		})
+	}
+	return output, nil
+}
+
+func (f *fakeECSClient) UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if params.DesiredCount != nil {
+		f.desired[*params.Service] = *params.DesiredCount
+	}
+	return &ecs.UpdateServiceOutput{}, nil
+}
+
+func (f *fakeECSClient) describeCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.describes
+}
+
+func TestFollowAfterRestartAndScale(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	client := &fakeECSClient{desired: map[string]int32{"api": 2}}
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2}
+	layout := tview.NewFlex()
+
+	following := func(app *tview.Application) bool {
+		view, ok := app.GetFocus().(*tview.TextView)
+		return ok && strings.Contains(view.GetTitle(), "following")
+	}
+
+	app := tview.NewApplication()
+	restartService(app, ctx, client, service, layout)
+	assert.True(t, following(app), "a restart is followed")
+
+	app = tview.NewApplication()
+	showDesiredCountPrompt(app, ctx, client, nil, service, nil, layout)
+	input := app.GetFocus().(*tview.InputField)
+	input.SetText("4")
+	input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	assert.True(t, following(app), "a scale is followed")
+	assert.Equal(t, int32(4), client.desired["api"])
+}
+
+// slowScalingClient doesn't answer until its context is done
+type slowScalingClient struct {
+	aws.AutoScalingClientAPI
+}
+
+func (c *slowScalingClient) DescribeScalableTargets(ctx context.Context, params *applicationautoscaling.DescribeScalableTargetsInput, optFns ...func(*applicationautoscaling.Options)) (*applicationautoscaling.DescribeScalableTargetsOutput, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestDesiredCountPrompt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &fakeECSClient{desired: map[string]int32{"api": 2}}
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2}
+	layout := tview.NewFlex()
+
+	// The prompt opens while the bounds are still being checked, and waits
+	// for them before changing anything
+	app := tview.NewApplication()
+	showDesiredCountPrompt(app, ctx, client, &slowScalingClient{}, service, nil, layout)
+	input := app.GetFocus().(*tview.InputField)
+	assert.Contains(t, input.GetLabel(), "checking auto scaling bounds")
+	input.SetText("4")
+	input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	assert.Equal(t, int32(2), client.desired["api"])
+
+	app = tview.NewApplication()
+	showDesiredCountPrompt(app, ctx, client, nil, service, nil, layout)
+	input = app.GetFocus().(*tview.InputField)
+	input.SetText("-1")
+	input.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), nil)
+	_, isModal := app.GetFocus().(*tview.Button)
+	assert.True(t, isModal, "a negative count is refused")
+	assert.Equal(t, int32(2), client.desired["api"])
+}
+
+// TestPollingWhileHandlingKeys runs the UI with fast polling while keys that
+// read and change the service list are pressed. Run it with -race to check
+// that service state is only touched from the UI goroutine.
+func TestPollingWhileHandlingKeys(t *testing.T) {
+	defaultInterval := PollInterval
+	PollInterval = time.Millisecond
+	defer func() { PollInterval = defaultInterval }()
+
+	screen := tcell.NewSimulationScreen("")
+	app := tview.NewApplication().SetScreen(screen)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &fakeECSClient{desired: map[string]int32{"api": 2, "worker": 1}}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2},
+		{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE", RunningCount: 1, DesiredCount: 1},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, client, nil, services)
+	serviceUI.updateList()
+	serviceUI.setupSearchInput()
+	serviceUI.setupListInputCapture()
+	serviceUI.startPolling()
+	app.SetRoot(serviceUI.layout, true).SetFocus(serviceUI.list)
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run() }()
+
+	for i := 0; i < 20; i++ {
+		screen.InjectKey(tcell.KeyRune, '+', tcell.ModNone)
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'p', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'v', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, '-', tcell.ModNone)
+		screen.InjectKey(tcell.KeyUp, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, '/', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'a', tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+		time.Sleep(time.Millisecond)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.describeCount() < 20 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, client.describeCount(), 20)
+
+	cancel()
+	app.Stop()
+	assert.NoError(t, <-done)
+}
+
+func TestRenderOnce(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2},
+		{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE", RunningCount: 0, DesiredCount: 1},
+	}
+
+	var buf bytes.Buffer
+	err := RenderOnce(context.Background(), &buf, nil, services, errors.New("staging: access denied"), 120)
+	assert.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "\x1b[", "the output keeps the UI's colors")
+	plain := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(output, "")
+	assert.Contains(t, plain, "Total Services: 2 | Unhealthy: 1")
+	assert.Contains(t, plain, "staging: access denied")
+	assert.Contains(t, plain, "api")
+	assert.Contains(t, plain, "worker")
+	for _, line := range strings.Split(plain, "\n") {
+		assert.Equal(t, strings.TrimRight(line, " "), line, "trailing blanks are trimmed")
+	}
+}
+
+func TestRefreshMetricsMarksCacheStale(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.storeMetrics(services[0], pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(10)}, nil)
+
+	serviceUI.refreshMetrics()
+
+	entry := serviceUI.metrics[serviceKey(services[0])]
+	assert.True(t, entry.fetchedAt.IsZero())
+	// Stale metrics stay on screen until the refetch arrives
+	item, _ := serviceUI.list.GetItemText(0)
+	assert.Contains(t, item, "CPU: 10.00%")
+}
+
+func TestRefreshServiceMetricsGuards(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.refreshServiceMetrics(services[0])
+	assert.Equal(t, "Metrics are not available", serviceUI.toast)
+
+	serviceUI.cwClient = &cloudwatch.Client{}
+	serviceUI.metricsPending[serviceKey(services[0])] = true
+	serviceUI.refreshServiceMetrics(services[0])
+	assert.Equal(t, "Still loading metrics for api", serviceUI.toast)
+}
+
+func TestChangedServicesHighlightedForOnePoll(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+
+	serviceUI.refreshServices([]pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE"},
+	})
+	api, _ := serviceUI.list.GetItemText(0)
+	worker, _ := serviceUI.list.GetItemText(1)
+	assert.True(t, strings.HasPrefix(api, changedHighlight))
+	assert.False(t, strings.HasPrefix(worker, changedHighlight))
+
+	// Unchanged on the next poll, so the highlight goes away
+	serviceUI.refreshServices([]pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "DRAINING"},
+	})
+	api, _ = serviceUI.list.GetItemText(0)
+	worker, _ = serviceUI.list.GetItemText(1)
+	assert.False(t, strings.HasPrefix(api, changedHighlight))
+	assert.True(t, strings.HasPrefix(worker, changedHighlight))
+}
+
+func TestRestartScopedToFilter(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	zero := int64(0)
+	services := []pkg.ServiceDetails{
+		{ServiceName: "payments-api", Cluster: "prod", Status: "ACTIVE", MinimumHealthyPercent: &zero, DesiredCount: 1},
+		{ServiceName: "payments-worker", Cluster: "prod", Status: "ACTIVE"},
+		{ServiceName: "search", Cluster: "prod", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	assert.False(t, serviceUI.isFiltered())
+	assert.Equal(t, "Are you sure you want to restart all 3 services?\n\nWarning: 1 service(s) have a minimum healthy percent of 0% and may be fully down during the redeploy.",
+		restartPromptText(serviceUI.filteredServices, serviceUI.isFiltered()))
+
+	serviceUI.searchInput.SetText("payments")
+	serviceUI.filterServices("payments")
+	assert.True(t, serviceUI.isFiltered())
+	assert.Len(t, serviceUI.filteredServices, 2)
+	assert.Contains(t, restartPromptText(serviceUI.filteredServices, serviceUI.isFiltered()), "restart 2 filtered services?")
+}
+
+func TestUnfinishedRestartIsNotOverwritten(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := restartprogress.DefaultPath()
+	assert.NoError(t, err)
+	progress, err := restartprogress.Load(path)
+	assert.NoError(t, err)
+	assert.NoError(t, progress.Start([]pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod"}}))
+
+	app := tview.NewApplication()
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE"},
+	}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, services)
+	serviceUI.updateList()
+	serviceUI.setupListInputCapture()
+
+	// R offers to resume or discard the unfinished restart instead
+	serviceUI.list.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'R', tcell.ModNone))
+	button, ok := app.GetFocus().(*tview.Button)
+	assert.True(t, ok)
+	assert.Equal(t, "Resume", button.GetLabel())
+
+	// Starting a restart anyway leaves the pending services alone
+	startRestart(app, context.Background(), &ecs.Client{}, services[1:], serviceUI.layout, serviceUI.retryRestart)
+	reloaded, err := restartprogress.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod/api"}, reloaded.PendingKeys())
+}
+
+func TestRetryRestartIsConfirmed(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := restartprogress.DefaultPath()
+	assert.NoError(t, err)
+	progress, err := restartprogress.Load(path)
+	assert.NoError(t, err)
+
+	app := tview.NewApplication()
+	failed := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/prod", Status: "ACTIVE"}}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, failed)
+
+	// Retrying asks before restarting anything
+	serviceUI.retryRestart(failed, progress)
+	button, ok := app.GetFocus().(*tview.Button)
+	assert.True(t, ok)
+	assert.Equal(t, "Yes", button.GetLabel())
+
+	// In production the retry waits for the typed production confirmation
+	serviceUI.config.ProductionAccounts = []string{"123456789012"}
+	serviceUI.retryRestart(failed, progress)
+	input, ok := app.GetFocus().(*tview.InputField)
+	assert.True(t, ok)
+	assert.Equal(t, "Confirm: ", input.GetLabel())
+}
+
+func TestRestartResumeText(t *testing.T) {
+	startedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)
+	pending := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod"}}
+
+	text := restartResumeText(startedAt, pending, []string{"dev/worker"})
+	assert.Equal(t, "A restart started 2024-05-01 09:30 was interrupted.\n\n1 service(s) were not restarted:\nprod/api\n\n1 pending service(s) are no longer listed and can't be resumed:\ndev/worker", text)
+
+	names := make([]string, 12)
+	for i := range names {
+		names[i] = fmt.Sprintf("svc-%d", i)
+	}
+	assert.True(t, strings.HasSuffix(listedNames(names), "svc-9\n...and 2 more"))
+}
+
+func TestZoneSpreadText(t *testing.T) {
+	spread := []pkg.TaskDetails{
+		{LastStatus: "RUNNING", AvailabilityZone: "eu-west-1b"},
+		{LastStatus: "RUNNING", AvailabilityZone: "eu-west-1a"},
+		{LastStatus: "RUNNING", AvailabilityZone: "eu-west-1b"},
+		{LastStatus: "PENDING", AvailabilityZone: "eu-west-1c"},
+	}
+	text := zoneSpreadText(spread, nil)
+	assert.Contains(t, text, "Tasks by Availability Zone:[-] eu-west-1a: 1, eu-west-1b: 2\n")
+	assert.NotContains(t, text, "All running tasks")
+
+	single := []pkg.TaskDetails{
+		{LastStatus: "RUNNING", AvailabilityZone: "eu-west-1a"},
+		{LastStatus: "RUNNING", AvailabilityZone: "eu-west-1a"},
+	}
+	assert.Contains(t, zoneSpreadText(single, nil), "All running tasks are in eu-west-1a")
+
+	assert.Contains(t, zoneSpreadText(nil, nil), "no running tasks")
+	assert.Contains(t, zoneSpreadText(nil, errors.New("throttled")), "throttled")
+}
+
+func TestProductionGuard(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+
+	proceeded := false
+	serviceUI.guardProduction("restart api", func() { proceeded = true })
+	assert.True(t, proceeded, "outside production actions proceed straight away")
+
+	serviceUI.config.ProductionAccounts = []string{"123456789012"}
+	serviceUI.SetProfile("prod")
+	assert.Equal(t, "account 123456789012", serviceUI.productionLabel())
+	assert.Contains(t, serviceUI.banner.GetText(false), "PRODUCTION — account 123456789012")
+
+	serviceUI.config.ProductionProfiles = []string{"prod"}
+	assert.Equal(t, "account 123456789012, profile prod", serviceUI.productionLabel())
+
+	proceeded = false
+	serviceUI.guardProduction("restart api", func() { proceeded = true })
+	assert.False(t, proceeded, "production actions wait for a typed confirmation")
+}
+
+func TestScaleClusterSkipsDaemons(t *testing.T) {
+	app := tview.NewApplication()
+	layout := tview.NewFlex()
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", SchedulingStrategy: "REPLICA"}
+	agent := pkg.ServiceDetails{ServiceName: "log-agent", Cluster: "prod", SchedulingStrategy: "DAEMON"}
+
+	showScaleClusterPrompt(app, context.Background(), &ecs.Client{}, "prod", []pkg.ServiceDetails{api, agent}, config.Default(), layout)
+	assert.Equal(t, "Scale all 1 services in prod to: ", app.GetFocus().(*tview.InputField).GetLabel())
+	assert.Contains(t, skippedDaemonsText([]pkg.ServiceDetails{agent}), "log-agent")
+	assert.Empty(t, skippedDaemonsText(nil))
+
+	showScaleClusterPrompt(app, context.Background(), &ecs.Client{}, "prod", []pkg.ServiceDetails{agent}, config.Default(), layout)
+	_, isInput := app.GetFocus().(*tview.InputField)
+	assert.False(t, isInput, "a cluster of daemons has nothing to scale")
+}
+
+func TestUnreadableConfigGuardsAsProduction(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "bw-cli"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bw-cli", "config.json"), []byte(`{"productionAccounts": [`), 0o644))
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(tview.NewApplication(), context.Background(), &ecs.Client{}, nil, services)
+	serviceUI.loadConfig()
+	serviceUI.updateProductionBanner()
+
+	assert.Contains(t, serviceUI.header.GetText(false), "error parsing config file")
+	assert.Contains(t, serviceUI.banner.GetText(false), "PRODUCTION — config file unreadable")
+	proceeded := false
+	serviceUI.guardProduction("restart api", func() { proceeded = true })
+	assert.False(t, proceeded, "changes wait for a typed confirmation when production is unknown")
+}
+
+func TestAddPageKeepsSelectionAndPollsNewServices(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"},
+		{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.polledServices = append([]pkg.ServiceDetails(nil), services...)
+	serviceUI.list.SetCurrentItem(1)
+	serviceUI.morePages = true
+	serviceUI.updateHeader()
+	assert.Contains(t, serviceUI.header.GetText(false), "More services load as you scroll")
+
+	serviceUI.searchInput.SetText("er")
+	serviceUI.filterServices("er")
+	serviceUI.morePages = false
+	serviceUI.addPage([]pkg.ServiceDetails{{ServiceName: "scheduler", Cluster: "prod", Status: "ACTIVE"}}, aws.ClusterErrors{"dev": errors.New("access denied")})
+
+	assert.Len(t, serviceUI.currentServices, 3)
+	assert.Len(t, serviceUI.polledServices, 3)
+	assert.Equal(t, "scheduler", serviceUI.polledServices[1].ServiceName)
+	// The search covers the new page too
+	assert.Len(t, serviceUI.filteredServices, 2)
+	selected, _ := serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+	assert.NotContains(t, serviceUI.header.GetText(false), "More services")
+	assert.Contains(t, serviceUI.header.GetText(false), "1 cluster(s) failed to load")
+
+	serviceUI.addPage(nil, aws.ClusterErrors{"staging": errors.New("throttled")})
+	assert.Contains(t, serviceUI.loadError.Error(), "2 cluster(s) failed to load")
+}
+
+func TestToggleStatistic(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"}}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.cwClient = &cloudwatch.Client{}
+	serviceUI.updateList()
+	serviceUI.storeMetrics(services[0], pkg.ServiceMetrics{CPUUtilization: awssdk.Float64(10)}, nil)
+	assert.Contains(t, serviceUI.header.GetText(false), "Utilization: Average")
+
+	// Keep the refetch from starting by marking it as already in flight
+	serviceUI.metricsPending[serviceKey(services[0])] = true
+	serviceUI.toggleStatistic()
+	assert.Equal(t, aws.StatisticMaximum, serviceUI.statistic)
+	assert.Contains(t, serviceUI.header.GetText(false), "Utilization: Maximum")
+	assert.True(t, serviceUI.metrics[serviceKey(services[0])].fetchedAt.IsZero())
+
+	// An average fetched before the toggle is not stored
+	assert.False(t, serviceUI.discardStale(services[0], aws.StatisticMaximum))
+	// Terse enough that the refetch isn't started
+	serviceUI.state.ListVerbosity = verbosityName
+	assert.True(t, serviceUI.discardStale(services[0], aws.StatisticAverage))
+	assert.False(t, serviceUI.metricsPending[serviceKey(services[0])])
+}
+
+func TestForgetDeletedClusters(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/gone", Status: "ACTIVE"},
+		{ServiceName: "api", Cluster: "prod", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.polledServices = append([]pkg.ServiceDetails(nil), services...)
+
+	// The poll already left the deleted cluster's services out
+	serviceUI.refreshServices(services[1:])
+	serviceUI.forgetDeletedClusters([]string{services[0].Cluster})
+
+	assert.Equal(t, services[1:], serviceUI.polledServices)
+	assert.Equal(t, 1, serviceUI.list.GetItemCount())
+	assert.Equal(t, "Cluster gone no longer exists; its services were removed", serviceUI.toast)
+}
+
+func TestApplyClusterRefresh(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 1, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "old", Cluster: "prod", Status: "ACTIVE"},
+		{ServiceName: "web", Cluster: "staging", Status: "ACTIVE"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+	serviceUI.polledServices = append([]pkg.ServiceDetails(nil), services...)
+	serviceUI.refreshingCluster = "prod"
+
+	refreshed := []pkg.ServiceDetails{
+		{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"},
+		{ServiceName: "new", Cluster: "prod", Status: "ACTIVE"},
+	}
+	serviceUI.applyClusterRefresh("prod", refreshed, nil)
+
+	expected := []pkg.ServiceDetails{refreshed[0], refreshed[1], services[2]}
+	assert.Equal(t, expected, serviceUI.currentServices)
+	assert.Equal(t, expected, serviceUI.polledServices)
+	assert.Empty(t, serviceUI.refreshingCluster)
+	assert.Equal(t, "Refreshed 2 services in cluster prod", serviceUI.toast)
+
+	serviceUI.refreshingCluster = "staging"
+	serviceUI.applyClusterRefresh("staging", nil, errors.New("throttled"))
+	assert.Equal(t, expected, serviceUI.currentServices, "a failed refresh keeps the last details")
+	assert.Equal(t, "Error refreshing cluster staging: throttled", serviceUI.toast)
+}
+
+func TestRevisionDiffText(t *testing.T) {
+	from := pkg.TaskDefinitionSummary{
+		CPU:    "256",
+		Memory: "512",
+		Containers: []pkg.ContainerDefinitionSummary{
+			{Name: "api", Image: "repo/api:1", Environment: map[string]string{"PORT": "8080", "DEBUG": "true"}},
+			{Name: "old-sidecar", Image: "repo/sidecar:1"},
+		},
+	}
+	to := pkg.TaskDefinitionSummary{
+		CPU:    "512",
+		Memory: "512",
+		Containers: []pkg.ContainerDefinitionSummary{
+			{Name: "api", Image: "repo/api:2", Environment: map[string]string{"PORT": "8080", "LOG_LEVEL": "info"}},
+			{Name: "agent", Image: "repo/agent:1"},
+		},
+	}
+
+	text := revisionDiffText(from, to)
+	assert.Contains(t, text, "[yellow]Task[-]\n  [red]- cpu: 256[-]\n  [green]+ cpu: 512[-]\n")
+	assert.NotContains(t, text, "memory")
+	assert.Contains(t, text, "[red]- image: repo/api:1[-]\n  [green]+ image: repo/api:2[-]")
+	assert.Contains(t, text, "[red]- env DEBUG: true[-]")
+	assert.Contains(t, text, "[green]+ env LOG_LEVEL: info[-]")
+	assert.NotContains(t, text, "PORT")
+	assert.Contains(t, text, "[yellow]Container agent (added)[-]\n  [green]+ image: repo/agent:1[-]")
+	assert.Contains(t, text, "[yellow]Container old-sidecar (removed)[-]\n  [red]- image: repo/sidecar:1[-]")
+
+	assert.Contains(t, revisionDiffText(from, from), "No differences")
+}
+
+func TestTrackDeploymentsFlagsStuckRollouts(t *testing.T) {
+	app := tview.NewApplication()
+	deploying := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", Deploying: true, DeploymentID: "ecs-svc/1", DeploymentRunningCount: 1}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{deploying})
+	start := time.Now()
+
+	serviceUI.trackDeployments([]pkg.ServiceDetails{deploying}, start.Add(StuckAfter-time.Second))
+	assert.False(t, serviceUI.isStuck(deploying))
+
+	// Progress restarts the clock
+	progressed := deploying
+	progressed.DeploymentRunningCount = 2
+	serviceUI.trackDeployments([]pkg.ServiceDetails{progressed}, start.Add(StuckAfter))
+	assert.False(t, serviceUI.isStuck(progressed))
+
+	serviceUI.trackDeployments([]pkg.ServiceDetails{progressed}, start.Add(2*StuckAfter))
+	assert.True(t, serviceUI.isStuck(progressed))
+	assert.Contains(t, serviceUI.toast, "Deployment stuck for api")
+	assert.Contains(t, serviceUI.serviceColumnsText(progressed), "Stuck")
+
+	// A finished rollout is forgotten
+	done := progressed
+	done.Deploying = false
+	serviceUI.trackDeployments([]pkg.ServiceDetails{done}, start.Add(3*StuckAfter))
+	assert.False(t, serviceUI.isStuck(done))
+	assert.Empty(t, serviceUI.deployments)
+}
+
+func TestServiceAge(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "created just now", serviceAge(now.Add(-30*time.Second), now))
+	assert.Equal(t, "created 1 hour ago", serviceAge(now.Add(-90*time.Minute), now))
+	assert.Equal(t, "created 3 months ago", serviceAge(now.AddDate(0, -3, -1), now))
+	assert.Equal(t, "created 2 years ago", serviceAge(now.AddDate(-2, 0, -1), now))
+
+	createdAt := now.AddDate(0, -3, 0)
+	assert.Contains(t, serviceDetailsText(pkg.ServiceDetails{CreatedAt: &createdAt}, nil), "Created:[-] ")
+	assert.NotContains(t, serviceDetailsText(pkg.ServiceDetails{}, nil), "Created:")
+}
+
+func TestFargateCostEstimates(t *testing.T) {
+	rate := config.FargateRate{VCPUHour: 0.04, GBHour: 0.004}
+	reservation := pkg.TaskReservation{CPU: 512, MemoryMiB: 1024}
+	assert.InDelta(t, 0.072, hourlyCost(reservation, 3, rate), 1e-9)
+
+	fargate := pkg.ServiceDetails{ServiceName: "api", Cluster: "arn:aws:ecs:eu-west-1:123456789012:cluster/prod", TaskDefinition: "api:1", LaunchType: "FARGATE", RunningCount: 3, Status: "ACTIVE"}
+	ec2 := pkg.ServiceDetails{ServiceName: "batch", Cluster: fargate.Cluster, TaskDefinition: "batch:1", RunningCount: 5, Status: "ACTIVE"}
+	assert.Contains(t, costText(fargate, reservation, rate, true), "~$0.07/h for 3 running tasks")
+	assert.Contains(t, costText(fargate, reservation, rate, false), `no Fargate rates for region "eu-west-1"`)
+	assert.Empty(t, costText(ec2, reservation, rate, true))
+
+	// Services on a Fargate capacity provider strategy are priced too
+	spot := pkg.ServiceDetails{ServiceName: "jobs", Cluster: fargate.Cluster, TaskDefinition: "api:1", CapacityProviders: []string{"FARGATE_SPOT"}, RunningCount: 1, Status: "ACTIVE"}
+	assert.Contains(t, costText(spot, reservation, rate, true), "~$0.02/h for 1 running tasks")
+
+	unknown := fargate
+	unknown.ServiceName, unknown.TaskDefinition = "worker", "worker:1"
+	serviceUI := NewServiceUI(tview.NewApplication(), context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{fargate, ec2, unknown, spot})
+	serviceUI.config.FargateRates = map[string]config.FargateRate{"eu-west-1": rate}
+	serviceUI.reservations["api:1"] = reservation
+
+	total, priced, unpriced := serviceUI.fleetCost(serviceUI.currentServices)
+	assert.InDelta(t, 0.096, total, 1e-9)
+	assert.Equal(t, 2, priced)
+	assert.Equal(t, 1, unpriced)
+	serviceUI.updateHeader()
+	assert.Equal(t, "Fargate: ~$0.10/h (~$70/month) [gray](1 not priced)[-]", serviceUI.costFooter.GetText(false))
+	assert.NotContains(t, serviceUI.header.GetText(false), "Fargate")
+
+	serviceUI.reservations = map[string]pkg.TaskReservation{}
+	serviceUI.updateHeader()
+	assert.Empty(t, serviceUI.costFooter.GetText(false), "the footer collapses when nothing is priced")
+}
+
+func TestContainerInstancesText(t *testing.T) {
+	tasks := []pkg.TaskDetails{
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/abc", LastStatus: "RUNNING", ContainerInstanceArn: "ci/1"},
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/def", LastStatus: "RUNNING", ContainerInstanceArn: "ci/2"},
+		{TaskArn: "arn:aws:ecs:eu-west-1:123456789012:task/prod/old", LastStatus: "STOPPED", ContainerInstanceArn: "ci/1"},
+	}
+	instances := map[string]pkg.ContainerInstance{
+		"ci/1": {EC2InstanceID: "i-0abc", AvailabilityZone: "eu-west-1a", Status: "ACTIVE", AgentConnected: true, RunningTasksCount: 4, RegisteredCPU: 2048, RemainingCPU: 100, RegisteredMemoryMiB: 4096, RemainingMemoryMiB: 2048},
+		"ci/2": {EC2InstanceID: "i-0def", Status: "DRAINING", RunningTasksCount: 1},
+	}
+
+	text := containerInstancesText(tasks, instances, nil)
+	assert.Contains(t, text, "abc → i-0abc (eu-west-1a, ACTIVE): [red]100 of 2048[-] CPU units and 2048 of 4096 MiB free, 4 tasks")
+	assert.Contains(t, text, "def → i-0def (unknown zone, [red]DRAINING[-], [red]agent disconnected[-])")
+	assert.NotContains(t, text, "old")
+
+	assert.Contains(t, containerInstancesText(nil, nil, nil), "no running tasks on container instances")
+	assert.Contains(t, containerInstancesText(nil, nil, errors.New("access denied")), "access denied")
+}
+
+// tasksECSClient serves one running task on a container instance and one
+// stopped task, counting the calls listing running tasks
+type tasksECSClient struct {
+	aws.ECSClientAPI
+	runningLists int
+}
+
+func (c *tasksECSClient) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	if params.DesiredStatus == ecstypes.DesiredStatusStopped {
+		return &ecs.ListTasksOutput{TaskArns: []string{"task/prod/old"}}, nil
+	}
+	c.runningLists++
+	return &ecs.ListTasksOutput{TaskArns: []string{"task/prod/abc"}}, nil
+}
+
+func (c *tasksECSClient) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	var tasks []ecstypes.Task
+	for _, arn := range params.Tasks {
+		task := ecstypes.Task{TaskArn: awssdk.String(arn), LastStatus: awssdk.String("RUNNING"), ContainerInstanceArn: awssdk.String("ci/1")}
+		if arn == "task/prod/old" {
+			task.LastStatus = awssdk.String("STOPPED")
+			task.StoppedReason = awssdk.String("Essential container in task exited")
+		}
+		tasks = append(tasks, task)
+	}
+	return &ecs.DescribeTasksOutput{Tasks: tasks}, nil
+}
+
+func (c *tasksECSClient) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	return &ecs.DescribeContainerInstancesOutput{ContainerInstances: []ecstypes.ContainerInstance{
+		{ContainerInstanceArn: awssdk.String("ci/1"), Ec2InstanceId: awssdk.String("i-0abc"), Status: awssdk.String("ACTIVE"), AgentConnected: true},
+	}}, nil
+}
+
+func TestTasksViewListsContainerInstances(t *testing.T) {
+	app := tview.NewApplication()
+	client := &tasksECSClient{}
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", LaunchType: "EC2", Status: "ACTIVE"}
+	layout := tview.NewFlex()
+
+	showServiceTasks(app, context.Background(), client, service, layout)
+	text := app.GetFocus().(*tview.TextView).GetText(true)
+	assert.Contains(t, text, "abc → i-0abc")
+	assert.Contains(t, text, "Essential container in task exited")
+	assert.Equal(t, 1, client.runningLists)
+
+	// Fargate tasks don't run on container instances
+	service.LaunchType = "FARGATE"
+	showServiceTasks(app, context.Background(), client, service, layout)
+	text = app.GetFocus().(*tview.TextView).GetText(true)
+	assert.NotContains(t, text, "Container Instances")
+	assert.Contains(t, text, "Essential container in task exited")
+	assert.Equal(t, 1, client.runningLists)
+
+	// The service detail view no longer lists them
+	serviceUI := NewServiceUI(app, context.Background(), client, nil, []pkg.ServiceDetails{service})
+	service.LaunchType = "EC2"
+	for _, section := range serviceUI.detailSections(service) {
+		assert.NotEqual(t, "Container Instances", section.title)
+	}
+}
+
+func TestFollowStatusText(t *testing.T) {
+	assert.True(t, isSettled("Stable"))
+	assert.True(t, isSettled("Deployment Failed"))
+	assert.False(t, isSettled("Deploying (1/3)"))
+	assert.False(t, isSettled(""))
+
+	loading := followStatusText(followUpdate{service: pkg.ServiceDetails{RunningCount: 2, DesiredCount: 3}}, 0)
+	assert.Contains(t, loading, "Status:[-] loading...")
+	assert.Contains(t, loading, "Running / Desired:[-] 2 / 3")
+	assert.Contains(t, loading, "Rollout State:[-] n/a")
+
+	deploying := followStatusText(followUpdate{
+		status: "Deploying (1/3)",
+		service: pkg.ServiceDetails{RunningCount: 3, DesiredCount: 3, Deployments: []pkg.Deployment{
+			{Status: "PRIMARY", RolloutState: "IN_PROGRESS"},
+			{Status: "ACTIVE", RolloutState: "COMPLETED"},
+		}},
+	}, 42*time.Second)
+	assert.Contains(t, deploying, "Status:[-] [blue]Deploying (1/3)[-]")
+	assert.Contains(t, deploying, "Rollout State:[-] IN_PROGRESS")
+	assert.Contains(t, deploying, "Following For:[-] 42s")
+
+	failed := followStatusText(followUpdate{err: errors.New("throttled")}, time.Second)
+	assert.Contains(t, failed, "[red]throttled[-] (retrying)")
+}
+
+func TestServiceNamesWithColorTags(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	mockClient := &ecs.Client{}
+	services := []pkg.ServiceDetails{
+		{ServiceName: "legacy[red]api[-]", Cluster: "prod", RunningCount: 1, DesiredCount: 1, Status: "ACTIVE[x]"},
+	}
+
+	serviceUI := NewServiceUI(app, ctx, mockClient, nil, services)
+	serviceUI.updateList()
+
+	screen := tcell.NewSimulationScreen("")
+	assert.NoError(t, screen.Init())
+	screen.SetSize(100, 3)
+	serviceUI.list.SetRect(0, 0, 100, 3)
+	serviceUI.list.Draw(screen)
+	screen.Show()
+
+	cells, width, _ := screen.GetContents()
+	var row strings.Builder
+	for _, cell := range cells[:width] {
+		row.WriteString(string(cell.Runes))
+	}
+	assert.Contains(t, row.String(), "legacy[red]api[-] (Running: 1, Desired: 1) - Status: ACTIVE[x]")
+}
+
+func TestFlappingServices(t *testing.T) {
+	samples := func(running ...int64) []countSample {
+		values := make([]countSample, len(running))
+		for i, count := range running {
+			values[i] = countSample{running: count, desired: 3}
+		}
+		return values
+	}
+	assert.Equal(t, 0, runningReversals(samples(1, 2, 3, 3)))
+	assert.Equal(t, 3, runningReversals(samples(3, 2, 3, 3, 2, 3)))
+	// Scaling down and back up isn't flapping
+	assert.Equal(t, 0, runningReversals([]countSample{{running: 3, desired: 3}, {running: 2, desired: 2}, {running: 3, desired: 3}, {running: 2, desired: 2}}))
+
+	app := tview.NewApplication()
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", RunningCount: 3, DesiredCount: 3, Status: "ACTIVE"}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{service})
+	for _, running := range []int64{2, 3, 2, 3} {
+		crashed := service
+		crashed.RunningCount = running
+		serviceUI.recordHistory([]pkg.ServiceDetails{crashed})
+	}
+	assert.True(t, serviceUI.isFlapping(service))
+	assert.Contains(t, serviceUI.serviceItemText(service), "[red]Flapping[-]")
+	assert.Contains(t, serviceDetailsText(service, serviceUI.serviceHistory(service)), "changed direction 3 times over the last 5 polls")
+
+	defaultWindow := FlapWindow
+	FlapWindow = 3
+	t.Cleanup(func() { FlapWindow = defaultWindow })
+	assert.False(t, serviceUI.isFlapping(service), "only the last 3 polls count")
+}
+
+func TestRollingDeployIsNotFlapping(t *testing.T) {
+	app := tview.NewApplication()
+	service := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", RunningCount: 2, DesiredCount: 2, Status: "ACTIVE"}
+	serviceUI := NewServiceUI(app, context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{service})
+	// Two rolling deployments, each starting new tasks before draining old ones
+	for _, running := range []int64{2, 3, 4, 3, 2, 3, 4, 3, 2} {
+		deploying := service
+		deploying.RunningCount = running
+		deploying.Deploying = running != 2
+		serviceUI.recordHistory([]pkg.ServiceDetails{deploying})
+	}
+	serviceUI.recordHistory([]pkg.ServiceDetails{service})
+	assert.False(t, serviceUI.isFlapping(service))
+	assert.NotContains(t, serviceUI.serviceItemText(service), "Flapping")
+}
+
+func TestStaleServices(t *testing.T) {
+	now := time.Now()
+	old, recent := now.Add(-120*24*time.Hour), now.Add(-10*24*time.Hour)
+	stale := pkg.ServiceDetails{ServiceName: "legacy", Cluster: "prod", Status: "ACTIVE", LastDeployedAt: &old}
+	fresh := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", LastDeployedAt: &recent}
+
+	assert.True(t, isStale(stale, now))
+	assert.False(t, isStale(fresh, now))
+	assert.False(t, isStale(pkg.ServiceDetails{}, now), "services without a known deployment aren't stale")
+	assert.Contains(t, lastDeployedText(old, now), "(120 days ago) [yellow]stale: not deployed in over 90 days[-]")
+	assert.NotContains(t, lastDeployedText(recent, now), "stale")
+
+	match, err := queryMatcher("is:stale leg")
+	assert.NoError(t, err)
+	assert.True(t, match(stale))
+	assert.False(t, match(fresh))
+
+	serviceUI := NewServiceUI(tview.NewApplication(), context.Background(), &ecs.Client{}, nil, []pkg.ServiceDetails{stale, fresh})
+	assert.Contains(t, serviceUI.serviceItemText(stale), "⌛ Stale")
+	assert.NotContains(t, serviceUI.serviceItemText(fresh), "Stale")
+
+	defaultDays := StaleDays
+	StaleDays = 0
+	t.Cleanup(func() { StaleDays = defaultDays })
+	assert.False(t, isStale(stale, now))
+}
+
+func TestHeatmapCells(t *testing.T) {
+	cpu, memory := 30.0, 80.0
+	metrics := pkg.ServiceMetrics{CPUUtilization: &cpu, MemoryUtilization: &memory}
+	assert.Equal(t, &memory, heatmapValue(metrics, heatmapPeak))
+	assert.Equal(t, &cpu, heatmapValue(metrics, heatmapCPU))
+	assert.Equal(t, &cpu, heatmapValue(pkg.ServiceMetrics{CPUUtilization: &cpu}, heatmapPeak))
+	assert.Nil(t, heatmapValue(pkg.ServiceMetrics{}, heatmapPeak))
+
+	zero, half, over := 0.0, 50.0, 140.0
+	assert.Equal(t, tcell.ColorGray, utilizationColor(nil))
+	assert.Equal(t, tcell.NewRGBColor(0, 200, 0), utilizationColor(&zero))
+	assert.Equal(t, tcell.NewRGBColor(255, 200, 0), utilizationColor(&half))
+	assert.Equal(t, tcell.NewRGBColor(255, 0, 0), utilizationColor(&over))
+
+	assert.Equal(t, "api", abbreviate("api", 10))
+	assert.Equal(t, "payments-…", abbreviate("payments-service", 10))
+}
+
+func TestListHeldWhileModalOpen(t *testing.T) {
+	app := tview.NewApplication()
+	ctx := context.Background()
+	api := pkg.ServiceDetails{ServiceName: "api", Cluster: "prod", Status: "ACTIVE", RunningCount: 2, DesiredCount: 2}
+	worker := pkg.ServiceDetails{ServiceName: "worker", Cluster: "prod", Status: "ACTIVE", RunningCount: 1, DesiredCount: 1}
+
+	serviceUI := NewServiceUI(app, ctx, &ecs.Client{}, nil, []pkg.ServiceDetails{api, worker})
+	serviceUI.updateList()
+	serviceUI.setupModalTracking()
+	var hooked []pkg.ServiceDetails
+	serviceUI.OnRefresh(func(services []pkg.ServiceDetails) { hooked = services })
+	app.SetRoot(serviceUI.layout, true).SetFocus(serviceUI.list)
+	serviceUI.list.SetCurrentItem(1)
+
+	// A confirmation covers the list
+	showMessage(app, "Confirm?", serviceUI.layout)
+	assert.True(t, serviceUI.modalOpen)
+
+	scaled := api
+	scaled.DesiredCount = 5
+	aardvark := pkg.ServiceDetails{ServiceName: "aardvark", Cluster: "prod", Status: "ACTIVE"}
+	updated := []pkg.ServiceDetails{aardvark, scaled, worker}
+	serviceUI.refreshServices(updated)
+
+	// The services, history and hooks are current, but the list doesn't move
+	assert.Equal(t, updated, serviceUI.currentServices)
+	assert.Equal(t, updated, hooked)
+	assert.Len(t, serviceUI.history, 3)
+	assert.Equal(t, 2, serviceUI.list.GetItemCount())
+	selected, _ := serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+
+	// Neither does a page of services loaded meanwhile
+	serviceUI.addPage([]pkg.ServiceDetails{{ServiceName: "zebra", Cluster: "prod", Status: "ACTIVE"}}, nil)
+	assert.Len(t, serviceUI.currentServices, 4)
+	assert.Equal(t, 2, serviceUI.list.GetItemCount())
+
+	// Closing it re-lists right away, keeping the selection
+	app.SetRoot(serviceUI.layout, true)
+	assert.False(t, serviceUI.modalOpen)
+	assert.Equal(t, 4, serviceUI.list.GetItemCount())
+	selected, _ = serviceUI.selectedService()
+	assert.Equal(t, "worker", selected.ServiceName)
+	item, _ := serviceUI.list.GetItemText(1)
+	assert.Contains(t, item, "Desired: 5")
+}
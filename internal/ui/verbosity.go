@@ -0,0 +1,48 @@
+package ui
+
+// List Verbosity
+// --------------
+//
+// The service list can be cut down from every column to just service names.
+// Each level adds to the one before it, and the chosen level is stored in the
+// state file.
+
+const (
+	verbosityName    = "name"
+	verbosityCounts  = "counts"
+	verbosityStatus  = "status"
+	verbosityMetrics = "metrics"
+)
+
+// verbosityLevels lists the levels in the order the 'v' key cycles through
+var verbosityLevels = []string{verbosityName, verbosityCounts, verbosityStatus, verbosityMetrics}
+
+// verbosityRank returns the position of a level in verbosityLevels, treating
+// unknown levels as the most verbose
+func verbosityRank(level string) int {
+	for i, l := range verbosityLevels {
+		if l == level {
+			return i
+		}
+	}
+	return len(verbosityLevels) - 1
+}
+
+func (s *ServiceUI) verbosityRank() int {
+	return verbosityRank(s.state.ListVerbosity)
+}
+
+// cycleVerbosity switches the list to the next level, wrapping around from
+// the most verbose to names only, and keeps the current selection
+func (s *ServiceUI) cycleVerbosity() {
+	next := verbosityLevels[(s.verbosityRank()+1)%len(verbosityLevels)]
+	s.state.ListVerbosity = next
+	s.saveState()
+
+	current := s.list.GetCurrentItem()
+	s.updateList()
+	if current < s.list.GetItemCount() {
+		s.list.SetCurrentItem(current)
+	}
+	s.loadVisibleMetrics()
+}
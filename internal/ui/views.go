@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/state"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Saved Views
+// -----------
+//
+// A view captures the search query, cluster group, cluster scope, pins and
+// list verbosity under a name, so switching between contexts such as an
+// incident or a team's services is a single selection. The cluster scope is
+// the clusters chosen in the startup picker, so a view's filter keeps
+// applying to the same clusters when more are loaded later. Views are stored
+// in the state file.
+
+// currentView returns the list settings currently in effect
+func (s *ServiceUI) currentView() state.View {
+	return state.View{
+		Query:          s.searchInput.GetText(),
+		GroupFilter:    s.groupFilter,
+		PinnedServices: append([]string(nil), s.state.PinnedServices...),
+		ListVerbosity:  s.state.ListVerbosity,
+		ClusterFilter:  s.clusterFilter,
+		Clusters:       append([]string(nil), s.viewClusters()...),
+	}
+}
+
+// viewClusters returns the clusters a view saved now would cover: the scope
+// of the applied view, or else the clusters chosen in the startup picker
+func (s *ServiceUI) viewClusters() []string {
+	if len(s.clusterScope) > 0 {
+		return s.clusterScope
+	}
+	return s.pickedClusters
+}
+
+// inClusterScope reports whether service belongs to a cluster the applied view
+// covers
+func (s *ServiceUI) inClusterScope(service pkg.ServiceDetails) bool {
+	if len(s.clusterScope) == 0 {
+		return true
+	}
+	for _, cluster := range s.clusterScope {
+		if service.Cluster == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// missingClusters returns the clusters of a scope that aren't loaded
+func (s *ServiceUI) missingClusters(clusters []string) []string {
+	if len(clusters) == 0 || s.pickedClusters == nil {
+		return nil
+	}
+	loaded := make(map[string]bool, len(s.pickedClusters))
+	for _, cluster := range s.pickedClusters {
+		loaded[cluster] = true
+	}
+	var missing []string
+	for _, cluster := range clusters {
+		if !loaded[cluster] {
+			missing = append(missing, aws.ClusterName(cluster))
+		}
+	}
+	return missing
+}
+
+// saveView stores the current settings under name, replacing any view with
+// the same name
+func (s *ServiceUI) saveView(name string) {
+	if s.state.Views == nil {
+		s.state.Views = make(map[string]state.View)
+	}
+	s.state.Views[name] = s.currentView()
+	s.saveState()
+}
+
+// deleteView forgets a saved view
+func (s *ServiceUI) deleteView(name string) {
+	delete(s.state.Views, name)
+	s.saveState()
+}
+
+// applyView switches the list to a view's settings and persists them as the
+// current ones. A view covering clusters that weren't chosen in the startup
+// picker has them preselected there on the next launch.
+func (s *ServiceUI) applyView(view state.View) {
+	s.groupFilter = view.GroupFilter
+	s.clusterFilter = view.ClusterFilter
+	s.clusterScope = append([]string(nil), view.Clusters...)
+	s.state.GroupFilter = view.GroupFilter
+	s.state.PinnedServices = append([]string(nil), view.PinnedServices...)
+	s.state.ListVerbosity = view.ListVerbosity
+	if len(view.Clusters) > 0 {
+		s.state.SelectedClusters = append([]string(nil), view.Clusters...)
+	}
+	s.saveState()
+
+	s.searchInput.SetText(view.Query)
+	s.filterServices(view.Query)
+	s.loadVisibleMetrics()
+	if missing := s.missingClusters(view.Clusters); len(missing) > 0 {
+		s.showToast("Not loaded, restart to include: " + strings.Join(missing, ", "))
+	}
+}
+
+// viewNames returns the names of the saved views, sorted
+func (s *ServiceUI) viewNames() []string {
+	names := make([]string, 0, len(s.state.Views))
+	for name := range s.state.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// showViews lists the saved views to switch to, with an entry to save the
+// current settings as a new one. x deletes the highlighted view.
+func (s *ServiceUI) showViews() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(" Views (Enter: switch, x: delete) ")
+
+	closeSelection := func() {
+		s.app.SetRoot(s.layout, true)
+		s.app.SetFocus(s.list)
+	}
+
+	list.AddItem("Save current view...", "", 0, s.showSaveViewPrompt)
+	names := s.viewNames()
+	for _, name := range names {
+		name := name // Capture the current name in the loop
+		list.AddItem(name, "", 0, func() {
+			s.applyView(s.state.Views[name])
+			closeSelection()
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := list.GetCurrentItem()
+		if event.Key() == tcell.KeyRune && event.Rune() == 'x' && index > 0 {
+			s.deleteView(names[index-1])
+			s.showViews()
+			return nil
+		}
+		return event
+	})
+	list.SetDoneFunc(closeSelection)
+
+	s.app.SetRoot(list, true)
+}
+
+// showSaveViewPrompt asks for a name and saves the current settings under it
+func (s *ServiceUI) showSaveViewPrompt() {
+	inputField := tview.NewInputField().
+		SetLabel("Save view as: ").
+		SetFieldWidth(30)
+
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		name := strings.TrimSpace(inputField.GetText())
+		if key == tcell.KeyEnter && name != "" {
+			s.saveView(name)
+			s.app.SetRoot(s.layout, true)
+			s.app.SetFocus(s.list)
+			s.showToast("Saved view " + name)
+			return
+		}
+		s.showViews()
+	})
+
+	s.app.SetRoot(inputField, true)
+}
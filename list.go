@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listStatus string
+	listFailIf string
+)
+
+// Values accepted by list --fail-if
+const (
+	failIfIn    = "in"
+	failIfNotIn = "not-in"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List ECS services and their deployment status",
+	Long: `List prints every service with its deployment status. With --status, only
+services in that state (deploying, stable, or failed) are printed.
+
+With --fail-if, list exits non-zero when any service is "in" the requested
+state, or when any service is "not-in" it. For example, a CI job can run
+"bw-cli list --status stable --fail-if not-in" until every service is stable.
+It also exits non-zero when any cluster or service couldn't be checked.
+Services deployed by CodeDeploy or an external controller are stable once
+their primary task set is the only one and has reached a steady state.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch listStatus {
+		case "", aws.DeploymentStateDeploying, aws.DeploymentStateStable, aws.DeploymentStateFailed:
+		default:
+			return fmt.Errorf("unknown status %q: must be deploying, stable, or failed", listStatus)
+		}
+		switch listFailIf {
+		case "":
+		case failIfIn, failIfNotIn:
+			if listStatus == "" {
+				return errors.New("--fail-if requires --status")
+			}
+		default:
+			return fmt.Errorf("unknown --fail-if value %q: must be in or not-in", listFailIf)
+		}
+		return runList()
+	},
+}
+
+func init() {
+	listCmd.Flags().StringVar(&listStatus, "status", "", "Only list services in this state: deploying, stable, or failed")
+	listCmd.Flags().StringVar(&listFailIf, "fail-if", "", "Exit non-zero if any service is in (\"in\") or not in (\"not-in\") the --status state")
+	rootCmd.AddCommand(listCmd)
+}
+
+// listStatusConcurrency bounds the number of services whose deployment status
+// is fetched at the same time.
+const listStatusConcurrency = 10
+
+func runList() error {
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Clusters that fail to load are reported and the rest still listed
+	services, err := getAllServiceDetails(ctx, clients)
+	var clusterErrs aws.ClusterErrors
+	if errors.As(err, &clusterErrs) {
+		logClusterErrors(clusterErrs)
+	} else if err != nil {
+		return fmt.Errorf("error fetching services: %v", err)
+	}
+	aws.SortServices(services)
+
+	results := aws.GetDeploymentStatuses(ctx, clients.ecs, services, listStatusConcurrency)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER\tSERVICE\tSTATUS")
+	matched, unmatched, failed := 0, 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			logEvent(slog.LevelWarn, fmt.Sprintf("Warning: error fetching deployment status of %s: %v", result.Service.ServiceName, result.Err), "failed to fetch deployment status",
+				"cluster", aws.ClusterName(result.Service.Cluster), "service", result.Service.ServiceName, "error", result.Err.Error())
+			failed++
+			continue
+		}
+		if listStatus != "" && aws.DeploymentState(result.Status) != listStatus {
+			unmatched++
+			continue
+		}
+		matched++
+		fmt.Fprintf(w, "%s\t%s\t%s\n", aws.ClusterName(result.Service.Cluster), result.Service.ServiceName, result.Status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// A gate can't pass on services it couldn't check
+	switch {
+	case listFailIf != "" && len(clusterErrs) > 0:
+		return fmt.Errorf("%d cluster(s) failed to load", len(clusterErrs))
+	case listFailIf != "" && failed > 0:
+		return fmt.Errorf("could not fetch the deployment status of %d service(s)", failed)
+	case listFailIf == failIfIn && matched > 0:
+		return fmt.Errorf("%d service(s) are %s", matched, listStatus)
+	case listFailIf == failIfNotIn && unmatched > 0:
+		return fmt.Errorf("%d service(s) are not %s", unmatched, listStatus)
+	}
+	return nil
+}
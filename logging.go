@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Values accepted by --log-format
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+var logFormat string
+
+// quiet drops progress lines of non-interactive commands, leaving only
+// warnings, errors and the exit code
+var quiet bool
+
+// Where logEvent writes; replaced in tests
+var (
+	logStdout io.Writer = os.Stdout
+	logStderr io.Writer = os.Stderr
+)
+
+// jsonLogger writes progress and result lines as JSON objects when
+// --log-format is json, and is nil otherwise
+var jsonLogger *slog.Logger
+
+// setupLogging validates --log-format and creates the JSON logger if needed
+func setupLogging() error {
+	switch logFormat {
+	case logFormatText:
+		jsonLogger = nil
+	case logFormatJSON:
+		jsonLogger = newJSONLogger(logStdout)
+	default:
+		return fmt.Errorf("unknown --log-format %q: must be text or json", logFormat)
+	}
+	return nil
+}
+
+// newJSONLogger logs one object per line with timestamp, level and message
+// keys, plus any service and cluster attributes
+func newJSONLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return attr
+			}
+			switch attr.Key {
+			case slog.TimeKey:
+				attr.Key = "timestamp"
+			case slog.MessageKey:
+				attr.Key = "message"
+			}
+			return attr
+		},
+	}))
+}
+
+// logEvent reports progress or a result of a non-interactive command. text is
+// printed as is in text mode; in JSON mode message and attrs are logged
+// instead. Warnings and errors go to stderr in text mode. With --quiet only
+// warnings and errors are reported.
+func logEvent(level slog.Level, text, message string, attrs ...any) {
+	if quiet && level < slog.LevelWarn {
+		return
+	}
+	if jsonLogger != nil {
+		jsonLogger.Log(context.Background(), level, message, attrs...)
+		return
+	}
+	if level >= slog.LevelWarn {
+		fmt.Fprintln(logStderr, text)
+		return
+	}
+	fmt.Fprintln(logStdout, text)
+}
+
+// requireYesWhenQuiet refuses to prompt for confirmation when --quiet has
+// hidden the list of changes being confirmed
+func requireYesWhenQuiet(yes bool) error {
+	if quiet && !yes {
+		return errors.New("--quiet hides the changes to confirm: pass --yes as well")
+	}
+	return nil
+}
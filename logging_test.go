@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogEvent(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		quiet      bool
+		level      slog.Level
+		wantStdout string
+		wantStderr string
+		wantJSON   map[string]any
+	}{
+		{name: "text info", format: logFormatText, level: slog.LevelInfo, wantStdout: "Scaled api\n"},
+		{name: "text warning", format: logFormatText, level: slog.LevelWarn, wantStderr: "Scaled api\n"},
+		{name: "quiet info", format: logFormatText, quiet: true, level: slog.LevelInfo},
+		{name: "quiet warning", format: logFormatText, quiet: true, level: slog.LevelWarn, wantStderr: "Scaled api\n"},
+		{name: "json info", format: logFormatJSON, level: slog.LevelInfo,
+			wantJSON: map[string]any{"level": "INFO", "message": "service scaled", "service": "api"}},
+		{name: "json warning", format: logFormatJSON, level: slog.LevelWarn,
+			wantJSON: map[string]any{"level": "WARN", "message": "service scaled", "service": "api"}},
+		{name: "json quiet info", format: logFormatJSON, quiet: true, level: slog.LevelInfo},
+		{name: "json quiet error", format: logFormatJSON, quiet: true, level: slog.LevelError,
+			wantJSON: map[string]any{"level": "ERROR", "message": "service scaled", "service": "api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			savedStdout, savedStderr := logStdout, logStderr
+			logStdout, logStderr = &stdout, &stderr
+			logFormat, quiet = tt.format, tt.quiet
+			t.Cleanup(func() {
+				logStdout, logStderr = savedStdout, savedStderr
+				logFormat, quiet, jsonLogger = "", false, nil
+			})
+			assert.NoError(t, setupLogging())
+
+			logEvent(tt.level, "Scaled api", "service scaled", "service", "api")
+
+			assert.Equal(t, tt.wantStderr, stderr.String())
+			if tt.wantJSON == nil {
+				assert.Equal(t, tt.wantStdout, stdout.String())
+				return
+			}
+			var logged map[string]any
+			assert.NoError(t, json.Unmarshal(stdout.Bytes(), &logged))
+			assert.Contains(t, logged, "timestamp")
+			assert.NotContains(t, logged, "time")
+			assert.NotContains(t, logged, "msg")
+			delete(logged, "timestamp")
+			assert.Equal(t, tt.wantJSON, logged)
+		})
+	}
+}
+
+func TestSetupLoggingRejectsUnknownFormat(t *testing.T) {
+	logFormat = "yaml"
+	t.Cleanup(func() { logFormat = "" })
+	assert.EqualError(t, setupLogging(), `unknown --log-format "yaml": must be text or json`)
+}
@@ -1,28 +1,66 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/clusterfilter"
+	appconfig "github.com/alexalbu001/bw-cli/internal/config"
+	"github.com/alexalbu001/bw-cli/internal/exporter"
 	"github.com/alexalbu001/bw-cli/internal/ui"
+	"github.com/alexalbu001/bw-cli/pkg"
 
 	"context"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/rivo/tview"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
-	version string
+	version                string
+	metricsPort            int
+	metricsAddress         string
+	clusterPickerThreshold int
+	endpointURL            string
+	caBundle               string
+	profileName            string
+	startupTimeout         = 30 * time.Second
+	idleTimeout            time.Duration
+	includeClusters        []string
+	excludeClusters        []string
+	clusterFilter          *clusterfilter.Filter
+	showAPIStats           bool
+	once                   bool
+	pageSize               int
+	// apiStats counts ECS and CloudWatch calls when --api-stats is set
+	apiStats *aws.APIStats
 )
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		// Errors are reported even with --quiet, which only drops progress
+		if jsonLogger != nil {
+			jsonLogger.Error(err.Error())
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
 		os.Exit(1)
 	}
 }
@@ -33,6 +71,83 @@ var rootCmd = &cobra.Command{
 	Long: `bw-cli is a command-line tool that provides an interactive terminal UI 
 for managing and monitoring AWS ECS services. It allows users to view service 
 details, update desired counts, and perform other ECS-related operations.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if endpointURL != "" {
+			if u, err := url.Parse(endpointURL); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("invalid --endpoint-url %q: expected e.g. http://localhost:4566", endpointURL)
+			}
+		}
+		if aws.MetricsWindow <= 0 {
+			return errors.New("--metrics-window must be positive")
+		}
+		if err := aws.ValidateMetricsSource(aws.MetricsSource); err != nil {
+			return err
+		}
+		// An unreadable config is reported by the UI and by confirmations,
+		// which then treat every session as production
+		if cfg, err := appconfig.LoadDefault(); err == nil {
+			if cfg.MetricsNamespace != "" && !cmd.Flags().Changed("metrics-namespace") {
+				aws.MetricsNamespace = cfg.MetricsNamespace
+			}
+			aws.SetNoMetricsClusters(cfg.NoMetricsClusters)
+		}
+		if aws.MetricsNamespace == "" {
+			return errors.New("--metrics-namespace must not be empty")
+		}
+		if aws.MetricsPeriod != 0 {
+			if err := aws.ValidateMetricsPeriod(aws.MetricsPeriod); err != nil {
+				return err
+			}
+		}
+		if ui.PollInterval <= 0 {
+			return errors.New("--poll-interval must be positive")
+		}
+		if ui.MetricsInterval <= 0 {
+			return errors.New("--metrics-interval must be positive")
+		}
+		if startupTimeout < 0 {
+			return errors.New("--startup-timeout must not be negative")
+		}
+		if ui.StuckAfter < 0 {
+			return errors.New("--stuck-after must not be negative")
+		}
+		if ui.FlapWindow < 2 || ui.FlapWindow > ui.MaxFlapWindow {
+			return fmt.Errorf("--flap-window must be between 2 and %d polls", ui.MaxFlapWindow)
+		}
+		if ui.FlapChanges < 0 {
+			return errors.New("--flap-changes must not be negative")
+		}
+		if ui.StaleDays < 0 {
+			return errors.New("--stale-days must not be negative")
+		}
+		if pageSize < 0 || pageSize > aws.MaxPageSize {
+			return fmt.Errorf("--page-size must be between 0 and %d", aws.MaxPageSize)
+		}
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		filter, err := clusterfilter.New(includeClusters, excludeClusters)
+		if err != nil {
+			return err
+		}
+		clusterFilter = filter
+		if showAPIStats {
+			apiStats = aws.NewAPIStats()
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// The interactive UI shows the counts in its header instead
+		if apiStats == nil || !cmd.HasParent() {
+			return
+		}
+		stats := apiStats.Take()
+		var attrs []any
+		for _, s := range stats {
+			attrs = append(attrs, slog.Group(s.Service, "calls", s.Calls, "latency_ms", s.Latency.Milliseconds()))
+		}
+		logEvent(slog.LevelInfo, "API calls: "+aws.FormatAPIStats(stats), "api calls", attrs...)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		runCLI()
 	},
@@ -47,33 +162,387 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
+	rootCmd.Flags().IntVar(&metricsPort, "metrics-port", 0, "Serve polled service data as Prometheus metrics on this port (disabled when 0)")
+	rootCmd.Flags().StringVar(&metricsAddress, "metrics-address", "127.0.0.1", "Address to serve Prometheus metrics on; 0.0.0.0 allows scraping from other hosts")
+	rootCmd.Flags().DurationVar(&ui.PollInterval, "poll-interval", ui.PollInterval, "How often service counts and status are refreshed")
+	rootCmd.Flags().DurationVar(&startupTimeout, "startup-timeout", startupTimeout, "Give up loading services at startup after this long, showing what loaded in time (0 waits indefinitely)")
+	rootCmd.Flags().DurationVar(&ui.StuckAfter, "stuck-after", ui.StuckAfter, "Flag a rollout as stuck when its running count hasn't changed for this long (0 disables)")
+	rootCmd.Flags().IntVar(&ui.FlapWindow, "flap-window", ui.FlapWindow, "Number of recent polls looked at to detect flapping services")
+	rootCmd.Flags().IntVar(&ui.StaleDays, "stale-days", ui.StaleDays, "Mark services whose last deployment is older than this many days as stale (0 disables)")
+	rootCmd.Flags().IntVar(&ui.FlapChanges, "flap-changes", ui.FlapChanges, "Flag a service as flapping when its running count changes direction this many times within --flap-window polls (0 disables)")
+	rootCmd.Flags().DurationVar(&ui.MetricsInterval, "metrics-interval", ui.MetricsInterval, "How often CloudWatch utilization is refreshed, independently of --poll-interval")
+	rootCmd.Flags().DurationVar(&idleTimeout, "idle-timeout", 0, "Pause polling after this long without a keypress, e.g. 15m (disabled when 0)")
+	rootCmd.Flags().BoolVar(&ui.MouseEnabled, "mouse", ui.MouseEnabled, "Click to select a service and double-click to open its details; --mouse=false leaves text selection to the terminal")
+	rootCmd.Flags().BoolVar(&once, "once", false, "Print a single colored frame of the service list and exit, without polling or input")
+	rootCmd.Flags().IntVar(&pageSize, "page-size", 0, fmt.Sprintf("Load services this many at a time (up to %d), fetching more as the list is scrolled, instead of all before starting (disabled when 0)", aws.MaxPageSize))
+	rootCmd.Flags().IntVar(&clusterPickerThreshold, "cluster-picker-threshold", 0, "Ask which clusters to load when there are more than this many (disabled when 0)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "AWS shared config profile to use instead of AWS_PROFILE")
+	rootCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "PEM file of extra certificate authorities to trust, e.g. for a TLS-intercepting corporate proxy")
+	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", "Send all AWS requests to this endpoint, e.g. http://localhost:4566 for LocalStack")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, "Format of progress and result lines from non-interactive commands: text or json")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only warnings and errors from non-interactive commands; the exit code reports success")
+	rootCmd.PersistentFlags().BoolVar(&showAPIStats, "api-stats", false, "Count ECS and CloudWatch API calls and their latency, shown in the header after each refresh or logged when a command finishes")
+	rootCmd.PersistentFlags().StringArrayVar(&includeClusters, "include-cluster", nil, "Only load clusters matching this glob, or regex when prefixed with re: (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeClusters, "exclude-cluster", nil, "Skip clusters matching this glob, or regex when prefixed with re: (repeatable; wins over --include-cluster)")
+	rootCmd.PersistentFlags().DurationVar(&aws.MetricsWindow, "metrics-window", aws.MetricsWindow, "How far back CloudWatch utilization is read")
+	rootCmd.PersistentFlags().DurationVar(&aws.MetricsPeriod, "metrics-period", 0, "CloudWatch aggregation period, a multiple of 60s (derived from --metrics-window when 0)")
+	rootCmd.PersistentFlags().StringVar(&aws.MetricsSource, "metrics-source", aws.MetricsSource, "Where utilization is read from: basic (AWS/ECS), auto (Container Insights in clusters that enable it) or container-insights")
+	rootCmd.PersistentFlags().StringVar(&aws.MetricsNamespace, "metrics-namespace", aws.DefaultMetricsNamespace, "CloudWatch namespace of the basic CPUUtilization and MemoryUtilization metrics, for setups that publish them elsewhere")
+	rootCmd.PersistentFlags().DurationVar(&aws.MetricsCallTimeout, "metrics-timeout", aws.MetricsCallTimeout, "Give up on a CloudWatch call after this long and show metrics as unavailable (0 disables)")
 	rootCmd.AddCommand(versionCmd)
 }
 
-func runCLI() {
-	// Load AWS configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+// awsClients holds the service clients built from the shared AWS configuration
+type awsClients struct {
+	ecs        aws.ECSClientAPI
+	cloudwatch aws.CloudWatchClientAPI
+	elb        *elasticloadbalancingv2.Client
+	scaling    *applicationautoscaling.Client
+	profile    string // The shared config profile the clients were loaded from
+}
+
+// newAWSClients loads the default AWS configuration and creates the clients,
+// pointing them at --endpoint-url when it is set
+func newAWSClients(ctx context.Context) (*awsClients, error) {
+	cfg, err := loadAWSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config, %v", err)
+	}
+	return newAWSClientsFromConfig(cfg), nil
+}
+
+// loadAWSConfig loads the default AWS configuration with an HTTP client that
+// sends requests through the proxy from the environment and trusts the
+// certificates in --ca-bundle on top of the system ones. Every client built
+// from it, ECS and CloudWatch included, shares these settings.
+func loadAWSConfig(ctx context.Context) (awssdk.Config, error) {
+	var pem []byte
+	if caBundle != "" {
+		var err error
+		if pem, err = os.ReadFile(caBundle); err != nil {
+			return awssdk.Config{}, fmt.Errorf("error reading --ca-bundle: %v", err)
+		}
+	}
+	httpClient, err := aws.NewHTTPClient(pem)
+	if err != nil {
+		return awssdk.Config{}, fmt.Errorf("error in --ca-bundle %s: %v", caBundle, err)
+	}
+	options := []func(*config.LoadOptions) error{config.WithHTTPClient(httpClient)}
+	if profileName != "" {
+		options = append(options, config.WithSharedConfigProfile(profileName))
+	}
+	return config.LoadDefaultConfig(ctx, options...)
+}
+
+// newAWSClientsFromConfig creates the clients from an already loaded AWS
+// configuration
+func newAWSClientsFromConfig(cfg awssdk.Config) *awsClients {
+	var ecsOptions []func(*ecs.Options)
+	var cloudwatchOptions []func(*cloudwatch.Options)
+	var elbOptions []func(*elasticloadbalancingv2.Options)
+	var scalingOptions []func(*applicationautoscaling.Options)
+	if endpointURL != "" {
+		ecsOptions = append(ecsOptions, func(o *ecs.Options) { o.BaseEndpoint = &endpointURL })
+		cloudwatchOptions = append(cloudwatchOptions, func(o *cloudwatch.Options) { o.BaseEndpoint = &endpointURL })
+		elbOptions = append(elbOptions, func(o *elasticloadbalancingv2.Options) { o.BaseEndpoint = &endpointURL })
+		scalingOptions = append(scalingOptions, func(o *applicationautoscaling.Options) { o.BaseEndpoint = &endpointURL })
+	}
+	newECSClient := func(region string) aws.ECSClientAPI {
+		options := ecsOptions
+		if region != "" {
+			options = append(append([]func(*ecs.Options){}, ecsOptions...), func(o *ecs.Options) { o.Region = region })
+		}
+		var client aws.ECSClientAPI = ecs.NewFromConfig(cfg, options...)
+		if apiStats != nil {
+			client = aws.CountECSCalls(client, apiStats)
+		}
+		return client
+	}
+	clients := &awsClients{
+		// Services from clusters in other regions are acted on through a client for their region
+		ecs:        aws.RouteECSCalls(newECSClient(""), cfg.Region, newECSClient),
+		cloudwatch: cloudwatch.NewFromConfig(cfg, cloudwatchOptions...),
+		elb:        elasticloadbalancingv2.NewFromConfig(cfg, elbOptions...),
+		scaling:    applicationautoscaling.NewFromConfig(cfg, scalingOptions...),
+		profile:    configProfile(cfg),
+	}
+	if apiStats != nil {
+		clients.cloudwatch = aws.CountCloudWatchCalls(clients.cloudwatch, apiStats)
+	}
+	return clients
+}
+
+// listClusters lists the clusters that pass --include-cluster and
+// --exclude-cluster, and with --metrics-source auto checks which of them
+// have Container Insights enabled
+func listClusters(ctx context.Context, clients *awsClients) ([]string, error) {
+	clusters, err := aws.ListClusters(ctx, clients.ecs)
 	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
+		return nil, err
+	}
+	if clusterFilter.Active() {
+		clusters = clusterFilter.Apply(clusters, aws.ClusterName)
+		if len(clusters) == 0 {
+			return nil, errors.New("no clusters match --include-cluster and --exclude-cluster")
+		}
+	}
+	// Clusters whose settings can't be read keep using basic metrics
+	_ = aws.DetectContainerInsights(ctx, clients.ecs, clusters)
+	return clusters, nil
+}
+
+// getAllServiceDetails describes the services of every cluster that passes
+// the cluster filter
+func getAllServiceDetails(ctx context.Context, clients *awsClients) ([]pkg.ServiceDetails, error) {
+	clusters, err := listClusters(ctx, clients)
+	if err != nil {
+		return nil, err
+	}
+	return aws.GetServiceDetailsForClusters(ctx, clients.ecs, clusters)
+}
+
+// logClusterErrors warns about clusters that failed to load, one log entry per
+// cluster in JSON mode
+func logClusterErrors(clusterErrs aws.ClusterErrors) {
+	if jsonLogger == nil {
+		logEvent(slog.LevelWarn, fmt.Sprintf("Warning: %v", clusterErrs), "")
+		return
+	}
+	clusters := make([]string, 0, len(clusterErrs))
+	for cluster := range clusterErrs {
+		clusters = append(clusters, cluster)
 	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		logEvent(slog.LevelWarn, "", "failed to load cluster", "cluster", aws.ClusterName(cluster), "error", clusterErrs[cluster].Error())
+	}
+}
 
-	// Create an ECS client
-	ecsClient := ecs.NewFromConfig(cfg)
+// fetchLiveServices loads every service for the non-interactive commands,
+// warning on stderr about clusters that could not be loaded.
+func fetchLiveServices(ctx context.Context, clients *awsClients) ([]pkg.ServiceDetails, error) {
+	services, err := getAllServiceDetails(ctx, clients)
+	var clusterErrs aws.ClusterErrors
+	if errors.As(err, &clusterErrs) {
+		logClusterErrors(clusterErrs)
+	} else if err != nil {
+		return nil, fmt.Errorf("error fetching services: %v", err)
+	}
+	aws.SortServices(services)
+	return services, nil
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 120 columns
+// when output is redirected
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return 120
+}
 
-	// Create context
-	ctx := context.TODO()
+func runCLI() {
+	// Create context, cancelled once the UI exits so background work stops
+	// queueing updates for an application that is no longer running
+	ctx, cancel := context.WithCancel(context.Background())
 
-	// Fetch service details
-	services, err := aws.GetAllServiceDetails(ctx, ecsClient)
+	// Create the ECS and CloudWatch clients
+	clients, err := newAWSClients(ctx)
 	if err != nil {
-		log.Fatalf("Error fetching services: %v", err)
+		log.Fatal(err)
 	}
 
-	// Initialize the UI and pass the context and ecsClient
+	// Print one frame of the list instead of running the UI
+	if once {
+		defer cancel()
+		services, err := getAllServiceDetails(ctx, clients)
+		if err := ui.RenderOnce(ctx, os.Stdout, clients.cloudwatch, services, err, terminalWidth()); err != nil {
+			log.Fatalf("Error rendering services: %v", err)
+		}
+		return
+	}
+
+	// Initialize the UI and pass the context and clients
 	app := tview.NewApplication()
-	ui.DisplayServices(app, ctx, ecsClient, services)
 
-	if err := app.Run(); err != nil {
+	// Optionally expose polled service data for Prometheus. If serving
+	// fails, the UI is stopped so the error can be reported.
+	var metricsExporter *exporter.Exporter
+	metricsErr := make(chan error, 1)
+	if metricsPort > 0 {
+		address := net.JoinHostPort(metricsAddress, strconv.Itoa(metricsPort))
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Fatalf("unable to listen on metrics address %s: %v", address, err)
+		}
+		metricsExporter = exporter.New()
+		go func() {
+			metricsErr <- http.Serve(listener, metricsExporter)
+			app.Stop()
+		}()
+	}
+
+	// With --page-size, only the first page of services is loaded up front
+	// and the UI loads the rest as the list is scrolled
+	var pager *aws.ServicePager
+	if pageSize > 0 {
+		if pager, err = aws.NewServicePager(clients.ecs, pageSize); err != nil {
+			log.Fatal(err)
+		}
+	}
+	loadClusters := func(ctx context.Context, clusters []string) ([]pkg.ServiceDetails, error) {
+		if pager == nil {
+			return aws.GetServiceDetailsForClusters(ctx, clients.ecs, clusters)
+		}
+		// Page through clusters in the order they're listed in
+		sorted := append([]string(nil), clusters...)
+		sort.Slice(sorted, func(i, j int) bool { return aws.NaturalLess(sorted[i], sorted[j]) })
+		pager.Reset(sorted)
+		return pager.NextPage(ctx)
+	}
+
+	// Fetch service details before the first draw; metrics are loaded lazily by the UI
+	load := func() ([]pkg.ServiceDetails, error) {
+		return loadWithStartupTimeout(ctx, func(ctx context.Context) ([]pkg.ServiceDetails, error) {
+			clusters, err := listClusters(ctx, clients)
+			if err != nil {
+				return nil, err
+			}
+			return loadClusters(ctx, clusters)
+		})
+	}
+	// With many clusters, optionally let the user choose which ones to load
+	picking := false
+	if clusterPickerThreshold > 0 {
+		listCtx, cancelList := startupContext(ctx)
+		clusters, err := listClusters(listCtx, clients)
+		cancelList()
+		if err == nil && len(clusters) > clusterPickerThreshold {
+			picking = true
+			ui.DisplayClusterPicker(app, clusters, func(selected []string) {
+				load := func() ([]pkg.ServiceDetails, error) {
+					return loadWithStartupTimeout(ctx, func(ctx context.Context) ([]pkg.ServiceDetails, error) {
+						return loadClusters(ctx, selected)
+					})
+				}
+				app.SetRoot(tview.NewModal().SetText("Loading services..."), true)
+				go func() {
+					services, err := load()
+					app.QueueUpdateDraw(func() {
+						showServices(app, ctx, clients, metricsExporter, pager, load, selected, services, err)
+					})
+				}()
+			})
+		}
+	}
+	if !picking {
+		services, err := load()
+		showServices(app, ctx, clients, metricsExporter, pager, load, nil, services, err)
+	}
+
+	err = app.Run()
+	cancel()
+	if err != nil {
 		log.Fatalf("Error running application: %v", err)
 	}
+	select {
+	case err := <-metricsErr:
+		log.Fatalf("Error serving metrics: %v", err)
+	default:
+	}
+}
+
+// startupContext derives the context the initial load runs under, which
+// expires after --startup-timeout unless it is 0
+func startupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if startupTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, startupTimeout)
+}
+
+// loadWithStartupTimeout runs an initial load under --startup-timeout. If the
+// timeout expires before any service has loaded, the load fails with an error
+// saying so. Otherwise the services loaded so far are kept, and the clusters
+// that didn't finish in time are reported as such.
+func loadWithStartupTimeout(ctx context.Context, load func(context.Context) ([]pkg.ServiceDetails, error)) ([]pkg.ServiceDetails, error) {
+	loadCtx, cancel := startupContext(ctx)
+	defer cancel()
+	services, err := load(loadCtx)
+	if err == nil || !errors.Is(loadCtx.Err(), context.DeadlineExceeded) {
+		return services, err
+	}
+
+	timedOut := fmt.Errorf("not loaded within --startup-timeout %s", startupTimeout)
+	var clusterErrs aws.ClusterErrors
+	if len(services) == 0 || !errors.As(err, &clusterErrs) {
+		return nil, fmt.Errorf("timed out after %s loading services; check your credentials, region and network, or pass a longer --startup-timeout", startupTimeout)
+	}
+	partial := make(aws.ClusterErrors, len(clusterErrs))
+	for cluster, clusterErr := range clusterErrs {
+		if errors.Is(clusterErr, context.DeadlineExceeded) {
+			clusterErr = timedOut
+		}
+		partial[cluster] = clusterErr
+	}
+	return services, partial
+}
+
+// configProfile returns the name of the shared config profile cfg was
+// loaded from, resolved the way the AWS config loader does: --profile, then
+// AWS_PROFILE or AWS_DEFAULT_PROFILE, then "default"
+func configProfile(cfg awssdk.Config) string {
+	for _, source := range cfg.ConfigSources {
+		switch source := source.(type) {
+		case config.LoadOptions:
+			if source.SharedConfigProfile != "" {
+				return source.SharedConfigProfile
+			}
+		case config.EnvConfig:
+			if source.SharedConfigProfile != "" {
+				return source.SharedConfigProfile
+			}
+		}
+	}
+	return "default"
+}
+
+// showServices displays the loaded services, or an error screen with a retry
+// option if the load failed outright. Clusters that failed to load on their
+// own are reported in the header instead. Retrying calls load again. A
+// non-nil pager loads the remaining services as the list is scrolled. picked
+// holds the clusters chosen in the startup picker, if it was shown.
+func showServices(app *tview.Application, ctx context.Context, clients *awsClients, metricsExporter *exporter.Exporter, pager *aws.ServicePager, load func() ([]pkg.ServiceDetails, error), picked []string, services []pkg.ServiceDetails, err error) {
+	var clusterErrs aws.ClusterErrors
+	if err != nil && !errors.As(err, &clusterErrs) {
+		ui.DisplayLoadError(app, err, func() {
+			services, err := load()
+			app.QueueUpdateDraw(func() {
+				showServices(app, ctx, clients, metricsExporter, pager, load, picked, services, err)
+			})
+		})
+		return
+	}
+
+	serviceUI := ui.DisplayServices(app, ctx, clients.ecs, clients.cloudwatch, services)
+	serviceUI.SetELBClient(clients.elb)
+	serviceUI.SetAutoScalingClient(clients.scaling)
+	serviceUI.SetIdleTimeout(idleTimeout)
+	serviceUI.SetProfile(clients.profile)
+	if pager != nil {
+		serviceUI.SetPager(pager)
+	}
+	if picked != nil {
+		serviceUI.SetPickedClusters(picked)
+	}
+	if apiStats != nil {
+		serviceUI.SetAPIStats(apiStats)
+	}
+	if clusterErrs != nil {
+		serviceUI.SetLoadError(clusterErrs)
+	}
+	if metricsExporter != nil {
+		metricsExporter.Update(services)
+		serviceUI.OnRefresh(metricsExporter.Update)
+	}
 }
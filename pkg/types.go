@@ -1,5 +1,10 @@
 package pkg
 
+import (
+	"strings"
+	"time"
+)
+
 // ClusterOutput holds the list of cluster ARNs returned by ECS
 type ClusterOutput struct {
 	ClusterArns []string `json:"clusterArns"`
@@ -12,9 +17,217 @@ type ServiceOutput struct {
 
 // ServiceDetails contains details about ECS services, including the cluster they belong to
 type ServiceDetails struct {
-	Cluster      string `json:"cluster"`
-	ServiceName  string `json:"serviceName"`
-	RunningCount int64  `json:"runningCount"`
-	DesiredCount int64  `json:"desiredCount"`
-	Status       string `json:"status"` // Add this field to store the deployment status
+	Cluster            string          `json:"cluster"`
+	Group              string          `json:"group"` // Cluster name prefix before the first "-"
+	ServiceName        string          `json:"serviceName"`
+	RunningCount       int64           `json:"runningCount"`
+	DesiredCount       int64           `json:"desiredCount"`
+	Status             string          `json:"status"` // Add this field to store the deployment status
+	TaskDefinition     string          `json:"taskDefinition"`
+	SchedulingStrategy string          `json:"schedulingStrategy"`        // REPLICA or DAEMON
+	PlatformVersion    string          `json:"platformVersion,omitempty"` // Fargate only, e.g. 1.4.0 or LATEST
+	CreatedAt          *time.Time      `json:"createdAt,omitempty"`       // When the service was created
+	Metrics            *ServiceMetrics `json:"metrics,omitempty"`         // Nil until metrics have been loaded
+	PlacementBlocked   bool            `json:"placementBlocked"`          // Recent events show tasks failing to be placed
+	Deploying          bool            `json:"deploying"`                 // A rollout is in progress
+
+	// Where tasks run: the launch type, or with a capacity provider strategy
+	// an empty launch type and the strategy's providers, e.g. FARGATE_SPOT
+	LaunchType        string   `json:"launchType,omitempty"`
+	CapacityProviders []string `json:"capacityProviders,omitempty"`
+
+	// The newest (PRIMARY) deployment, whose running count grows as a
+	// rollout makes progress
+	DeploymentID           string     `json:"deploymentId,omitempty"`
+	DeploymentRunningCount int64      `json:"deploymentRunningCount,omitempty"`
+	LastDeployedAt         *time.Time `json:"lastDeployedAt,omitempty"` // When the primary deployment started
+
+	// Set from Metrics by SetMetrics
+	SustainedHighCPU    bool `json:"sustainedHighCpu"`
+	SustainedHighMemory bool `json:"sustainedHighMemory"`
+
+	TargetGroupArns []string `json:"targetGroupArns,omitempty"` // Load balancer target groups the service registers tasks with
+
+	Network *NetworkConfiguration `json:"network,omitempty"` // Nil unless the service uses the awsvpc network mode
+
+	ExecuteCommandEnabled bool   `json:"executeCommandEnabled"`   // ECS Exec works for new tasks
+	PropagateTags         string `json:"propagateTags,omitempty"` // TASK_DEFINITION, SERVICE or NONE
+	ManagedTags           bool   `json:"managedTags"`             // ECS adds cluster and service tags to tasks
+
+	// Deployment configuration; nil when ECS does not report one
+	MinimumHealthyPercent *int64 `json:"minimumHealthyPercent,omitempty"`
+	MaximumPercent        *int64 `json:"maximumPercent,omitempty"`
+
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"` // Nil when ECS does not report one
+	// Why the latest failed deployment failed, and why ECS is rolling back or
+	// rolled back to an earlier deployment; empty when nothing failed
+	FailedDeployment string `json:"failedDeployment,omitempty"`
+	Rollback         string `json:"rollback,omitempty"`
+
+	Deployments []Deployment `json:"deployments,omitempty"` // Newest first, as ECS lists them
+}
+
+// Deployment is one of the deployments a service's tasks are split between
+// during a rollout. Services using the CODE_DEPLOY or EXTERNAL deployment
+// controllers report their task sets instead.
+type Deployment struct {
+	ID             string   `json:"id"`
+	Status         string   `json:"status"` // PRIMARY, ACTIVE or DRAINING
+	TaskDefinition string   `json:"taskDefinition"`
+	DesiredCount   int64    `json:"desiredCount"`
+	RunningCount   int64    `json:"runningCount"`
+	PendingCount   int64    `json:"pendingCount"`
+	RolloutState   string   `json:"rolloutState,omitempty"` // IN_PROGRESS, COMPLETED or FAILED
+	ScalePercent   *float64 `json:"scalePercent,omitempty"` // Task sets only: share of the service's desired count
+}
+
+// CircuitBreaker is a service's deployment circuit breaker, which stops a
+// deployment whose tasks keep failing and optionally rolls it back
+type CircuitBreaker struct {
+	Enabled  bool `json:"enabled"`
+	Rollback bool `json:"rollback"` // Failed deployments are rolled back to the last completed one
+}
+
+// NetworkConfiguration is where the tasks of an awsvpc service are placed
+type NetworkConfiguration struct {
+	Subnets        []string `json:"subnets"`
+	SecurityGroups []string `json:"securityGroups"`
+	AssignPublicIP string   `json:"assignPublicIp"` // ENABLED or DISABLED
+}
+
+// ServiceMetrics holds the CloudWatch utilization of a service, as a
+// percentage of its reserved CPU and memory. A utilization is nil when
+// CloudWatch has no datapoints for it, so it isn't mistaken for an idle 0%.
+type ServiceMetrics struct {
+	CPUUtilization    *float64 `json:"cpuUtilization"`
+	MemoryUtilization *float64 `json:"memoryUtilization"`
+
+	// Utilization stayed high for most of the window, not just a spike
+	SustainedHighCPU    bool `json:"sustainedHighCpu"`
+	SustainedHighMemory bool `json:"sustainedHighMemory"`
+
+	Source string `json:"source,omitempty"` // CloudWatch namespace: AWS/ECS or ECS/ContainerInsights
+}
+
+// TaskReservation is the CPU and memory reserved by each task of a task
+// definition. Zero means nothing is reserved.
+type TaskReservation struct {
+	CPU       int64 `json:"cpu"`       // CPU units, 1024 per vCPU
+	MemoryMiB int64 `json:"memoryMiB"` // Memory in MiB
+}
+
+// TaskDefinitionSummary is the part of a task definition revision that is
+// compared between deploys: task-level sizes and each container's image,
+// sizes and environment
+type TaskDefinitionSummary struct {
+	TaskDefinition string                       `json:"taskDefinition"`
+	CPU            string                       `json:"cpu,omitempty"`    // Task-level CPU units, as on Fargate
+	Memory         string                       `json:"memory,omitempty"` // Task-level memory in MiB
+	Containers     []ContainerDefinitionSummary `json:"containers"`
+}
+
+// ContainerDefinitionSummary describes a container of a task definition
+type ContainerDefinitionSummary struct {
+	Name              string            `json:"name"`
+	Image             string            `json:"image"`
+	CPU               int32             `json:"cpu,omitempty"`
+	Memory            *int32            `json:"memory,omitempty"`            // Hard limit in MiB
+	MemoryReservation *int32            `json:"memoryReservation,omitempty"` // Soft limit in MiB
+	Environment       map[string]string `json:"environment,omitempty"`
+}
+
+// SetMetrics stores loaded metrics on the service, including its sustained
+// utilization flags
+func (s *ServiceDetails) SetMetrics(metrics ServiceMetrics) {
+	s.Metrics = &metrics
+	s.SustainedHighCPU = metrics.SustainedHighCPU
+	s.SustainedHighMemory = metrics.SustainedHighMemory
+}
+
+// IsFargate reports whether the service's tasks run on Fargate, either with
+// the FARGATE launch type or a FARGATE or FARGATE_SPOT capacity provider
+func (s ServiceDetails) IsFargate() bool {
+	if s.LaunchType == "FARGATE" {
+		return true
+	}
+	for _, provider := range s.CapacityProviders {
+		if provider == "FARGATE" || provider == "FARGATE_SPOT" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDaemon reports whether the service uses the DAEMON scheduling strategy,
+// which runs one task per container instance and has no desired count to set
+func (s ServiceDetails) IsDaemon() bool {
+	return strings.EqualFold(s.SchedulingStrategy, "daemon")
+}
+
+// TaskDetails describes an ECS task and its containers
+type TaskDetails struct {
+	TaskArn          string             `json:"taskArn"`
+	TaskDefinition   string             `json:"taskDefinition,omitempty"`
+	StartedBy        string             `json:"startedBy,omitempty"` // e.g. the EventBridge rule of a scheduled task
+	LastStatus       string             `json:"lastStatus"`
+	DesiredStatus    string             `json:"desiredStatus"`
+	HealthStatus     string             `json:"healthStatus"`
+	StartedAt        *time.Time         `json:"startedAt,omitempty"`
+	StoppedAt        *time.Time         `json:"stoppedAt,omitempty"`
+	StoppedReason    string             `json:"stoppedReason,omitempty"`
+	PrivateIP        string             `json:"privateIp,omitempty"` // Only set for awsvpc tasks
+	AvailabilityZone string             `json:"availabilityZone,omitempty"`
+	Containers       []ContainerDetails `json:"containers"`
+
+	ContainerInstanceArn string `json:"containerInstanceArn,omitempty"` // EC2 launch type only
+}
+
+// ContainerInstance is an EC2 instance registered to a cluster, with the CPU
+// units and memory it registered and has left for further tasks
+type ContainerInstance struct {
+	ContainerInstanceArn string `json:"containerInstanceArn"`
+	EC2InstanceID        string `json:"ec2InstanceId"`
+	AvailabilityZone     string `json:"availabilityZone,omitempty"`
+	Status               string `json:"status"` // e.g. ACTIVE or DRAINING
+	AgentConnected       bool   `json:"agentConnected"`
+	RunningTasksCount    int64  `json:"runningTasksCount"`
+	RegisteredCPU        int64  `json:"registeredCpu"`
+	RegisteredMemoryMiB  int64  `json:"registeredMemoryMiB"`
+	RemainingCPU         int64  `json:"remainingCpu"`
+	RemainingMemoryMiB   int64  `json:"remainingMemoryMiB"`
+}
+
+// ContainerDetails describes a container within a task
+type ContainerDetails struct {
+	Name       string `json:"name"`
+	LastStatus string `json:"lastStatus"`
+	Reason     string `json:"reason,omitempty"`
+	ExitCode   *int32 `json:"exitCode,omitempty"` // Nil while the container hasn't exited
+}
+
+// ServiceEvent is a message from an ECS service's event log
+type ServiceEvent struct {
+	ID        string     `json:"id"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	Message   string     `json:"message"`
+}
+
+// TargetHealth is the health of a running task in one of its service's
+// load balancer target groups
+type TargetHealth struct {
+	TaskArn        string `json:"taskArn"`
+	TargetGroupArn string `json:"targetGroupArn"`
+	State          string `json:"state"` // ELB target state, or "unregistered"
+	Reason         string `json:"reason,omitempty"`
+}
+
+// ScalingBounds are the desired count limits of an auto-scaled service
+type ScalingBounds struct {
+	MinCapacity int64 `json:"minCapacity"`
+	MaxCapacity int64 `json:"maxCapacity"`
+}
+
+// Contains reports whether a desired count is within the bounds
+func (b ScalingBounds) Contains(count int64) bool {
+	return count >= b.MinCapacity && count <= b.MaxCapacity
 }
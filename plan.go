@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/plan"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportPlanFile string
+	applyPlanDry   bool
+	applyPlanYes   bool
+	// applyPlanAllowProduction skips the typed confirmation in production
+	applyPlanAllowProduction bool
+)
+
+var exportPlanCmd = &cobra.Command{
+	Use:   "export-plan",
+	Short: "Write the desired count of every service as an editable scaling plan",
+	Long: `Export-plan writes one cluster,service,desiredCount row per service to stdout
+or a file. Edit the counts and pass the file to apply-plan to scale in bulk.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExportPlan(exportPlanFile)
+	},
+}
+
+var applyPlanCmd = &cobra.Command{
+	Use:   "apply-plan <file>",
+	Short: "Scale services to the desired counts in a plan",
+	Long: `Apply-plan compares a plan written by export-plan against the current desired
+counts, previews every planned service with its current and target count,
+marking the ones that differ, asks for confirmation, and updates only those
+services. With --dry-run the preview is shown and nothing is changed; with
+--yes it is still printed but not confirmed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApplyPlan(args[0])
+	},
+}
+
+func init() {
+	exportPlanCmd.Flags().StringVar(&exportPlanFile, "file", "", "Write to this file instead of stdout")
+	applyPlanCmd.Flags().BoolVar(&applyPlanDry, "dry-run", false, "Show the changes without applying them")
+	applyPlanCmd.Flags().BoolVarP(&applyPlanYes, "yes", "y", false, "Skip the confirmation prompt")
+	applyPlanCmd.Flags().BoolVar(&applyPlanAllowProduction, "allow-production", false, "Change a production account or profile without typing yes; --yes alone doesn't skip that prompt")
+	rootCmd.AddCommand(exportPlanCmd)
+	rootCmd.AddCommand(applyPlanCmd)
+}
+
+func runExportPlan(file string) error {
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	services, err := fetchLiveServices(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %v", file, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return plan.Write(w, services)
+}
+
+func runApplyPlan(file string) error {
+	if err := requireYesWhenQuiet(applyPlanYes || applyPlanDry); err != nil {
+		return err
+	}
+	entries, err := plan.Load(file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	services, err := fetchLiveServices(ctx, clients)
+	if err != nil {
+		return err
+	}
+
+	updates, missing := plan.Diff(entries, services)
+	for _, entry := range missing {
+		logEvent(slog.LevelWarn, fmt.Sprintf("Warning: service %s not found in cluster %s, skipping", entry.Service, entry.Cluster), "planned service not found",
+			"cluster", entry.Cluster, "service", entry.Service)
+	}
+	if len(updates) == 0 {
+		logEvent(slog.LevelInfo, "All services already match the plan", "no changes to apply")
+		return nil
+	}
+
+	if jsonLogger == nil && !quiet {
+		fmt.Printf("%d of %d planned services will be scaled (marked *):\n", len(updates), len(entries))
+		if err := plan.WritePreview(os.Stdout, entries, services); err != nil {
+			return err
+		}
+	} else {
+		for _, update := range updates {
+			cluster := aws.ClusterName(update.Service.Cluster)
+			logEvent(slog.LevelInfo, "", "service will be scaled",
+				"cluster", cluster, "service", update.Service.ServiceName, "from", update.Service.DesiredCount, "to", update.DesiredCount)
+		}
+	}
+	if applyPlanDry {
+		return nil
+	}
+	changed := make([]pkg.ServiceDetails, len(updates))
+	for i, update := range updates {
+		changed[i] = update.Service
+	}
+	if err := confirmProduction("apply the plan", changed, clients.profile, applyPlanAllowProduction); err != nil {
+		return err
+	}
+	if !applyPlanYes && !confirmBulk(len(updates)) {
+		return errors.New("aborted")
+	}
+
+	results := aws.UpdateDesiredCounts(ctx, clients.ecs, updates)
+	failed := 0
+	for i, result := range results {
+		cluster := aws.ClusterName(result.Service.Cluster)
+		if result.Err != nil {
+			failed++
+			logEvent(slog.LevelError, fmt.Sprintf("FAILED  %s/%s: %v", cluster, result.Service.ServiceName, result.Err), "failed to scale service",
+				"cluster", cluster, "service", result.Service.ServiceName, "error", result.Err.Error())
+			continue
+		}
+		logEvent(slog.LevelInfo, fmt.Sprintf("OK      %s/%s: desired count %d -> %d", cluster, result.Service.ServiceName, result.Service.DesiredCount, updates[i].DesiredCount), "scaled service",
+			"cluster", cluster, "service", result.Service.ServiceName, "from", result.Service.DesiredCount, "to", updates[i].DesiredCount)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to scale %d of %d services", failed, len(updates))
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/alexalbu001/bw-cli/internal/config"
+	"github.com/alexalbu001/bw-cli/internal/savedcounts"
+	"github.com/alexalbu001/bw-cli/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scaleCount   int64
+	scaleToZero  bool
+	scaleRestore bool
+	scaleYes     bool
+	// scaleAllowProduction skips the typed confirmation in production
+	scaleAllowProduction bool
+)
+
+var scaleClusterCmd = &cobra.Command{
+	Use:   "scale-cluster <cluster>",
+	Short: "Set the desired count of every service in a cluster",
+	Long: `Scale-cluster lists the services in the cluster, asks for confirmation, and
+then updates the desired count of all of them concurrently.
+
+With --to-zero, each service's current desired count is saved locally before
+it is scaled to zero, and --restore later sets them back to the saved counts.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modes := 0
+		for _, set := range []bool{cmd.Flags().Changed("count"), scaleToZero, scaleRestore} {
+			if set {
+				modes++
+			}
+		}
+		if modes != 1 {
+			return errors.New("exactly one of --count, --to-zero, or --restore is required")
+		}
+		if scaleCount < 0 {
+			return errors.New("--count must not be negative")
+		}
+		return runScaleCluster(args[0])
+	},
+}
+
+func init() {
+	scaleClusterCmd.Flags().Int64Var(&scaleCount, "count", 0, "Desired count to set on every service")
+	scaleClusterCmd.Flags().BoolVar(&scaleToZero, "to-zero", false, "Save current desired counts and scale every service to zero")
+	scaleClusterCmd.Flags().BoolVar(&scaleRestore, "restore", false, "Restore the desired counts saved by --to-zero")
+	scaleClusterCmd.Flags().BoolVarP(&scaleYes, "yes", "y", false, "Skip the confirmation prompt")
+	scaleClusterCmd.Flags().BoolVar(&scaleAllowProduction, "allow-production", false, "Change a production account or profile without typing yes; --yes alone doesn't skip that prompt")
+	rootCmd.AddCommand(scaleClusterCmd)
+}
+
+func runScaleCluster(cluster string) error {
+	if err := requireYesWhenQuiet(scaleYes); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Services listed by ARN tell which account they are in
+	clusterArn, err := resolveClusterArn(ctx, clients, cluster)
+	if err != nil {
+		return err
+	}
+	services, err := aws.GetClusterServiceDetails(ctx, clients.ecs, clusterArn)
+	if err != nil {
+		return fmt.Errorf("error fetching services in cluster %s: %v", cluster, err)
+	}
+	aws.SortServices(services)
+	// The saved counts skip daemons on their own, and drop any saved earlier
+	scalable, daemons := aws.ScalableServices(services)
+	for _, daemon := range daemons {
+		logEvent(slog.LevelInfo, fmt.Sprintf("Skipping %s: daemon services have no desired count", daemon.ServiceName), "daemon service skipped",
+			"cluster", cluster, "service", daemon.ServiceName)
+	}
+
+	var store *savedcounts.Store
+	if scaleToZero || scaleRestore {
+		path, err := savedcounts.DefaultPath()
+		if err != nil {
+			return err
+		}
+		if store, err = savedcounts.Load(path); err != nil {
+			return err
+		}
+	}
+
+	var updates []aws.DesiredCountUpdate
+	switch {
+	case scaleToZero:
+		updates = store.ZeroUpdates(services)
+	case scaleRestore:
+		updates = store.RestoreUpdates(services)
+	default:
+		for _, service := range scalable {
+			updates = append(updates, aws.DesiredCountUpdate{Service: service, DesiredCount: scaleCount})
+		}
+	}
+	if len(updates) == 0 {
+		if scaleRestore {
+			if err := store.Save(); err != nil {
+				return err
+			}
+		}
+		logEvent(slog.LevelInfo, fmt.Sprintf("No services to scale in cluster %s", cluster), "no services to scale",
+			"cluster", cluster)
+		return nil
+	}
+
+	if jsonLogger == nil && !quiet {
+		fmt.Printf("The following services in %s will be scaled:\n", cluster)
+	}
+	for _, update := range updates {
+		logEvent(slog.LevelInfo, fmt.Sprintf("  %s (desired: %d -> %d)", update.Service.ServiceName, update.Service.DesiredCount, update.DesiredCount), "service will be scaled",
+			"cluster", cluster, "service", update.Service.ServiceName, "from", update.Service.DesiredCount, "to", update.DesiredCount)
+	}
+	if err := confirmProduction("scale cluster "+cluster, services, clients.profile, scaleAllowProduction); err != nil {
+		return err
+	}
+	if !scaleYes && !confirmBulk(len(updates)) {
+		return errors.New("aborted")
+	}
+
+	// Persist the counts before scaling to zero so they survive a failed run
+	if scaleToZero {
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+
+	results := aws.UpdateDesiredCounts(ctx, clients.ecs, updates)
+	failed := 0
+	for i, result := range results {
+		if result.Err != nil {
+			failed++
+			logEvent(slog.LevelError, fmt.Sprintf("FAILED  %s: %v", result.Service.ServiceName, result.Err), "failed to scale service",
+				"cluster", cluster, "service", result.Service.ServiceName, "error", result.Err.Error())
+			continue
+		}
+		logEvent(slog.LevelInfo, fmt.Sprintf("OK      %s: desired count %d -> %d", result.Service.ServiceName, result.Service.DesiredCount, updates[i].DesiredCount), "scaled service",
+			"cluster", cluster, "service", result.Service.ServiceName, "from", result.Service.DesiredCount, "to", updates[i].DesiredCount)
+	}
+
+	if scaleRestore {
+		store.ForgetRestored(results)
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to scale %d of %d services", failed, len(updates))
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no. Prompts go to
+// stderr so they never mix with JSON output on stdout.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// confirmBulk asks to proceed with an operation on count services. Above the
+// configured threshold the count or "yes" has to be typed in full.
+func confirmBulk(count int) bool {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if !cfg.RequiresTypedConfirmation(count) {
+		return confirm("Proceed?")
+	}
+
+	fmt.Fprintf(os.Stderr, "This affects %d services. Type %d or yes to proceed: ", count, count)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return config.IsTypedConfirmation(answer, count)
+}
+
+// resolveClusterArn returns the ARN of the cluster named cluster, or cluster
+// itself if it already is an ARN or no cluster has that name
+func resolveClusterArn(ctx context.Context, clients *awsClients, cluster string) (string, error) {
+	if _, err := aws.ParseARN(cluster); err == nil {
+		return cluster, nil
+	}
+	clusters, err := aws.ListClusters(ctx, clients.ecs)
+	if err != nil {
+		return "", err
+	}
+	for _, clusterArn := range clusters {
+		if aws.ClusterName(clusterArn) == cluster {
+			return clusterArn, nil
+		}
+	}
+	return cluster, nil
+}
+
+// confirmProduction asks for yes to be typed before a command changes
+// services in an account or profile marked as production in the config
+// file, as the UI does. --yes doesn't skip it; allow does, for automation
+// that has been cleared to change production.
+func confirmProduction(action string, services []pkg.ServiceDetails, profile string, allow bool) error {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	label := cfg.ProductionLabel(aws.AccountIDs(services), profile)
+	if label == "" || allow {
+		return nil
+	}
+	if quiet {
+		return fmt.Errorf("%s is marked as production: pass --allow-production to change it without typing yes", label)
+	}
+
+	fmt.Fprintf(os.Stderr, "PRODUCTION (%s): type yes to %s: ", label, action)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(answer), "yes") {
+		return errors.New("aborted")
+	}
+	return nil
+}
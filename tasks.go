@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexalbu001/bw-cli/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tasksCluster string
+	tasksOutput  string
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks <service>",
+	Short: "List the tasks of a service",
+	Long: `Tasks lists every task of a service with its ARN, last status, health status,
+and start time.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tasksOutput != "text" && tasksOutput != "json" {
+			return fmt.Errorf("unsupported output format %q (expected text or json)", tasksOutput)
+		}
+		return runTasks(args[0])
+	},
+}
+
+func init() {
+	tasksCmd.Flags().StringVar(&tasksCluster, "cluster", "", "Cluster the service runs in")
+	tasksCmd.Flags().StringVar(&tasksOutput, "output", "text", "Output format: text or json")
+	tasksCmd.MarkFlagRequired("cluster")
+	rootCmd.AddCommand(tasksCmd)
+}
+
+func runTasks(service string) error {
+	ctx := context.TODO()
+
+	clients, err := newAWSClients(ctx)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := aws.ListServiceTasks(ctx, clients.ecs, tasksCluster, service)
+	if err != nil {
+		return err
+	}
+
+	if tasksOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tasks)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tLAST STATUS\tHEALTH\tSTARTED AT")
+	for _, task := range tasks {
+		startedAt := "-"
+		if task.StartedAt != nil {
+			startedAt = task.StartedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", task.TaskArn, task.LastStatus, task.HealthStatus, startedAt)
+	}
+	return w.Flush()
+}